@@ -0,0 +1,48 @@
+// Package completion -
+//
+// This is the entry point of generating completion scripts, by cobra. Scripts complete dynamically, by running the
+// CLI itself (cli __complete ...), so that completions of searches follow the data (see `cmd/search/complete.go`)
+//
+
+package completion
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+// Supported shells
+const (
+	shellBash       = "bash"
+	shellZsh        = "zsh"
+	shellFish       = "fish"
+	shellPowershell = "powershell"
+)
+
+/*
+*		Write the completion script of a shell for the root command to the output of the command
+*
+*	    @return (error): If the shell is not supported, or the script could not be written
+ */
+func generateCompletion(cmd *cobra.Command, shell string) error {
+	root, output := cmd.Root(), cmd.OutOrStdout()
+	descriptions, _ := cmd.Flags().GetBool("no-descriptions")
+	descriptions = !descriptions
+	switch shell {
+	case shellBash:
+		return root.GenBashCompletionV2(output, descriptions)
+	case shellZsh:
+		if descriptions {
+			return root.GenZshCompletion(output)
+		}
+		return root.GenZshCompletionNoDesc(output)
+	case shellFish:
+		return root.GenFishCompletion(output, descriptions)
+	case shellPowershell:
+		if descriptions {
+			return root.GenPowerShellCompletionWithDesc(output)
+		}
+		return root.GenPowerShellCompletion(output)
+	}
+	return fmt.Errorf("unsupported shell %v, expected one of: %v, %v, %v or %v", shell, shellBash, shellZsh, shellFish, shellPowershell)
+}
@@ -0,0 +1,51 @@
+package completion
+
+import (
+	"bytes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// Test completion scripts are generated for every supported shell
+
+func Test_ExecuteCompletionCommand(t *testing.T) {
+	execute := func(args ...string) (string, error) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		root := &cobra.Command{Use: "cli"}
+		root.AddCommand(NewCompletionCmd())
+		root.SetOut(buffer)
+		root.SetErr(buffer)
+		root.SetArgs(args)
+		err := root.Execute()
+		return buffer.String(), err
+	}
+
+	tests := []struct {
+		shell  string
+		script string
+	}{
+		{shell: shellBash, script: "# bash completion V2 for cli"},
+		{shell: shellZsh, script: "#compdef cli"},
+		{shell: shellFish, script: "# fish completion for cli"},
+		{shell: shellPowershell, script: "# powershell completion for cli"},
+	}
+	for _, tt := range tests {
+		t.Run("Generate completion script of "+tt.shell, func(t *testing.T) {
+			output, err := execute("completion", tt.shell)
+			assert.Nil(t, err)
+			assert.True(t, strings.HasPrefix(output, tt.script), output[:min(len(output), 80)])
+			assert.True(t, strings.Contains(output, "__complete"), "Scripts complete dynamically by running the CLI")
+		})
+	}
+	t.Run("Generate completion script without descriptions", func(t *testing.T) {
+		output, err := execute("completion", shellFish, "--no-descriptions")
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(output, "__completeNoDesc"))
+	})
+	t.Run("Generate completion script of an unsupported shell", func(t *testing.T) {
+		err := generateCompletion(&cobra.Command{}, "tcsh")
+		assert.Equal(t, "unsupported shell tcsh, expected one of: bash, zsh, fish or powershell", err.Error())
+	})
+}
@@ -0,0 +1,67 @@
+// Package completion -
+//
+// Defines all the commands for generating completion scripts of shells, and which entrypoints to invoke for them
+//
+
+package completion
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCompletionCmd - Parent command setup for completion scripts, one for each supported shell /*
+func NewCompletionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion",
+		Short: "Generate the completion script of a shell (bash, zsh, fish or powershell)",
+		Long: `Generate the completion script of a shell, completing commands and flags of the CLI. Searches complete
+--name by the fields of the entity, and --value by the values of the field in the data (eg. statuses of tickets),
+read from the data directory of the selected profile. See 'cli completion <shell> --help' to load it.`,
+	}
+	cmd.AddCommand(NewShellCompletionCmd(shellBash, `Load completions in the current shell with:
+
+  source <(cli completion bash)
+
+Or for every session, on Linux:
+
+  cli completion bash > /etc/bash_completion.d/cli
+
+The bash-completion package must be installed.`))
+	cmd.AddCommand(NewShellCompletionCmd(shellZsh, `Load completions in the current shell with:
+
+  source <(cli completion zsh)
+
+Or for every session, with compinit enabled (eg. autoload -U compinit; compinit in ~/.zshrc):
+
+  cli completion zsh > "${fpath[1]}/_cli"`))
+	cmd.AddCommand(NewShellCompletionCmd(shellFish, `Load completions in the current shell with:
+
+  cli completion fish | source
+
+Or for every session:
+
+  cli completion fish > ~/.config/fish/completions/cli.fish`))
+	cmd.AddCommand(NewShellCompletionCmd(shellPowershell, `Load completions in the current shell with:
+
+  cli completion powershell | Out-String | Invoke-Expression
+
+Or for every session, add the output of the command above to your PowerShell profile.`))
+	return cmd
+}
+
+// NewShellCompletionCmd - Define command generating the completion script of a shell /*
+func NewShellCompletionCmd(shell, long string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   shell,
+		Short:                 "Generate the completion script of " + shell,
+		Long:                  "Generate the completion script of " + shell + ". " + long,
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateCompletion(cmd, shell)
+		},
+	}
+	cmd.Flags().Bool("no-descriptions", false, "Complete without descriptions (eg. of flags)")
+	return cmd
+}
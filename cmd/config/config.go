@@ -0,0 +1,131 @@
+// Package config -
+//
+// This is the entry point of viewing and changing the config file of the user, see `internal/config.go`. Settings
+// are validated as they are set (eg. fields of entities against their models), rather than when they are applied
+//
+
+package config
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+/*
+*		Display the config file, along with its path
+*
+*	    @return (error): If the config file could not be read, or is invalid
+ */
+func viewConfig(cmd *cobra.Command) error {
+	path, config, err := readConfig(cmd)
+	if err != nil {
+		return err
+	}
+	cmd.Println("# " + path)
+	raw, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if len(config.Profiles) == 0 && config.CurrentProfile == "" {
+		cmd.Println("# Nothing configured yet, eg. cli config set timezone Australia/Melbourne")
+		return nil
+	}
+	cmd.Print(string(raw))
+	return nil
+}
+
+/*
+*		Display a setting of the selected profile (or current_profile of the config), empty if it is not set
+*
+*	    @return (error): If the config file is invalid, or the key is unknown
+ */
+func getConfig(cmd *cobra.Command, key string) error {
+	_, config, err := readConfig(cmd)
+	if err != nil {
+		return err
+	}
+	name, _ := cmd.Flags().GetString("profile")
+	value, err := config.Get(name, key)
+	if err != nil {
+		return cmd.FlagErrorFunc()(cmd, err)
+	}
+	cmd.Println(value)
+	return nil
+}
+
+/*
+*		Change a setting of the selected profile (or current_profile of the config), creating the profile and the config
+*		file if needed
+*
+*	    @return (error): If the config file is invalid, the key is unknown or the value is invalid for the key
+ */
+func setConfig(cmd *cobra.Command, key, value string) error {
+	path, config, err := readConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if err := validateSetting(key, value); err != nil {
+		return cmd.FlagErrorFunc()(cmd, err)
+	}
+	name, _ := cmd.Flags().GetString("profile")
+	if err := config.Set(name, key, value); err != nil {
+		return cmd.FlagErrorFunc()(cmd, err)
+	}
+	if err := config.Write(path); err != nil {
+		return err
+	}
+	if key == internal.KeyCurrentProfile {
+		cmd.Println(fmt.Sprintf("Set %v to %q in %v", key, value, path))
+		return nil
+	}
+	cmd.Println(fmt.Sprintf("Set %v of profile %v to %q in %v", key, config.ProfileName(name), value, path))
+	return nil
+}
+
+// Path of the config file of a command, and the config in it
+func readConfig(cmd *cobra.Command) (string, *internal.Config, error) {
+	path, err := internal.ConfigPath(cmd)
+	if err != nil {
+		return "", nil, err
+	}
+	config, err := internal.ReadConfig(path)
+	return path, config, err
+}
+
+/*
+*		Validate a setting against the CLI: a data directory must exist, and fields of an entity must be fields of its
+*		model. Other settings are validated by the config itself
+*
+*	    @return (error): If the value is invalid for the key
+ */
+func validateSetting(key, value string) error {
+	if value == "" {
+		return nil // Unsets the setting
+	}
+	if key == internal.KeyDataDir {
+		info, err := os.Stat(value)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("invalid data_dir %v, expected a directory of data files", value)
+		}
+		return nil
+	}
+	name, ok := strings.CutPrefix(key, internal.KeyFields+".")
+	if !ok {
+		return nil
+	}
+	entity, ok := models.Registry.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown entity %v in %v, expected one of: %v", name, key, strings.Join(models.Registry.Names(), ", "))
+	}
+	for _, field := range internal.SplitFields(value) {
+		if _, ok := entity.Mappings()[field]; !ok && !strings.Contains(field, ".") { // Custom fields are named <type>_field.<name>
+			return fmt.Errorf("unknown field %v of %vs, see 'list' command for fields", field, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test viewing and changing the config file of profiles
+
+func Test_ExecuteConfigCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	// Root command of the CLI, as the --config and --profile flags are global
+	execute := func(args ...string) (string, error) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		root := &cobra.Command{Use: "cli"}
+		root.PersistentFlags().String("config", path, "")
+		root.PersistentFlags().String("profile", "", "")
+		root.AddCommand(NewConfigCmd())
+		root.SetOut(buffer)
+		root.SetErr(buffer)
+		root.SetArgs(args)
+		err := root.Execute()
+		return buffer.String(), err
+	}
+
+	t.Run("View config before anything is configured", func(t *testing.T) {
+		output, err := execute("config", "view")
+		assert.Nil(t, err)
+		assert.Equal(t, "# "+path+"\n# Nothing configured yet, eg. cli config set timezone Australia/Melbourne\n", output)
+	})
+	t.Run("Set settings and view config", func(t *testing.T) {
+		output, err := execute("config", "set", "timezone", "Australia/Melbourne", "--profile", "acme")
+		assert.Nil(t, err)
+		assert.Equal(t, `Set timezone of profile acme to "Australia/Melbourne" in `+path+"\n", output)
+		_, err = execute("config", "set", "fields.ticket", "_id,subject,organization_field.region")
+		assert.Nil(t, err)
+
+		output, err = execute("config", "view")
+		assert.Nil(t, err)
+		assert.Equal(t, "# "+path+"\nprofiles:\n    acme:\n        timezone: Australia/Melbourne\n"+
+			"    default:\n        fields:\n            ticket:\n                - _id\n                - subject\n                - organization_field.region\n", output)
+	})
+	t.Run("Get settings of profiles", func(t *testing.T) {
+		output, _ := execute("config", "get", "timezone", "--profile", "acme")
+		assert.Equal(t, "Australia/Melbourne\n", output)
+		output, _ = execute("config", "get", "timezone")
+		assert.Equal(t, "\n", output)
+	})
+	t.Run("Set invalid settings", func(t *testing.T) {
+		_, err := execute("config", "set", "fields.ticket", "subjcet")
+		assert.Equal(t, "unknown field subjcet of tickets, see 'list' command for fields", err.Error())
+		_, err = execute("config", "set", "timezone", "Mars/Olympus")
+		assert.NotNil(t, err)
+		output, _ := execute("config", "get", "fields.ticket")
+		assert.True(t, strings.HasPrefix(output, "_id,subject"), "Invalid settings are not set")
+	})
+}
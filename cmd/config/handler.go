@@ -0,0 +1,71 @@
+// Package config -
+//
+// Defines all the commands for the config file of the user, and which entrypoints to invoke for them
+//
+
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// UnprofiledAnnotation - Annotation of commands run without applying a profile, so that the config file can be
+// changed even if the selected profile does not exist yet
+const UnprofiledAnnotation = "unprofiled"
+
+// NewConfigCmd - Parent command setup for all config commands (view, get and set) /*
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "config",
+		Short:       "View and change the config file of profiles",
+		Annotations: map[string]string{UnprofiledAnnotation: "true"},
+		Long: `View and change the config file of profiles. Each profile has settings applied to every command, unless
+overridden by its flags: data_dir, output, timezone, color and fields.<entity>. The profile is selected by --profile,
+or by current_profile of the config, or is the default profile.`,
+		Example: `  cli config set data_dir /srv/zendesk/acme --profile acme
+  cli config set fields.user _id,name,email --profile acme
+  cli config set current_profile acme
+  cli config get timezone
+  cli config view`,
+	}
+	cmd.AddCommand(NewViewCmd())
+	cmd.AddCommand(NewGetCmd())
+	cmd.AddCommand(NewSetCmd())
+	return cmd
+}
+
+// NewViewCmd - Define command displaying the config file /*
+func NewViewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "Display the config file, along with its path",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return viewConfig(cmd)
+		},
+	}
+}
+
+// NewGetCmd - Define command displaying a setting of the selected profile /*
+func NewGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Display a setting of the selected profile, eg. timezone or fields.user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return getConfig(cmd, args[0])
+		},
+	}
+}
+
+// NewSetCmd - Define command changing a setting of the selected profile /*
+func NewSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting of the selected profile, or unset it with an empty value",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setConfig(cmd, args[0], args[1])
+		},
+	}
+}
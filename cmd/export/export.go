@@ -0,0 +1,371 @@
+// Package export -
+//
+// This is the entry point of exporting the data of organizations: records of the data files related to them, by the
+// relationships declared by the models of the registry, are written as they were read, so that nothing of the schema
+// is lost
+//
+
+package export
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"ZendeskChallenge/models/organizations"
+	"ZendeskChallenge/pkg/zsearch"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// record - Record of a data file as it was read, along with its fields for following it to related records
+type record struct {
+	raw    json.RawMessage
+	fields map[string]any
+}
+
+// Key of a field of a record, to match it against keys of related records. Empty if the record does not have it
+func (r record) key(field string) string {
+	value, ok := r.fields[field]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// Keys of a list field of a record (eg. agent_ids), to match them against keys of related records
+func (r record) keys(field string) []string {
+	values, _ := r.fields[field].([]any)
+	var keys []string
+	for _, value := range values {
+		if value != nil {
+			keys = append(keys, fmt.Sprint(value))
+		}
+	}
+	return keys
+}
+
+// keySet - Set of keys of records
+type keySet map[string]bool
+
+// Add a key to the set, unless it is empty
+func (s keySet) add(key string) {
+	if key != "" {
+		s[key] = true
+	}
+}
+
+/*
+*		Export the organizations given by --organization to the directory given by --out: a data file of each entity
+*		with only the records related to the organizations (see relatedRecords), and the definitions of custom fields.
+*		A data file is written for every entity read, even without records, so that the directory is loaded as the
+*		data of the CLI
+*
+*	    @return (error): If data files could not be read, an organization is unknown, or files could not be written
+ */
+func triggerExport(cmd *cobra.Command) error {
+	ids, _ := cmd.Flags().GetStringSlice("organization")
+	out, _ := cmd.Flags().GetString("out")
+	force, _ := cmd.Flags().GetBool("force")
+	excludeRelated, _ := cmd.Flags().GetBool("exclude-related")
+	if strings.TrimSpace(out) == "" {
+		return cmd.FlagErrorFunc()(cmd, errors.New("invalid --out, expected a directory"))
+	}
+	if isDataDir(out) {
+		return cmd.FlagErrorFunc()(cmd, fmt.Errorf("invalid --out %q, data is read from it", out))
+	}
+
+	data := map[string][]record{}
+	for _, entity := range models.Registry.All() {
+		records, err := readRecords(entity.DataFile())
+		if errors.Is(err, fs.ErrNotExist) && entity.EntityName() != organizations.Model.Name {
+			continue // Entities other than organizations are optional, eg. comments
+		}
+		if err != nil {
+			return err
+		}
+		data[entity.EntityName()] = records
+	}
+	selected := keySet{}
+	for _, id := range ids {
+		selected.add(id)
+	}
+	for id := range selected {
+		if len(filterRecords(data[organizations.Model.Name], organizations.Model.KeyField, keySet{id: true})) == 0 {
+			return fmt.Errorf("organization %v %w in %v", id, zsearch.ErrNotFound, organizations.Model.File)
+		}
+	}
+	exported := relatedRecords(data, selected, !excludeRelated)
+
+	files := map[string][]byte{}
+	var written []internal.Entity
+	for _, entity := range models.Registry.All() {
+		if _, ok := data[entity.EntityName()]; !ok {
+			continue
+		}
+		written = append(written, entity)
+		raw, err := marshalRecords(exported[entity.EntityName()])
+		if err != nil {
+			return err
+		}
+		files[entity.DataFile()] = raw
+	}
+	definitions, err := internal.ReadDataFile(internal.FieldDefinitionsFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) { // Custom fields are optional
+		return err
+	}
+	if err == nil {
+		files[internal.FieldDefinitionsFile] = definitions
+	}
+	if err := writeFiles(out, files, force); err != nil {
+		return err
+	}
+	for _, entity := range written {
+		cmd.Println(fmt.Sprintf("Exported %v %vs to %v", len(exported[entity.EntityName()]), entity.EntityName(), filepath.Join(out, entity.DataFile())))
+	}
+	return nil
+}
+
+/*
+*		Records related to organizations, by the relationships of entities referring to a record of another entity by
+*		its primary key (see references):
+*
+*		- the organizations, and the records referring to them (eg. users and tickets, by organization_id)
+*		- unless left out, the records these refer to (eg. users tickets were submitted by or assigned to), even if
+*		  they are of other organizations, so that names of related records are found along with them
+*		- records of other entities referring to exported records only (eg. comments of exported tickets by exported
+*		  users), so that no other organization is found through them
+*		- records of other entities referring to exported records by lists only, referring to any of them (eg. groups
+*		  with any exported agent, by agent_ids)
+*
+*	    @return (map[string][]record): Related records by entity name, in the order they were read. Every entity of
+*		the data has its records, even if there are none
+ */
+func relatedRecords(data map[string][]record, selected keySet, includeRelated bool) map[string][]record {
+	organization := organizations.Model.Name
+	keys := map[string]keySet{organization: selected} // Keys of exported records by entity name
+	var owned []internal.Entity
+	for _, entity := range models.Registry.All() {
+		for _, reference := range references(entity) {
+			if reference.Entity == organization && data[entity.EntityName()] != nil {
+				keys[entity.EntityName()] = keysOf(filterRecords(data[entity.EntityName()], reference.LocalKey, selected), entity.PrimaryKey())
+				owned = append(owned, entity)
+				break
+			}
+		}
+	}
+	if includeRelated {
+		referred := map[string]keySet{}
+		for _, entity := range owned {
+			for _, r := range filterRecords(data[entity.EntityName()], entity.PrimaryKey(), keys[entity.EntityName()]) {
+				for _, reference := range references(entity) {
+					if reference.Entity != organization && keys[reference.Entity] != nil {
+						if referred[reference.Entity] == nil {
+							referred[reference.Entity] = keySet{}
+						}
+						referred[reference.Entity].add(r.key(reference.LocalKey))
+					}
+				}
+			}
+		}
+		for name, ids := range referred {
+			for id := range ids {
+				keys[name].add(id)
+			}
+		}
+	}
+	for found := true; found; {
+		found = false
+		for _, entity := range models.Registry.All() {
+			if keys[entity.EntityName()] != nil || data[entity.EntityName()] == nil {
+				continue
+			}
+			exported := exportedReferences(references(entity), keys)
+			lists := exportedReferences(listReferences(entity), keys)
+			if len(exported) == 0 && len(lists) == 0 {
+				continue
+			}
+			matching := keySet{}
+			for _, r := range data[entity.EntityName()] {
+				if (len(exported) > 0 && refersToExported(r, exported, keys)) || (len(exported) == 0 && listsExported(r, lists, keys)) {
+					matching.add(r.key(entity.PrimaryKey()))
+				}
+			}
+			keys[entity.EntityName()], found = matching, true
+		}
+	}
+	related := map[string][]record{}
+	for name := range data {
+		entity, _ := models.Registry.Get(name)
+		related[name] = filterRecords(data[name], entity.PrimaryKey(), keys[name])
+	}
+	return related
+}
+
+/*
+*		Relationships of an entity referring to a single record of another entity by its primary key (eg. organization_id
+*		of users), rather than to records referring back to it (eg. tickets of users) or to a list of them (eg. agent_ids
+*		of groups)
+ */
+func references(entity internal.Entity) []internal.Relationship {
+	return keyReferences(entity, false)
+}
+
+// Relationships of an entity referring to a list of records of another entity by their primary key, eg. agent_ids
+func listReferences(entity internal.Entity) []internal.Relationship {
+	return keyReferences(entity, true)
+}
+
+// Relationships of an entity referring to records of another entity by their primary key, by a list or not
+func keyReferences(entity internal.Entity, list bool) []internal.Relationship {
+	var found []internal.Relationship
+	for _, relation := range entity.Relations() {
+		related, ok := models.Registry.Get(relation.Entity)
+		fieldType := entity.FieldType(relation.LocalKey)
+		if ok && relation.ForeignKey == related.PrimaryKey() && fieldType != nil && (fieldType.Kind() == reflect.Slice) == list {
+			found = append(found, relation)
+		}
+	}
+	return found
+}
+
+// References to entities which have exported records
+func exportedReferences(references []internal.Relationship, keys map[string]keySet) []internal.Relationship {
+	var exported []internal.Relationship
+	for _, reference := range references {
+		if keys[reference.Entity] != nil {
+			exported = append(exported, reference)
+		}
+	}
+	return exported
+}
+
+// Whether a record refers to exported records only, and to at least one of them
+func refersToExported(r record, references []internal.Relationship, keys map[string]keySet) bool {
+	found := false
+	for _, reference := range references {
+		key := r.key(reference.LocalKey)
+		if key == "" {
+			continue
+		}
+		if !keys[reference.Entity][key] {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// Whether a record refers to any exported record by any of its lists
+func listsExported(r record, references []internal.Relationship, keys map[string]keySet) bool {
+	for _, reference := range references {
+		for _, key := range r.keys(reference.LocalKey) {
+			if keys[reference.Entity][key] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Keys of a field of records
+func keysOf(records []record, field string) keySet {
+	keys := keySet{}
+	for _, r := range records {
+		keys.add(r.key(field))
+	}
+	return keys
+}
+
+// Records whose field has any of the keys
+func filterRecords(records []record, field string, keys keySet) []record {
+	matching := []record{}
+	for _, r := range records {
+		if keys[r.key(field)] {
+			matching = append(matching, r)
+		}
+	}
+	return matching
+}
+
+/*
+*		Read records of a data file, each as it was read along with its fields
+*
+*	    @return ([]record, error): Records, and error if the file could not be read or is not a list of records
+ */
+func readRecords(file string) ([]record, error) {
+	raw, err := internal.ReadDataFile(file)
+	if err != nil {
+		return nil, &zsearch.LoadError{File: file, Err: err}
+	}
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, &zsearch.LoadError{File: file, Err: err}
+	}
+	records := make([]record, len(values))
+	for i, value := range values {
+		decoder := json.NewDecoder(bytes.NewReader(value))
+		decoder.UseNumber() // Keys are matched as they are written, eg. 119 rather than 119.0
+		if err := decoder.Decode(&records[i].fields); err != nil {
+			return nil, &zsearch.LoadError{File: file, Err: err}
+		}
+		records[i].raw = value
+	}
+	return records, nil
+}
+
+// Records as a data file, a list of records in the schema they were read in
+func marshalRecords(records []record) ([]byte, error) {
+	values := make([]json.RawMessage, len(records))
+	for i, r := range records {
+		values[i] = r.raw
+	}
+	raw, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(raw, '\n'), nil
+}
+
+// Whether a directory is the directory data files are read from, eg. "." or DATA_DIR
+func isDataDir(dir string) bool {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false // A directory which does not exist yet is not read from
+	}
+	dataDir, err := os.Stat(filepath.Dir(internal.DataFilePath(internal.FieldDefinitionsFile)))
+	return err == nil && os.SameFile(info, dataDir)
+}
+
+/*
+*		Write data files to a directory, created if needed. Files already in the directory are only replaced if forced,
+*		otherwise nothing is written. Each file is written to a temporary file first and then renamed, so that no file
+*		is left half written
+*
+*	    @return (error): If any file already exists, or files could not be written
+ */
+func writeFiles(dir string, files map[string][]byte, force bool) error {
+	names := make([]string, 0, len(files))
+	for file := range files {
+		names = append(names, file)
+	}
+	sort.Strings(names)
+	for _, file := range names {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil && !force {
+			return fmt.Errorf("%v already exists, export with --force to replace it", filepath.Join(dir, file))
+		}
+	}
+	for _, file := range names {
+		if err := internal.WriteFile(filepath.Join(dir, file), files[file]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
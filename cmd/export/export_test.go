@@ -0,0 +1,140 @@
+package export
+
+import (
+	"ZendeskChallenge/cmd/search"
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"ZendeskChallenge/pkg/zsearch"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Directory of the test data shared by all packages, relative to the directory of a package
+var testDataDir = filepath.Join("..", "..", "testdata")
+
+// Test exporting organizations along with their related records, in the schema of the data files
+
+func Test_ExecuteExportCommand(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+	out := filepath.Join(t.TempDir(), "export")
+
+	execute := func(args ...string) (string, error) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		root := &cobra.Command{Use: "cli"}
+		root.AddCommand(NewExportCmd(), search.NewSearchCmd())
+		root.SetOut(buffer)
+		root.SetErr(buffer)
+		root.SetArgs(args)
+		err := root.Execute()
+		return buffer.String(), err
+	}
+	// IDs of the records of an exported data file
+	exportedIDs := func(file string) []any {
+		raw, err := os.ReadFile(filepath.Join(out, file))
+		assert.Nil(t, err)
+		var records []map[string]any
+		assert.Nil(t, json.Unmarshal(raw, &records))
+		var ids []any
+		for _, record := range records {
+			ids = append(ids, record["_id"])
+		}
+		return ids
+	}
+
+	t.Run("Export an organization and assert its records and the records related to them are exported", func(t *testing.T) {
+		output, err := execute("export", "--organization", "102", "--out", out)
+		assert.Nil(t, err)
+		assert.Equal(t, "Exported 3 users to "+filepath.Join(out, "users.json")+"\n"+
+			"Exported 1 organizations to "+filepath.Join(out, "organizations.json")+"\n"+
+			"Exported 2 tickets to "+filepath.Join(out, "tickets.json")+"\n"+
+			"Exported 1 groups to "+filepath.Join(out, "groups.json")+"\n"+
+			"Exported 2 comments to "+filepath.Join(out, "ticket_comments.json")+"\n"+
+			"Exported 1 ratings to "+filepath.Join(out, "satisfaction_ratings.json")+"\n", output)
+		assert.Equal(t, []any{"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", "3ff0599a-fe0f-4f8f-ac31-e2636843bcea"}, exportedIDs("tickets.json"))
+		assert.Equal(t, []any{float64(70), float64(22), float64(43)}, exportedIDs("users.json"), "Submitters and assignees of tickets")
+		assert.Equal(t, []any{float64(102)}, exportedIDs("organizations.json"), "Organizations of submitters and assignees are not exported")
+		assert.Equal(t, []any{float64(360000100)}, exportedIDs("groups.json"), "Groups of exported agents")
+		assert.Equal(t, []any{float64(1), float64(2)}, exportedIDs("ticket_comments.json"))
+		assert.Equal(t, []any{float64(1)}, exportedIDs("satisfaction_ratings.json"))
+		assert.FileExists(t, filepath.Join(out, internal.FieldDefinitionsFile))
+	})
+	t.Run("Assert exported records are in the schema of the data files", func(t *testing.T) {
+		var read, exported []map[string]any
+		raw, _ := os.ReadFile(filepath.Join(testDataDir, "users.json"))
+		_ = json.Unmarshal(raw, &read)
+		raw, _ = os.ReadFile(filepath.Join(out, "users.json"))
+		_ = json.Unmarshal(raw, &exported)
+		assert.Contains(t, read, exported[0])
+	})
+	t.Run("Load the exported data and search every entity of it", func(t *testing.T) {
+		t.Setenv(internal.DataDirEnv, out)
+		dataset, err := zsearch.Load(context.Background())
+		assert.Nil(t, err)
+		for _, entity := range models.Registry.All() {
+			results, err := dataset.Find(context.Background(), entity.EntityName())
+			assert.Nil(t, err)
+			assert.Equal(t, len(exportedIDs(entity.DataFile())), len(results), entity.EntityName())
+		}
+		output, err := execute("search", "ticket", "--name", "organization_id", "--value", "102")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, strings.Count(output, "------------------------------------------------"))
+		assert.True(t, strings.Contains(output, "submitter_name: Moran Daniels\n"))
+		assert.True(t, strings.Contains(output, "assignee_name: Catalina Simpson\n"))
+		output, err = execute("search", "rating", "--name", "score", "--value", "bad")
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(output, "group_name: Support\n"))
+		output, err = execute("search", "group", "--name", "name", "--value", "Support")
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(output, "Catalina Simpson"))
+		_, err = execute("search", "user", "--name", "_id", "--value", "74")
+		assert.True(t, errors.Is(err, internal.ErrNoResults), "Users not related to the organization are not exported")
+	})
+	t.Run("Export an organization without the records related to it and load the exported data", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := execute("export", "--organization", "102", "--out", dir, "--exclude-related")
+		assert.Nil(t, err)
+		raw, err := os.ReadFile(filepath.Join(dir, "users.json"))
+		assert.Nil(t, err)
+		assert.Equal(t, "[]\n", string(raw), "Submitters and assignees of tickets are of other organizations")
+
+		t.Setenv(internal.DataDirEnv, dir)
+		for _, entity := range models.Registry.All() {
+			_, err := execute("search", entity.EntityName(), "--name", entity.PrimaryKey(), "--value", "1")
+			assert.True(t, errors.Is(err, internal.ErrNoResults), entity.EntityName())
+		}
+	})
+	t.Run("Export to a directory already exported to", func(t *testing.T) {
+		_, err := execute("export", "--organization", "107", "--out", out)
+		assert.Equal(t, filepath.Join(out, internal.FieldDefinitionsFile)+" already exists, export with --force to replace it", err.Error())
+		assert.Equal(t, []any{float64(102)}, exportedIDs("organizations.json"), "Nothing is replaced")
+
+		_, err = execute("export", "--organization", "107", "--out", out, "--force")
+		assert.Nil(t, err)
+		assert.Equal(t, []any{float64(107)}, exportedIDs("organizations.json"))
+		assert.Equal(t, []any{float64(22), float64(74)}, exportedIDs("users.json"))
+	})
+	t.Run("Export an unknown organization", func(t *testing.T) {
+		_, err := execute("export", "--organization", "102,999", "--out", t.TempDir())
+		assert.True(t, errors.Is(err, zsearch.ErrNotFound))
+		assert.Equal(t, "organization 999 not found in organizations.json", err.Error())
+	})
+	t.Run("Export to the directory data is read from", func(t *testing.T) {
+		_, err := execute("export", "--organization", "102", "--out", testDataDir+"/", "--force")
+		assert.Equal(t, fmt.Sprintf("invalid --out %q, data is read from it", testDataDir+"/"), err.Error())
+		_, err = execute("export", "--organization", "102", "--out", "", "--force")
+		assert.Equal(t, "invalid --out, expected a directory", err.Error())
+	})
+	t.Run("Export without required flags", func(t *testing.T) {
+		_, err := execute("export", "--organization", "102")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,41 @@
+// Package export -
+//
+// Defines the command for exporting a subset of the data, and which entrypoints to invoke for it
+//
+
+package export
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewExportCmd - Define command exporting the data of organizations, eg. cli export --organization 119 --out dir/ /*
+func NewExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the data of organizations, along with the records related to them",
+		Long: `Export the data of organizations to a directory, in the same schema as the data files, so that the CLI can
+be run against the exported subset (eg. DATA_DIR=dir/ cli search ...). Records are followed by the relationships of
+the entities referring to a record of another entity (eg. organization_id, submitter_id, ticket_id). Exported are the
+organizations and the records referring to them (users and tickets), the users tickets were submitted by or assigned
+to (even if they are of other organizations, unless --exclude-related is given), the records referring to exported
+records only (eg. comments and ratings of exported tickets, by exported users), and the groups of any exported agent
+(by agent_ids). A data file is written for every entity, even without records, and definitions of custom fields are
+exported as they are.`,
+		Example: `  cli export --organization 119 --out acme/
+  cli export --organization 119,121 --out acme/ --force
+  cli export --organization 119 --out acme/ --exclude-related`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return triggerExport(cmd)
+		},
+	}
+	cmd.Flags().StringSlice("organization", nil, "IDs of the organizations to export, separated by commas (repeatable)")
+	cmd.Flags().String("out", "", "Directory to write the exported data files to, created if needed")
+	cmd.Flags().Bool("force", false, "Replace data files already in the directory")
+	cmd.Flags().Bool("exclude-related", false, "Leave out the users tickets were submitted by or assigned to, if they are of other organizations")
+	_ = cmd.MarkFlagRequired("organization")
+	_ = cmd.MarkFlagRequired("out")
+	_ = cmd.MarkFlagDirname("out")
+	return cmd
+}
@@ -6,27 +6,32 @@
 package list
 
 import (
-	"ZendeskChallenge/models/organizations"
-	"ZendeskChallenge/models/tickets"
-	"ZendeskChallenge/models/users"
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
 	"github.com/spf13/cobra"
 )
 
 func fieldList(cmd *cobra.Command, args []string) error {
-	cmd.Print("Searchable user fields with 'search user' command")
-	cmd.Print("\n--------------------------------------------\n")
-	for field := range users.KeyMappings {
-		cmd.Printf("%v\n", field)
+	definitions, err := internal.LoadFieldDefinitions(internal.DataDir())
+	if err != nil {
+		return err
 	}
-	cmd.Print("\n\nSearchable organization fields with 'search organization' command")
-	cmd.Print("\n--------------------------------------------\n")
-	for field := range organizations.KeyMappings {
-		cmd.Printf("%v\n", field)
-	}
-	cmd.Print("\n\nSearchable ticket fields with 'search ticket' command")
-	cmd.Print("\n--------------------------------------------\n")
-	for field := range tickets.KeyMappings {
-		cmd.Printf("%v\n", field)
+	for i, entity := range models.Registry.All() {
+		if i > 0 {
+			cmd.Print("\n\n")
+		}
+		cmd.Printf("Searchable %v fields with 'search %v' command", entity.EntityName(), entity.EntityName())
+		cmd.Print("\n--------------------------------------------\n")
+		for _, field := range entity.SearchableFields() {
+			cmd.Printf("%v\n", field)
+		}
+		if entity.CustomFieldPrefix() == "" {
+			continue
+		}
+		for _, definition := range definitions.ForEntity(entity.EntityName()) {
+			cmd.Printf("%v (%v, %v)\n", definition.Key, definition.Name, definition.Type)
+		}
+		cmd.Printf("%v<field>\n", entity.CustomFieldPrefix())
 	}
 	return nil
 }
@@ -1,9 +1,7 @@
 package list
 
 import (
-	"ZendeskChallenge/models/organizations"
-	"ZendeskChallenge/models/tickets"
-	"ZendeskChallenge/models/users"
+	"ZendeskChallenge/models"
 	"bytes"
 	"github.com/stretchr/testify/assert"
 	"strings"
@@ -21,14 +19,14 @@ func Test_ExecuteListCommand(t *testing.T) {
 
 		expected := "Zendesk CLI list command\n"
 		assert.True(t, strings.HasPrefix(buffer.String(), expected), "actual is not expected")
-		for key, _ := range users.KeyMappings {
-			assert.True(t, strings.Contains(buffer.String(), key), "user field names are contained in output, for field "+key+"'")
-		}
-		for key, _ := range organizations.KeyMappings {
-			assert.True(t, strings.Contains(buffer.String(), key), "organization field names are contained in output, for field "+key+"'")
-		}
-		for key, _ := range tickets.KeyMappings {
-			assert.True(t, strings.Contains(buffer.String(), key), "ticket field names are contained in output, for field '"+key+"'")
+		for _, entity := range models.Registry.All() {
+			assert.True(t, strings.Contains(buffer.String(), "'search "+entity.EntityName()+"'"), "entity search command is contained in output")
+			for _, key := range entity.SearchableFields() {
+				assert.True(t, strings.Contains(buffer.String(), key), entity.EntityName()+" field names are contained in output, for field '"+key+"'")
+			}
+			if entity.CustomFieldPrefix() != "" {
+				assert.True(t, strings.Contains(buffer.String(), entity.CustomFieldPrefix()+"<field>\n"), "custom fields are contained in output")
+			}
 		}
 	})
 }
@@ -0,0 +1,104 @@
+// Package query -
+//
+// Defines the command for searching entities with the query language, and which entrypoints to invoke for it
+//
+
+package query
+
+import (
+	"ZendeskChallenge/internal"
+	"github.com/spf13/cobra"
+)
+
+// NewQueryCmd - Define command searching entities of any type by a query, eg. ticket where status = open /*
+func NewQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query <query>",
+		Short: "Search entities with a query, eg. 'ticket where status in (open, pending) order by created_at desc limit 20'",
+		Long: `Search entities with a query of the form:
+
+  <entity> [where <conditions>] [order by <field> [asc|desc], ...] [limit <number>]
+
+Conditions compare fields with =, !=, ~ (contains), !~, <, <=, > and >=, match values with 'in (a, b)', or match
+missing, null or empty fields with 'is [not] missing|null|empty'. Combine them with and, or, not and parentheses.
+Fields of related entities are prefixed by the relationship, eg. organization.name or submitter.role.`,
+		Example: `  cli query 'ticket where status in (open, pending) and priority = high and organization.name ~ "Multron" order by created_at desc limit 20'
+  cli query 'user where role = admin and not (alias is null or tickets.status = closed)'`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return triggerQuery(cmd, args[0])
+		},
+	}
+	cmd.Flags().String("tz", "", "Timezone to search dates and display timestamps in, eg. Australia/Melbourne or +10:00")
+	cmd.Flags().String("output", internal.OutputText, "Format of results: text, or json in the schema of the data files")
+	cmd.Flags().String("fields", "", "Fields of results to display, separated by commas, eg. _id,name,email (default all)")
+	cmd.Flags().Bool("explain", false, "Display the plan of the query: how each comparison is evaluated, and estimated vs actual counts")
+	cmd.Flags().Bool("timing", false, "Display the time spent in each phase of the query")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(internal.ResultFormats, cobra.ShellCompDirectiveNoFileComp))
+	cmd.AddCommand(NewSaveQueryCmd(), NewRunQueryCmd(), NewListQueriesCmd(), NewDeleteQueryCmd())
+	return cmd
+}
+
+// NewSaveQueryCmd - Define command saving a command line by name, eg. cli query save org-tickets -- search ticket ... /*
+func NewSaveQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "save <name> -- <command>",
+		Short: "Save a command line by name, with $placeholders filled when it is run",
+		Long: `Save a command line of the CLI by name, to run it again with 'cli query run <name>'. Values of the command
+line may have $placeholders (eg. $org_id, quoted so that the shell leaves them be), filled with --param when it is
+run. $$ is a literal $. Saved queries are stored in queries.json, in the config directory of the user.`,
+		Example: `  cli query save org-tickets -- search ticket --name organization_id --value '$org_id'
+  cli query save open-tickets -- query 'ticket where status = open and organization_id = $org_id'`,
+		Annotations: map[string]string{savedQueryAnnotation: "save"},
+		Args:        cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return saveQuery(cmd, args[0], args[1:])
+		},
+	}
+	cmd.Flags().Bool("force", false, "Replace a query already saved by the name")
+	return cmd
+}
+
+// NewRunQueryCmd - Define command running a saved query by name, eg. cli query run org-tickets --param org_id=119 /*
+func NewRunQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "run <name>",
+		Short:             "Run a saved query, filling its $placeholders with --param",
+		Example:           `  cli query run org-tickets --param org_id=119`,
+		Annotations:       map[string]string{savedQueryAnnotation: "run"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSavedQueries,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSavedQuery(cmd, args[0])
+		},
+	}
+	cmd.Flags().StringArrayP("param", "p", nil, "Value of a placeholder of the saved query, as <name>=<value> (repeatable)")
+	return cmd
+}
+
+// NewListQueriesCmd - Define command listing all saved queries /*
+func NewListQueriesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "list",
+		Short:       "List saved queries, along with their placeholders",
+		Annotations: map[string]string{savedQueryAnnotation: "list"},
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listSavedQueries(cmd)
+		},
+	}
+}
+
+// NewDeleteQueryCmd - Define command deleting a saved query by name /*
+func NewDeleteQueryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete <name>",
+		Short:             "Delete a saved query",
+		Annotations:       map[string]string{savedQueryAnnotation: "delete"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSavedQueries,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteSavedQuery(cmd, args[0])
+		},
+	}
+}
@@ -0,0 +1,76 @@
+// Package query -
+//
+// This is the entry point of queries of the query language, which are parsed and evaluated by the zsearch package
+//
+
+package query
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/pkg/zsearch"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+/*
+*		Trigger a query. Parses the query before loading any data, so that errors in it are shown straight away,
+*		pointing at the column they were found at
+*
+*	    @return (error): If any error occurs during parsing or evaluation of the query, or ErrNoResults if nothing matched
+*		Displays results if no errors
+ */
+func triggerQuery(cmd *cobra.Command, query string) error {
+	start := time.Now()
+	statement, err := zsearch.ParseQuery(query)
+	if err != nil {
+		printError(cmd, err)
+		return err
+	}
+	if tz := internal.TimezoneOf(cmd); tz != "" {
+		statement.Location, err = internal.LoadTimezone(tz)
+		if err != nil {
+			return err
+		}
+	}
+	options, err := internal.ResolveDisplayOptions(cmd, statement.Entity.EntityName(), statement.Entity.Mappings(), internal.ResultFormats)
+	if err != nil {
+		return cmd.FlagErrorFunc()(cmd, err)
+	}
+	explain, _ := cmd.Flags().GetBool("explain")
+	timing, _ := cmd.Flags().GetBool("timing")
+	ctx, trace := zsearch.Traced(cmd.Context(), explain, timing)
+	dataset, err := zsearch.Load(ctx)
+	if err != nil {
+		return err
+	}
+	results, err := dataset.Query(ctx, statement)
+	fields := log.Fields{"entity": statement.Entity.EntityName(), "query": query, "duration": time.Since(start)}
+	if err != nil {
+		printError(cmd, err)
+		log.WithFields(fields).Error(err)
+		return err
+	}
+	displayed := time.Now()
+	filtered := internal.Records[any](results)
+	internal.InTimezone(filtered, statement.Location)
+	internal.DisplayResultsWith(cmd, filtered, statement.Entity.Mappings(), options)
+	trace.Observe(zsearch.PhaseDisplay, time.Since(displayed))
+	trace.Write(cmd.OutOrStdout(), explain, timing)
+	fields["results"] = len(results)
+	log.WithFields(fields).Infof("All results displayed")
+	if len(results) == 0 {
+		return fmt.Errorf("%w for query %q", internal.ErrNoResults, query)
+	}
+	return nil
+}
+
+// Print the query pointing at the column of an error, if it is an error in the query
+func printError(cmd *cobra.Command, err error) {
+	var parseError *zsearch.ParseError
+	if errors.As(err, &parseError) {
+		cmd.PrintErrln(parseError.Pointer())
+	}
+}
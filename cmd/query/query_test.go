@@ -0,0 +1,63 @@
+package query
+
+import (
+	"ZendeskChallenge/internal"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Directory of the test data shared by all packages, relative to the directory of a package
+var testDataDir = filepath.Join("..", "..", "testdata")
+
+func Test_ExecuteQueryCommand(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+
+	t.Run("Execute query command and assert output", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewQueryCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{`ticket where status = pending and organization.name ~ "geek" order by created_at desc`})
+		err := cmd.Execute()
+		assert.Nil(t, err)
+
+		assert.True(t, strings.HasPrefix(buffer.String(), "======== All results ========"), "Message output starts as expected")
+		assert.Equal(t, 1, strings.Count(buffer.String(), "------------------------------------------------"))
+		assert.True(t, strings.Contains(buffer.String(), "_id: 20615fe1-765b-4ff5-b4f6-ea42dcc8cac3\n"))
+		assert.True(t, strings.Contains(buffer.String(), "organization_name: Geekfarm\n"), "Related entities are displayed")
+	})
+	t.Run("Execute query command with --explain and assert the plan of each comparison", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewQueryCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{`ticket where status = pending and subject ~ "Korea"`, "--explain"})
+		_ = cmd.Execute()
+
+		assert.True(t, strings.Contains(buffer.String(), "======== Query plan ========\n1. ticket where status = \"pending\"\n"))
+		assert.True(t, strings.Contains(buffer.String(), "2. ticket where subject ~ \"Korea\"\n   scan of entities (~): searched "))
+		assert.False(t, strings.Contains(buffer.String(), "======== Timing ========"))
+	})
+	t.Run("Execute query command with an error in the query", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewQueryCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"ticket where status in (open pending)"})
+		err := cmd.Execute()
+		assert.NotNil(t, err)
+		assert.True(t, strings.Contains(buffer.String(), "ticket where status in (open pending)\n                             ^\n"), "Error points at its column")
+	})
+	t.Run("Execute query command without a query", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewQueryCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{})
+		err := cmd.Execute()
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,343 @@
+// Package query -
+//
+// This is the entry point of saved queries: command lines of the CLI (eg. search ticket --name organization_id --value
+// $org_id) saved by name in the config directory of the user, and run by name with their $placeholders filled
+//
+
+package query
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/pkg/zsearch"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SavedQueriesFile - File saved queries are stored in, in the config directory of the user
+const SavedQueriesFile = "queries.json"
+
+// Annotation of the commands of saved queries, which cannot be saved themselves
+const savedQueryAnnotation = "saved-query"
+
+var (
+	// Names of saved queries, eg. open-tickets
+	validName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	// Placeholders of saved queries, eg. $org_id. $$ is a literal $
+	placeholder = regexp.MustCompile(`\$(\$|[A-Za-z_][A-Za-z0-9_]*)`)
+	// Arguments which need no quotes in a shell
+	shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+)
+
+// SavedQuery - Command line of the CLI saved by name, without the name of the CLI itself
+type SavedQuery struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+}
+
+// Placeholders - Names of the placeholders of a saved query, in order they first appear in
+func (q SavedQuery) Placeholders() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, arg := range q.Args {
+		for _, match := range placeholder.FindAllStringSubmatch(arg, -1) {
+			if name := match[1]; name != "$" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+/*
+*		Arguments of a saved query with its placeholders filled by parameters
+*
+*	    @return ([]string, error): Arguments, and error naming every placeholder without a parameter
+ */
+func (q SavedQuery) Expand(params map[string]string) ([]string, error) {
+	var missing []string
+	for _, name := range q.Placeholders() {
+		if _, ok := params[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("saved query %v needs a value of %v, eg. --param %v=<value>", q.Name, "$"+strings.Join(missing, ", $"), missing[0])
+	}
+	args := make([]string, len(q.Args))
+	for i, arg := range q.Args {
+		args[i] = placeholder.ReplaceAllStringFunc(arg, func(match string) string {
+			if match == "$$" {
+				return "$"
+			}
+			return params[match[1:]]
+		})
+	}
+	return args, nil
+}
+
+// String - Command line of a saved query, quoted as in a shell
+func (q SavedQuery) String() string {
+	quoted := make([]string, len(q.Args))
+	for i, arg := range q.Args {
+		quoted[i] = shellQuote(arg)
+	}
+	return "cli " + strings.Join(quoted, " ")
+}
+
+// Quote an argument as in a shell, if it has anything but letters, digits and punctuation safe in a shell
+func shellQuote(arg string) string {
+	if shellSafe.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+/*
+*		Read saved queries of the user, sorted by name
+*
+*	    @return ([]SavedQuery, error): Saved queries, none if nothing was saved yet, and error if the file is invalid
+ */
+func readSavedQueries() ([]SavedQuery, error) {
+	path, err := internal.ConfigFilePath(SavedQueriesFile)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var queries []SavedQuery
+	if err := json.Unmarshal(raw, &queries); err != nil {
+		return nil, fmt.Errorf("invalid saved queries in %v: %w", path, err)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].Name < queries[j].Name
+	})
+	return queries, nil
+}
+
+// Write saved queries of the user, replacing all saved before
+func writeSavedQueries(queries []SavedQuery) error {
+	path, err := internal.ConfigFilePath(SavedQueriesFile)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(queries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return internal.WriteFile(path, raw)
+}
+
+/*
+*		Find a saved query by its name
+*
+*	    @return (SavedQuery, error): Saved query, and error wrapping zsearch.ErrNotFound if nothing is saved by the name
+ */
+func findSavedQuery(name string) (SavedQuery, error) {
+	queries, err := readSavedQueries()
+	if err != nil {
+		return SavedQuery{}, err
+	}
+	for _, query := range queries {
+		if query.Name == name {
+			return query, nil
+		}
+	}
+	return SavedQuery{}, fmt.Errorf("saved query %v %w, see 'cli query list'", name, zsearch.ErrNotFound)
+}
+
+/*
+*		Save a command line by name. The command line must be a command of the CLI, other than commands of saved
+*		queries themselves
+*
+*	    @return (error): If the name or command line is invalid, or a query is already saved by the name (unless forced)
+ */
+func saveQuery(cmd *cobra.Command, name string, args []string) error {
+	if !validName.MatchString(name) {
+		return cmd.FlagErrorFunc()(cmd, fmt.Errorf("invalid name %q of a saved query, use letters, digits, '_', '.' and '-' only", name))
+	}
+	if _, _, err := findCommand(cmd.Root(), args); err != nil {
+		return cmd.FlagErrorFunc()(cmd, err)
+	}
+	queries, err := readSavedQueries()
+	if err != nil {
+		return err
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	saved := SavedQuery{Name: name, Args: args}
+	replaced := false
+	for i, query := range queries {
+		if query.Name != name {
+			continue
+		}
+		if !force {
+			return fmt.Errorf("a query is already saved as %v, save it with --force to replace it", name)
+		}
+		queries[i], replaced = saved, true
+	}
+	if !replaced {
+		queries = append(queries, saved)
+	}
+	if err := writeSavedQueries(queries); err != nil {
+		return err
+	}
+	cmd.Println(fmt.Sprintf("Saved %v: %v", name, saved))
+	if names := saved.Placeholders(); len(names) > 0 {
+		cmd.Println(fmt.Sprintf("Run it with: cli query run %v --param %v=<value>", name, strings.Join(names, "=<value> --param ")))
+	}
+	return nil
+}
+
+/*
+*		Run a saved query, with its placeholders filled by --param flags. The saved command line is run as if it was
+*		given to the CLI, along with the global flags the CLI was given (eg. -v)
+*
+*	    @return (error): If the saved query is unknown, a placeholder has no value, or any error of the command run
+ */
+func runSavedQuery(cmd *cobra.Command, name string) error {
+	saved, err := findSavedQuery(name)
+	if err != nil {
+		return err
+	}
+	params := map[string]string{}
+	values, _ := cmd.Flags().GetStringArray("param")
+	for _, value := range values {
+		key, param, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return cmd.FlagErrorFunc()(cmd, fmt.Errorf("invalid --param %q, expected <name>=<value>", value))
+		}
+		params[strings.TrimPrefix(key, "$")] = param
+	}
+	args, err := saved.Expand(params)
+	if err != nil {
+		return cmd.FlagErrorFunc()(cmd, err)
+	}
+	target, targetArgs, err := findCommand(cmd.Root(), args)
+	if err != nil {
+		return err
+	}
+	return executeCommand(cmd.Context(), target, targetArgs)
+}
+
+// Display all saved queries, along with their placeholders
+func listSavedQueries(cmd *cobra.Command) error {
+	queries, err := readSavedQueries()
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		cmd.Println("No saved queries, save one with: cli query save <name> -- <command>")
+		return nil
+	}
+	for _, query := range queries {
+		line := fmt.Sprintf("%-20v %v", query.Name, query)
+		if names := query.Placeholders(); len(names) > 0 {
+			line += fmt.Sprintf(" (params: %v)", strings.Join(names, ", "))
+		}
+		cmd.Println(line)
+	}
+	return nil
+}
+
+/*
+*		Delete a saved query by its name
+*
+*	    @return (error): Error wrapping zsearch.ErrNotFound if nothing is saved by the name
+ */
+func deleteSavedQuery(cmd *cobra.Command, name string) error {
+	queries, err := readSavedQueries()
+	if err != nil {
+		return err
+	}
+	kept := []SavedQuery{}
+	for _, query := range queries {
+		if query.Name != name {
+			kept = append(kept, query)
+		}
+	}
+	if len(kept) == len(queries) {
+		return fmt.Errorf("saved query %v %w, see 'cli query list'", name, zsearch.ErrNotFound)
+	}
+	if err := writeSavedQueries(kept); err != nil {
+		return err
+	}
+	cmd.Println("Deleted " + name)
+	return nil
+}
+
+// Completion of the name of a saved query, for the commands running or deleting one
+func completeSavedQueries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	queries, err := readSavedQueries()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, query := range queries {
+		if strings.HasPrefix(query.Name, toComplete) {
+			names = append(names, query.Name+"\t"+query.String())
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+/*
+*		Find the command of a command line of the CLI, which must be runnable and not a command of saved queries
+*
+*	    @return (*cobra.Command, []string, error): Command, its arguments (without the names of commands), and error if
+*		there is no such command
+ */
+func findCommand(root *cobra.Command, args []string) (*cobra.Command, []string, error) {
+	target, targetArgs, err := root.Find(args)
+	switch {
+	case err != nil:
+		return nil, nil, err
+	case len(args) == 0 || target == root || !target.Runnable():
+		return nil, nil, fmt.Errorf("invalid command %q to save, eg. cli query save <name> -- search ticket --name status --value open", strings.Join(args, " "))
+	case target.Annotations[savedQueryAnnotation] != "":
+		return nil, nil, fmt.Errorf("saved queries cannot run %q", target.CommandPath())
+	}
+	return target, targetArgs, nil
+}
+
+/*
+*		Execute a command with arguments, as cobra does once it has found the command: parse and validate its flags
+*		and arguments, and run it. Hooks of the root command (eg. configuring logs) are not run again
+*
+*	    @return (error): Error in usage of the command, or any error it fails with
+ */
+func executeCommand(ctx context.Context, target *cobra.Command, args []string) error {
+	if err := target.ParseFlags(args); err != nil {
+		return target.FlagErrorFunc()(target, err)
+	}
+	for _, validate := range []func() error{target.ValidateRequiredFlags, target.ValidateFlagGroups} {
+		if err := validate(); err != nil {
+			return target.FlagErrorFunc()(target, err)
+		}
+	}
+	args = target.Flags().Args()
+	if err := target.ValidateArgs(args); err != nil {
+		return target.FlagErrorFunc()(target, err)
+	}
+	target.SetContext(ctx)
+	if target.RunE != nil {
+		return target.RunE(target, args)
+	}
+	target.Run(target, args)
+	return nil
+}
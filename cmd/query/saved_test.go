@@ -0,0 +1,118 @@
+package query
+
+import (
+	"ZendeskChallenge/cmd/search"
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/pkg/zsearch"
+	"bytes"
+	"errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test saving queries by name, and running them with their placeholders filled
+
+func TestSavedQuery(t *testing.T) {
+	saved := SavedQuery{Name: "org-tickets", Args: []string{"query", "ticket where organization_id = $org_id and status = $status and price = $$5 and $org_id > 0"}}
+
+	t.Run("test placeholders in order they first appear in", func(t *testing.T) {
+		assert.Equal(t, []string{"org_id", "status"}, saved.Placeholders())
+	})
+	t.Run("test placeholders filled by parameters", func(t *testing.T) {
+		args, err := saved.Expand(map[string]string{"org_id": "119", "status": "open"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"query", "ticket where organization_id = 119 and status = open and price = $5 and 119 > 0"}, args)
+	})
+	t.Run("test placeholders without parameters", func(t *testing.T) {
+		_, err := saved.Expand(map[string]string{"status": "open"})
+		assert.Equal(t, "saved query org-tickets needs a value of $org_id, eg. --param org_id=<value>", err.Error())
+	})
+	t.Run("test command line quoted as in a shell", func(t *testing.T) {
+		assert.Equal(t, "cli query 'ticket where organization_id = $org_id and status = $status and price = $$5 and $org_id > 0'", saved.String())
+		assert.Equal(t, `cli search user --name name --value 'O'\''Reilly'`, SavedQuery{Args: []string{"search", "user", "--name", "name", "--value", "O'Reilly"}}.String())
+	})
+}
+
+func Test_ExecuteSavedQueryCommands(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// Root command of the CLI, as saved queries run any of its commands
+	execute := func(args ...string) (string, error) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		root := &cobra.Command{Use: "cli"}
+		root.AddCommand(NewQueryCmd(), search.NewSearchCmd())
+		root.SetOut(buffer)
+		root.SetErr(buffer)
+		root.SetArgs(args)
+		err := root.Execute()
+		return buffer.String(), err
+	}
+
+	t.Run("Save queries and assert they are listed", func(t *testing.T) {
+		output, err := execute("query", "save", "org-users", "--", "search", "user", "--name", "organization_id", "--value", "$org_id")
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(output, "Run it with: cli query run org-users --param org_id=<value>"))
+		_, err = execute("query", "save", "admins", "--", "query", "user where role = admin")
+		assert.Nil(t, err)
+
+		output, err = execute("query", "list")
+		assert.Nil(t, err)
+		assert.Equal(t, "admins               cli query 'user where role = admin'\n"+
+			"org-users            cli search user --name organization_id --value '$org_id' (params: org_id)\n", output)
+		path, _ := os.UserConfigDir()
+		assert.FileExists(t, filepath.Join(path, "zsearch", SavedQueriesFile))
+	})
+	t.Run("Run a saved query with its placeholders filled and assert output", func(t *testing.T) {
+		output, err := execute("query", "run", "org-users", "--param", "org_id=114")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, strings.Count(output, "------------------------------------------------"))
+		assert.True(t, strings.Contains(output, "organization_name: Isotronic\n"))
+
+		output, err = execute("query", "run", "admins")
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(output, "name: Melissa Bishop\n"))
+	})
+	t.Run("Run a saved query without a value of its placeholder", func(t *testing.T) {
+		_, err := execute("query", "run", "org-users")
+		assert.Equal(t, "saved query org-users needs a value of $org_id, eg. --param org_id=<value>", err.Error())
+	})
+	t.Run("Save a query by a name already saved", func(t *testing.T) {
+		_, err := execute("query", "save", "admins", "--", "query", "user where role = agent")
+		assert.NotNil(t, err)
+		_, err = execute("query", "save", "admins", "--force", "--", "query", "user where role = agent")
+		assert.Nil(t, err)
+		output, _ := execute("query", "run", "admins")
+		assert.True(t, strings.Contains(output, "name: Catalina Simpson\n"))
+	})
+	t.Run("Save invalid queries", func(t *testing.T) {
+		_, err := execute("query", "save", "loop", "--", "query", "run", "admins")
+		assert.Equal(t, `saved queries cannot run "cli query run"`, err.Error())
+		_, err = execute("query", "save", "my query", "--", "search", "user")
+		assert.NotNil(t, err)
+		_, err = execute("query", "save", "nothing", "--", "find", "user")
+		assert.NotNil(t, err)
+	})
+	t.Run("Complete names of saved queries", func(t *testing.T) {
+		output, err := execute(cobra.ShellCompRequestCmd, "query", "run", "adm")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(output, "admins\tcli query 'user where role = agent'\n:4\n"), output)
+	})
+	t.Run("Delete saved queries", func(t *testing.T) {
+		_, err := execute("query", "delete", "admins")
+		assert.Nil(t, err)
+		_, err = execute("query", "run", "admins")
+		assert.True(t, errors.Is(err, zsearch.ErrNotFound))
+		_, err = execute("query", "delete", "admins")
+		assert.True(t, errors.Is(err, zsearch.ErrNotFound))
+	})
+	t.Run("Run a query which is not saved, rather than a saved one", func(t *testing.T) {
+		output, err := execute("query", "user where _id = 22")
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(output, "_id: 22\n"))
+	})
+}
@@ -0,0 +1,71 @@
+// Package report -
+//
+// Defines all the commands for reports, and which entrypoints to invoke for them
+//
+
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// NewReportCmd - Parent command setup for all report commands /*
+func NewReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Zendesk Reports",
+	}
+	cmd.AddCommand(NewSLACmd())
+	cmd.AddCommand(NewWorkloadCmd())
+	cmd.AddCommand(NewOrganizationCmd())
+	return cmd
+}
+
+// NewSLACmd - Define report command for overdue tickets and tickets due soon /*
+func NewSLACmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sla",
+		Short: "report overdue tickets and tickets due soon, by priority, assignee and organization",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return triggerSLAReport(cmd)
+		},
+	}
+	cmd.Flags().String("within", "7d", "Window of time from now, for tickets due soon, eg. 12h, 7d or 2w")
+	cmd.Flags().String("now", "", "Time to report as of instead of the current time, eg. 2016-08-01 or \"2016-08-01T09:00:00 +10:00\"")
+	return cmd
+}
+
+// NewWorkloadCmd - Define report command for tickets assigned to each assignee /*
+func NewWorkloadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workload",
+		Short: "report tickets assigned to each assignee, by status and priority, with incidents and overdue tickets",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return triggerWorkloadReport(cmd)
+		},
+	}
+	cmd.Flags().String("sort", ColumnTotal, "Column to sort by, eg. name, total, overdue, status_open or priority_urgent")
+	cmd.Flags().Bool("reverse", false, "Reverse the sort order (by default counts are sorted from most to least)")
+	cmd.Flags().String("output", internal.OutputText, "Output format, one of "+strings.Join(internal.OutputFormats, ", "))
+	cmd.Flags().String("now", "", "Time to count overdue tickets as of instead of the current time, eg. 2016-08-01")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(internal.OutputFormats, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// NewOrganizationCmd - Define report command for the health of organizations, as an HTML page /*
+func NewOrganizationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "org",
+		Short: "report details, users and tickets of organizations as a static HTML page for each",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return triggerOrganizationReport(cmd)
+		},
+	}
+	cmd.Flags().String("html", "", "File to write the HTML page to, eg. out.html. Pages of all organizations are named by their _id, eg. out-101.html")
+	cmd.Flags().Int("organization", 0, "_id of the organization to report on, written to --html (all organizations by default)")
+	cmd.Flags().Int("recent", 5, "Number of most recently created tickets to show for each organization")
+	_ = cmd.MarkFlagRequired("html")
+	return cmd
+}
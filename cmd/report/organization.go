@@ -0,0 +1,191 @@
+// Package report -
+//
+// This file is meant for the organization health report: a static HTML page with the details, users and tickets of
+// an organization, one for each organization, for sharing with people who do not use the CLI. A page has no external
+// assets (styles are inline), so that it can be emailed as a single file
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/organizations"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+	"ZendeskChallenge/pkg/zsearch"
+	"bytes"
+	_ "embed"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed templates/organization.html
+var organizationTemplate string
+
+// Count - Number of tickets with a status or priority
+type Count struct {
+	Name  string
+	Count int
+}
+
+// OrganizationHealth - Details of an organization, along with its users, and counts and most recent of its tickets
+type OrganizationHealth struct {
+	Organization  organizations.OrganizationEntity
+	Users         []users.UserEntity
+	Tickets       int
+	Statuses      []Count
+	Priorities    []Count
+	RecentTickets []tickets.TicketEntity
+}
+
+// OrganizationReport - Health of organizations, as of when the report was generated
+type OrganizationReport struct {
+	GeneratedAt   internal.Timestamp
+	Organizations []OrganizationHealth
+}
+
+// OrganizationPage - Health of an organization, as of when the report was generated, rendered as a page
+type OrganizationPage struct {
+	GeneratedAt internal.Timestamp
+	OrganizationHealth
+}
+
+/*
+*		Trigger organization health report, of all organizations or of --organization. The page of --organization is
+*		written to --html, and the page of each of all organizations next to it, named by its _id (eg. out-101.html).
+*		Pages are all rendered before any is written, so that nothing is written if any fails
+*
+*	    @return (error): If any error occurs during validation of flags, loading of data or writing of the pages
+ */
+func triggerOrganizationReport(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("html")
+	id, _ := cmd.Flags().GetInt("organization")
+	recent, _ := cmd.Flags().GetInt("recent")
+	if recent < 0 {
+		return cmd.FlagErrorFunc()(cmd, fmt.Errorf("invalid --recent %v, expected 0 or more", recent))
+	}
+	allOrganizations, err := loadAll(cmd.Context(), zsearch.Organizations)
+	if err != nil {
+		return err
+	}
+	if id != 0 {
+		allOrganizations = findOrganization(allOrganizations, id)
+		if len(allOrganizations) == 0 {
+			return fmt.Errorf("organization %v %w", id, zsearch.ErrNotFound)
+		}
+	}
+	allTickets, err := loadTickets(cmd.Context())
+	if err != nil {
+		return err
+	}
+	allUsers, err := loadUsers(cmd.Context())
+	if err != nil {
+		return err
+	}
+	report := buildOrganizationReport(allOrganizations, allUsers, allTickets, recent)
+
+	pages := make([][]byte, len(report.Organizations))
+	for i, health := range report.Organizations {
+		buffer := new(bytes.Buffer)
+		if err := renderOrganizationPage(buffer, OrganizationPage{GeneratedAt: report.GeneratedAt, OrganizationHealth: health}); err != nil {
+			return err
+		}
+		pages[i] = buffer.Bytes()
+	}
+	for i, health := range report.Organizations {
+		pagePath := path
+		if id == 0 {
+			pagePath = organizationPagePath(path, health.Organization.Id)
+		}
+		if err := internal.WriteFile(pagePath, pages[i]); err != nil {
+			return err
+		}
+		cmd.Printf("Organization report of %v written to %v\n", health.Organization.Name, pagePath)
+	}
+	log.Infof("Organization report written")
+	return nil
+}
+
+// Path of the page of an organization, named after the path given by its _id, eg. out-101.html for out.html
+func organizationPagePath(path string, id int) string {
+	extension := filepath.Ext(path)
+	return fmt.Sprintf("%v-%v%v", strings.TrimSuffix(path, extension), id, extension)
+}
+
+// Organization with an ID, as a list of at most one organization
+func findOrganization(allOrganizations organizations.Organization, id int) organizations.Organization {
+	for _, organization := range allOrganizations {
+		if organization.Id == id {
+			return organizations.Organization{organization}
+		}
+	}
+	return nil
+}
+
+/*
+*		Build the health of every organization, from the users and tickets belonging to it. Recent tickets are the
+*		most recently created tickets, up to the number given
+ */
+func buildOrganizationReport(allOrganizations organizations.Organization, allUsers users.User, allTickets tickets.Ticket, recent int) OrganizationReport {
+	report := OrganizationReport{GeneratedAt: internal.Timestamp{Time: now()}}
+	usersByOrganization := map[int][]users.UserEntity{}
+	for _, user := range allUsers {
+		usersByOrganization[user.OrganizationId] = append(usersByOrganization[user.OrganizationId], user)
+	}
+	ticketsByOrganization := map[int][]tickets.TicketEntity{}
+	for _, ticket := range allTickets {
+		ticketsByOrganization[ticket.OrganizationId] = append(ticketsByOrganization[ticket.OrganizationId], ticket)
+	}
+	for _, organization := range allOrganizations {
+		organizationTickets := ticketsByOrganization[organization.Id]
+		byStatus := map[string]int{}
+		byPriority := map[string]int{}
+		for _, ticket := range organizationTickets {
+			byStatus[ticket.Status]++
+			byPriority[ticket.Priority]++
+		}
+		recentTickets := append([]tickets.TicketEntity{}, organizationTickets...)
+		sort.SliceStable(recentTickets, func(i, j int) bool {
+			return recentTickets[i].CreatedAt.After(recentTickets[j].CreatedAt.Time)
+		})
+		if len(recentTickets) > recent {
+			recentTickets = recentTickets[:recent]
+		}
+		report.Organizations = append(report.Organizations, OrganizationHealth{
+			Organization:  organization,
+			Users:         usersByOrganization[organization.Id],
+			Tickets:       len(organizationTickets),
+			Statuses:      sortedCounts(byStatus, statuses),
+			Priorities:    sortedCounts(byPriority, priorities),
+			RecentTickets: recentTickets,
+		})
+	}
+	return report
+}
+
+// Counts in the order of the known values (eg. statuses or priorities)
+func sortedCounts(counts map[string]int, known []string) []Count {
+	var sorted []Count
+	for name, count := range counts {
+		sorted = append(sorted, Count{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return knownOrder(known, sorted[i].Name, sorted[j].Name)
+	})
+	return sorted
+}
+
+/*
+*		Render the health of an organization as an HTML page
+ */
+func renderOrganizationPage(out io.Writer, page OrganizationPage) error {
+	organizationPage, err := template.New("organization").Parse(organizationTemplate)
+	if err != nil {
+		return err
+	}
+	return organizationPage.Execute(out, page)
+}
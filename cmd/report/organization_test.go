@@ -0,0 +1,124 @@
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/organizations"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildOrganizationReport(t *testing.T) {
+	created := func(day int) internal.Timestamp {
+		return internal.Timestamp{Time: time.Date(2016, 5, day, 0, 0, 0, 0, time.UTC)}
+	}
+	allOrganizations := organizations.Organization{{Id: 1, Name: "Acme"}, {Id: 2, Name: "Initech"}}
+	allUsers := users.User{{Id: 1, Name: "Ann", OrganizationId: 1}, {Id: 2, Name: "Bob", OrganizationId: 3}}
+	allTickets := tickets.Ticket{
+		{Id: "a", OrganizationId: 1, Status: "solved", Priority: "low", CreatedAt: created(1)},
+		{Id: "b", OrganizationId: 1, Status: "open", Priority: "low", CreatedAt: created(3)},
+		{Id: "c", OrganizationId: 1, Status: "open", Priority: "urgent", CreatedAt: created(2)},
+		{Id: "d", OrganizationId: 3, Status: "open", Priority: "urgent", CreatedAt: created(2)},
+	}
+	report := buildOrganizationReport(allOrganizations, allUsers, allTickets, 2)
+
+	t.Run("test users and tickets are counted for each organization", func(t *testing.T) {
+		assert.Equal(t, 2, len(report.Organizations))
+		acme := report.Organizations[0]
+		assert.Equal(t, 3, acme.Tickets)
+		assert.Equal(t, []Count{{Name: "open", Count: 2}, {Name: "solved", Count: 1}}, acme.Statuses)
+		assert.Equal(t, []Count{{Name: "urgent", Count: 1}, {Name: "low", Count: 2}}, acme.Priorities)
+		assert.Equal(t, 1, len(acme.Users))
+		assert.Equal(t, "b", acme.RecentTickets[0].Id, "most recent tickets first")
+		assert.Equal(t, 2, len(acme.RecentTickets), "only the number of recent tickets given")
+
+		initech := report.Organizations[1]
+		assert.Equal(t, 0, initech.Tickets)
+		assert.Nil(t, initech.Users)
+	})
+	t.Run("test page is rendered as HTML, escaping values of the data", func(t *testing.T) {
+		report.Organizations[0].Organization.Details = "<script>alert(1)</script>"
+		buffer := new(bytes.Buffer)
+		err := renderOrganizationPage(buffer, OrganizationPage{GeneratedAt: report.GeneratedAt, OrganizationHealth: report.Organizations[0]})
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(buffer.String(), "<!DOCTYPE html>"))
+		assert.True(t, strings.Contains(buffer.String(), "<h2>Acme</h2>"))
+		assert.False(t, strings.Contains(buffer.String(), "Initech"), "only the organization of the page")
+		assert.True(t, strings.Contains(buffer.String(), "&lt;script&gt;alert(1)&lt;/script&gt;"))
+		assert.False(t, strings.Contains(buffer.String(), "<link"), "no external assets")
+	})
+}
+
+func Test_ExecuteOrganizationReportCommand(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+	t.Run("Execute organization report command and assert the page written", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.html")
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewOrganizationCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"--html", path, "--organization", "114"})
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, "Organization report of Isotronic written to "+path+"\n", buffer.String())
+
+		page, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(string(page), "<h2>Isotronic</h2>"))
+		assert.True(t, strings.Contains(string(page), "Valentine Ashley"), "Users of the organization are shown")
+	})
+	t.Run("Execute organization report command and assert a page written for each organization", func(t *testing.T) {
+		dir := t.TempDir()
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewOrganizationCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"--html", filepath.Join(dir, "out.html")})
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(buffer.String(), "Organization report of Isotronic written to "+filepath.Join(dir, "out-114.html")+"\n"))
+
+		page, err := os.ReadFile(filepath.Join(dir, "out-114.html"))
+		assert.Nil(t, err)
+		assert.True(t, strings.Contains(string(page), "<h2>Isotronic</h2>"))
+		_, err = os.Stat(filepath.Join(dir, "out.html"))
+		assert.True(t, os.IsNotExist(err), "no page of all organizations")
+	})
+	t.Run("Execute organization report command for an unknown organization", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.html")
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewOrganizationCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"--html", path, "--organization", "1"})
+		err := cmd.Execute()
+		assert.NotNil(t, err)
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err), "nothing is written")
+	})
+	t.Run("Execute organization report command with a negative number of recent tickets", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.html")
+		cmd := NewOrganizationCmd()
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs([]string{"--html", path, "--organization", "114", "--recent", "-1"})
+		err := cmd.Execute()
+		assert.Equal(t, "invalid --recent -1, expected 0 or more", err.Error())
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err), "nothing is written")
+	})
+	t.Run("Execute organization report command without a file", func(t *testing.T) {
+		cmd := NewOrganizationCmd()
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs([]string{})
+		err := cmd.Execute()
+		assert.Equal(t, "required flag(s) \"html\" not set", err.Error())
+	})
+}
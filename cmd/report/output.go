@@ -0,0 +1,40 @@
+// Package report -
+//
+// This file is meant for writing reports in any of the output formats (see internal.OutputFormats): aligned text
+// tables for reading in a terminal, JSON and CSV for other tools
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"strings"
+	"text/tabwriter"
+)
+
+/*
+*		Write a report in an output format. Text and CSV are written as a table of the header and rows, and JSON is
+*		written from the values the rows were made from, so that it keeps their types
+ */
+func writeReport(cmd *cobra.Command, format string, header []string, rows [][]string, values any) error {
+	switch format {
+	case internal.OutputJSON:
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(values)
+	case internal.OutputCSV:
+		writer := csv.NewWriter(cmd.OutOrStdout())
+		_ = writer.Write(header)
+		_ = writer.WriteAll(rows)
+		return writer.Error()
+	default:
+		out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(out, strings.Join(header, "\t"))
+		for _, row := range rows {
+			_, _ = fmt.Fprintln(out, strings.Join(row, "\t"))
+		}
+		return out.Flush()
+	}
+}
@@ -0,0 +1,109 @@
+// Package report -
+//
+// This is the entry point of reports, which summarise entities of the models rather than searching for them. The
+// source of data is read from real JSON files for users and from testdata files for tests
+//
+
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/pkg/zsearch"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DateLayout - Layout of dates given without a time
+const DateLayout = "2006-01-02"
+
+// Statuses of tickets which need no more work, and are left out of reports on work to do
+var resolvedStatuses = map[string]bool{"solved": true, "closed": true}
+
+// Known priorities of tickets, most urgent first. Other priorities are reported after these
+var priorities = []string{"urgent", "high", "normal", "low"}
+
+// Known statuses of tickets, in the order tickets usually go through them. Other statuses are reported after these
+var statuses = []string{"open", "pending", "hold", "solved", "closed"}
+
+// now - Current time, replaced in tests
+var now = time.Now
+
+/*
+*		Load all entities of a type, along with their related entities (eg. names of submitters, assignees and
+*		organizations of tickets)
+*
+*	    @return ([]T, error): All entities, and error if reading or parsing their data failed
+ */
+func loadAll[T any](ctx context.Context, entity zsearch.EntityType[T]) ([]T, error) {
+	dataset, err := zsearch.Load(ctx, entity.Name())
+	if err != nil {
+		return nil, err
+	}
+	return zsearch.Search(ctx, dataset, zsearch.NewQuery(entity))
+}
+
+/*
+*		Load all tickets, along with names of their submitters, assignees and organizations
+*
+*	    @return (tickets.Ticket, error): All tickets, and error if reading or parsing tickets failed
+ */
+func loadTickets(ctx context.Context) (tickets.Ticket, error) {
+	return loadAll(ctx, zsearch.Tickets)
+}
+
+/*
+*		Parse the time a report is as of, a date or a full timestamp, defaulting to the current time if not given
+ */
+func parseNow(value string) (time.Time, error) {
+	if value == "" {
+		return now(), nil
+	}
+	for _, layout := range []string{internal.TimestampLayout, time.RFC3339, DateLayout} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New(fmt.Sprintf("Please specify a date (2016-08-01) or timestamp (2016-08-01T09:00:00 +10:00) for --now, not %v\n", value))
+}
+
+// Order of a value (eg. a priority or status) in reports, by its position in the known values, then by the value
+func knownOrder(known []string, a, b string) bool {
+	orderOf := func(value string) int {
+		for i, k := range known {
+			if k == value {
+				return i
+			}
+		}
+		return len(known)
+	}
+	if orderOf(a) != orderOf(b) {
+		return orderOf(a) < orderOf(b)
+	}
+	return a < b
+}
+
+// isOverdue - Whether a ticket is past its due date as of now, and is not yet solved or closed
+func isOverdue(ticket tickets.TicketEntity, now time.Time) bool {
+	return !resolvedStatuses[ticket.Status] && !ticket.DueAt.IsZero() && ticket.DueAt.Before(now)
+}
+
+// Display a duration in days and hours (eg. 3d 4h, -12d 0h), rounded to the hour
+func formatDuration(duration time.Duration) string {
+	sign := ""
+	if duration < 0 {
+		sign, duration = "-", -duration
+	}
+	hours := int(duration.Round(time.Hour).Hours())
+	return fmt.Sprintf("%v%dd %dh", sign, hours/24, hours%24)
+}
+
+// Display a name, or a placeholder if it is empty (eg. for unassigned tickets)
+func displayName(name, placeholder string) string {
+	if name == "" {
+		return placeholder
+	}
+	return name
+}
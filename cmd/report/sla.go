@@ -0,0 +1,213 @@
+// Package report -
+//
+// This file is meant for the SLA report of tickets: tickets which are overdue (due in the past, and not yet solved or
+// closed), tickets due within a window of time, and time to due of tickets by priority, assignee and organization
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/tickets"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// SLATicket - Unresolved ticket with a due date, and the time left until it is due (negative once overdue)
+type SLATicket struct {
+	Id           string
+	Subject      string
+	Priority     string
+	Status       string
+	Assignee     string
+	Organization string
+	DueAt        internal.Timestamp
+	TimeToDue    time.Duration
+}
+
+// SLASummary - Counts of unresolved tickets with a due date, for a priority, assignee or organization
+type SLASummary struct {
+	Name             string
+	Unresolved       int
+	Overdue          int
+	DueSoon          int
+	AverageTimeToDue time.Duration
+	NearestTimeToDue time.Duration
+	total            time.Duration
+}
+
+// SLAReport - Overdue tickets and tickets due soon, as of a time, summarised by priority, assignee and organization
+type SLAReport struct {
+	Now           time.Time
+	Window        time.Duration
+	Overdue       []SLATicket
+	DueSoon       []SLATicket
+	Priorities    []SLASummary
+	Assignees     []SLASummary
+	Organizations []SLASummary
+}
+
+/*
+*		Trigger SLA report of all tickets, as of now (or --now) and for tickets due --within a window from then
+*
+*	    @return (error): If any error occurs during validation of flags, or loading of tickets
+ */
+func triggerSLAReport(cmd *cobra.Command) error {
+	within, _ := cmd.Flags().GetString("within")
+	window, err := internal.ParseDuration(within)
+	if err != nil {
+		return err
+	}
+	at, _ := cmd.Flags().GetString("now")
+	reportNow, err := parseNow(at)
+	if err != nil {
+		return err
+	}
+	allTickets, err := loadTickets(cmd.Context())
+	if err != nil {
+		return err
+	}
+	displaySLAReport(cmd, buildSLAReport(allTickets, reportNow, window))
+	log.Infof("SLA report displayed")
+	return nil
+}
+
+/*
+*		Build the SLA report of tickets as of now. Solved and closed tickets, and tickets without a due date, are left
+*		out. Tickets are due soon if they are due from now until the end of the window
+ */
+func buildSLAReport(allTickets tickets.Ticket, now time.Time, window time.Duration) SLAReport {
+	report := SLAReport{Now: now, Window: window}
+	byPriority := map[string]*SLASummary{}
+	byAssignee := map[string]*SLASummary{}
+	byOrganization := map[string]*SLASummary{}
+	for _, ticket := range allTickets {
+		if resolvedStatuses[ticket.Status] || ticket.DueAt.IsZero() {
+			continue
+		}
+		slaTicket := SLATicket{
+			Id:           ticket.Id,
+			Subject:      ticket.Subject,
+			Priority:     ticket.Priority,
+			Status:       ticket.Status,
+			Assignee:     displayName(ticket.AssigneeName, "(unassigned)"),
+			Organization: displayName(ticket.OrganizationName, "(no organization)"),
+			DueAt:        ticket.DueAt,
+			TimeToDue:    ticket.DueAt.Sub(now),
+		}
+		overdue := isOverdue(ticket, now)
+		dueSoon := !overdue && slaTicket.TimeToDue <= window
+		if overdue {
+			report.Overdue = append(report.Overdue, slaTicket)
+		} else if dueSoon {
+			report.DueSoon = append(report.DueSoon, slaTicket)
+		}
+		addToSummary(byPriority, slaTicket.Priority, slaTicket.TimeToDue, overdue, dueSoon)
+		addToSummary(byAssignee, slaTicket.Assignee, slaTicket.TimeToDue, overdue, dueSoon)
+		addToSummary(byOrganization, slaTicket.Organization, slaTicket.TimeToDue, overdue, dueSoon)
+	}
+	sortByTimeToDue(report.Overdue)
+	sortByTimeToDue(report.DueSoon)
+	report.Priorities = sortedSummaries(byPriority, func(a, b SLASummary) bool {
+		return knownOrder(priorities, a.Name, b.Name)
+	})
+	mostOverdue := func(a, b SLASummary) bool {
+		if a.Overdue != b.Overdue {
+			return a.Overdue > b.Overdue
+		}
+		if a.DueSoon != b.DueSoon {
+			return a.DueSoon > b.DueSoon
+		}
+		return a.Name < b.Name
+	}
+	report.Assignees = sortedSummaries(byAssignee, mostOverdue)
+	report.Organizations = sortedSummaries(byOrganization, mostOverdue)
+	return report
+}
+
+// Add a ticket to the summary of its priority, assignee or organization
+func addToSummary(summaries map[string]*SLASummary, name string, timeToDue time.Duration, overdue, dueSoon bool) {
+	summary, ok := summaries[name]
+	if !ok {
+		summary = &SLASummary{Name: name, NearestTimeToDue: timeToDue}
+		summaries[name] = summary
+	}
+	summary.Unresolved++
+	summary.total += timeToDue
+	summary.AverageTimeToDue = summary.total / time.Duration(summary.Unresolved)
+	if timeToDue < summary.NearestTimeToDue {
+		summary.NearestTimeToDue = timeToDue
+	}
+	if overdue {
+		summary.Overdue++
+	}
+	if dueSoon {
+		summary.DueSoon++
+	}
+}
+
+// Sort tickets by time to due, most overdue first
+func sortByTimeToDue(slaTickets []SLATicket) {
+	sort.SliceStable(slaTickets, func(i, j int) bool {
+		return slaTickets[i].TimeToDue < slaTickets[j].TimeToDue
+	})
+}
+
+// List of summaries, sorted by less
+func sortedSummaries(summaries map[string]*SLASummary, less func(a, b SLASummary) bool) []SLASummary {
+	var sorted []SLASummary
+	for _, summary := range summaries {
+		sorted = append(sorted, *summary)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+/*
+*		Display the SLA report to the user, as tables of overdue tickets, tickets due soon, and summaries by priority,
+*		assignee and organization
+ */
+func displaySLAReport(cmd *cobra.Command, report SLAReport) {
+	out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(out, "======== SLA report ========\n")
+	_, _ = fmt.Fprintf(out, "As of: %v\n", internal.Timestamp{Time: report.Now})
+	_, _ = fmt.Fprintf(out, "Due soon: within %v\n", formatDuration(report.Window))
+	for _, section := range []struct {
+		title      string
+		slaTickets []SLATicket
+	}{
+		{title: "Overdue tickets", slaTickets: report.Overdue},
+		{title: "Tickets due soon", slaTickets: report.DueSoon},
+	} {
+		_, _ = fmt.Fprintf(out, "\n-------- %v (%v) --------\n", section.title, len(section.slaTickets))
+		if len(section.slaTickets) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "_id\tpriority\tstatus\tdue_at\ttime_to_due\tassignee\torganization\tsubject\n")
+		for _, ticket := range section.slaTickets {
+			_, _ = fmt.Fprintf(out, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", ticket.Id, ticket.Priority, ticket.Status, ticket.DueAt,
+				formatDuration(ticket.TimeToDue), ticket.Assignee, ticket.Organization, ticket.Subject)
+		}
+	}
+	for _, section := range []struct {
+		title     string
+		column    string
+		summaries []SLASummary
+	}{
+		{title: "By priority", column: "priority", summaries: report.Priorities},
+		{title: "By assignee", column: "assignee", summaries: report.Assignees},
+		{title: "By organization", column: "organization", summaries: report.Organizations},
+	} {
+		_, _ = fmt.Fprintf(out, "\n-------- %v --------\n", section.title)
+		_, _ = fmt.Fprintf(out, "%v\tunresolved\toverdue\tdue_soon\taverage_time_to_due\tnearest_time_to_due\n", section.column)
+		for _, summary := range section.summaries {
+			_, _ = fmt.Fprintf(out, "%v\t%v\t%v\t%v\t%v\t%v\n", summary.Name, summary.Unresolved, summary.Overdue, summary.DueSoon,
+				formatDuration(summary.AverageTimeToDue), formatDuration(summary.NearestTimeToDue))
+		}
+	}
+	_ = out.Flush()
+}
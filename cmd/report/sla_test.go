@@ -0,0 +1,98 @@
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/tickets"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Directory of the test data shared by all packages, relative to the directory of a package
+var testDataDir = filepath.Join("..", "..", "testdata")
+
+func TestBuildSLAReport(t *testing.T) {
+	reportNow := time.Date(2016, 8, 1, 0, 0, 0, 0, time.UTC)
+	due := func(hours int) internal.Timestamp {
+		return internal.Timestamp{Time: reportNow.Add(time.Duration(hours) * time.Hour)}
+	}
+	allTickets := tickets.Ticket{
+		{Id: "overdue", Status: "open", Priority: "low", AssigneeName: "Ann", OrganizationName: "Acme", DueAt: due(-48)},
+		{Id: "most overdue", Status: "hold", Priority: "urgent", AssigneeName: "Ann", DueAt: due(-72)},
+		{Id: "due soon", Status: "pending", Priority: "urgent", AssigneeName: "Bob", OrganizationName: "Acme", DueAt: due(12)},
+		{Id: "due later", Status: "open", Priority: "urgent", OrganizationName: "Acme", DueAt: due(24 * 10)},
+		{Id: "solved", Status: "solved", Priority: "high", AssigneeName: "Bob", DueAt: due(-48)},
+		{Id: "closed", Status: "closed", Priority: "high", AssigneeName: "Bob", DueAt: due(-48)},
+		{Id: "no due date", Status: "open", Priority: "high", AssigneeName: "Bob"},
+	}
+	report := buildSLAReport(allTickets, reportNow, 7*24*time.Hour)
+
+	t.Run("test overdue tickets leave out solved and closed tickets, most overdue first", func(t *testing.T) {
+		assert.Equal(t, 2, len(report.Overdue))
+		assert.Equal(t, "most overdue", report.Overdue[0].Id)
+		assert.Equal(t, -72*time.Hour, report.Overdue[0].TimeToDue)
+		assert.Equal(t, "(no organization)", report.Overdue[0].Organization)
+		assert.Equal(t, "overdue", report.Overdue[1].Id)
+	})
+	t.Run("test tickets due soon are due within the window", func(t *testing.T) {
+		assert.Equal(t, 1, len(report.DueSoon))
+		assert.Equal(t, "due soon", report.DueSoon[0].Id)
+	})
+	t.Run("test time to due by priority, most urgent first", func(t *testing.T) {
+		assert.Equal(t, []SLASummary{
+			{Name: "urgent", Unresolved: 3, Overdue: 1, DueSoon: 1, AverageTimeToDue: 60 * time.Hour, NearestTimeToDue: -72 * time.Hour, total: 180 * time.Hour},
+			{Name: "low", Unresolved: 1, Overdue: 1, AverageTimeToDue: -48 * time.Hour, NearestTimeToDue: -48 * time.Hour, total: -48 * time.Hour},
+		}, report.Priorities)
+	})
+	t.Run("test tickets by assignee and organization, most overdue first", func(t *testing.T) {
+		var assignees, organizations []string
+		for _, summary := range report.Assignees {
+			assignees = append(assignees, summary.Name)
+		}
+		for _, summary := range report.Organizations {
+			organizations = append(organizations, summary.Name)
+		}
+		assert.Equal(t, []string{"Ann", "Bob", "(unassigned)"}, assignees)
+		assert.Equal(t, []string{"Acme", "(no organization)"}, organizations)
+		assert.Equal(t, 2, report.Assignees[0].Overdue)
+		assert.Equal(t, 3, report.Organizations[0].Unresolved)
+	})
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "3d 4h", formatDuration(76*time.Hour))
+	assert.Equal(t, "-0d 5h", formatDuration(-5*time.Hour-10*time.Minute))
+	assert.Equal(t, "0d 0h", formatDuration(0))
+}
+
+func Test_ExecuteSLAReportCommand(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+	t.Run("Execute SLA report command and assert output", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewSLACmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"--now", "2016-08-20", "--within", "3d"})
+		err := cmd.Execute()
+		assert.Nil(t, err)
+
+		assert.True(t, strings.HasPrefix(buffer.String(), "======== SLA report ========\n"))
+		assert.True(t, strings.Contains(buffer.String(), "-------- Overdue tickets (0) --------"))
+		assert.True(t, strings.Contains(buffer.String(), "-------- Tickets due soon (2) --------"))
+		assert.True(t, strings.Contains(buffer.String(), "Catalina Simpson"), "Assignee names are resolved")
+		assert.True(t, strings.Contains(buffer.String(), "Geekfarm"), "Organization names are resolved")
+		assert.False(t, strings.Contains(buffer.String(), "3ff0599a-fe0f-4f8f-ac31-e2636843bcea"), "Closed tickets are left out")
+	})
+	t.Run("Execute SLA report command with an invalid window", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewSLACmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"--within", "a fortnight"})
+		err := cmd.Execute()
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,260 @@
+// Package report -
+//
+// This file is meant for the workload report of agents: counts of tickets assigned to each assignee by status and
+// priority, along with their incidents and overdue tickets, and the name, role and organization of the assignee
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+	"ZendeskChallenge/pkg/zsearch"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Columns of the workload report which are not counts of tickets by status or priority
+const (
+	ColumnAssigneeId   = "assignee_id"
+	ColumnName         = "name"
+	ColumnRole         = "role"
+	ColumnOrganization = "organization_name"
+	ColumnTotal        = "total"
+	ColumnIncidents    = "incidents"
+	ColumnOverdue      = "overdue"
+)
+
+// Prefixes of the columns of counts of tickets by status and by priority, eg. status_open, priority_high
+const (
+	ColumnStatusPrefix   = "status_"
+	ColumnPriorityPrefix = "priority_"
+)
+
+// Workload - Tickets assigned to an assignee, counted by status and priority
+type Workload struct {
+	AssigneeId   int            `json:"assignee_id"`
+	Name         string         `json:"name"`
+	Role         string         `json:"role"`
+	Organization string         `json:"organization_name"`
+	Total        int            `json:"total"`
+	Statuses     map[string]int `json:"statuses"`
+	Priorities   map[string]int `json:"priorities"`
+	Incidents    int            `json:"incidents"`
+	Overdue      int            `json:"overdue"`
+}
+
+// Value of a column of the workload, and whether it is a count (rather than text). Unknown columns are empty
+func (w Workload) column(name string) (string, bool) {
+	switch name {
+	case ColumnAssigneeId:
+		return strconv.Itoa(w.AssigneeId), true
+	case ColumnName:
+		return w.Name, false
+	case ColumnRole:
+		return w.Role, false
+	case ColumnOrganization:
+		return w.Organization, false
+	case ColumnTotal:
+		return strconv.Itoa(w.Total), true
+	case ColumnIncidents:
+		return strconv.Itoa(w.Incidents), true
+	case ColumnOverdue:
+		return strconv.Itoa(w.Overdue), true
+	}
+	if status, ok := strings.CutPrefix(name, ColumnStatusPrefix); ok {
+		return strconv.Itoa(w.Statuses[status]), true
+	}
+	if priority, ok := strings.CutPrefix(name, ColumnPriorityPrefix); ok {
+		return strconv.Itoa(w.Priorities[priority]), true
+	}
+	return "", false
+}
+
+/*
+*		Trigger workload report of all assignees, sorted by --sort and written in --output format (or the output of the
+*		profile of the user)
+*
+*	    @return (error): If any error occurs during validation of flags, or loading of tickets and users
+ */
+func triggerWorkloadReport(cmd *cobra.Command) error {
+	options, err := internal.ResolveDisplayOptions(cmd, "", nil, internal.OutputFormats)
+	if err != nil {
+		return cmd.FlagErrorFunc()(cmd, err)
+	}
+	at, _ := cmd.Flags().GetString("now")
+	reportNow, err := parseNow(at)
+	if err != nil {
+		return err
+	}
+	allTickets, err := loadTickets(cmd.Context())
+	if err != nil {
+		return err
+	}
+	allUsers, err := loadUsers(cmd.Context())
+	if err != nil {
+		return err
+	}
+	workloads := buildWorkloads(allTickets, allUsers, reportNow)
+	columns := workloadColumns(workloads)
+
+	sortBy, _ := cmd.Flags().GetString("sort")
+	validate := validator.New()
+	err = validate.Var(sortBy, "required,oneof="+strings.Join(columns, " "))
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Please specify one of %v for --sort, not %v\n", strings.Join(columns, ", "), sortBy))
+		return err
+	}
+	reverse, _ := cmd.Flags().GetBool("reverse")
+	sortWorkloads(workloads, sortBy, reverse)
+
+	var rows [][]string
+	for _, workload := range workloads {
+		var row []string
+		for _, column := range columns {
+			value, _ := workload.column(column)
+			row = append(row, value)
+		}
+		rows = append(rows, row)
+	}
+	err = writeReport(cmd, options.Output, columns, rows, workloads)
+	if err != nil {
+		return err
+	}
+	log.Infof("Workload report displayed")
+	return nil
+}
+
+/*
+*		Load all users, along with names of their organizations
+*
+*	    @return (users.User, error): All users, and error if reading or parsing users failed
+ */
+func loadUsers(ctx context.Context) (users.User, error) {
+	return loadAll(ctx, zsearch.Users)
+}
+
+/*
+*		Build the workload of every assignee of tickets, as of now (for overdue tickets). Unassigned tickets are left
+*		out, and assignees which are not users are still reported, without their details
+ */
+func buildWorkloads(allTickets tickets.Ticket, allUsers users.User, now time.Time) []Workload {
+	usersById := map[int]users.UserEntity{}
+	for _, user := range allUsers {
+		usersById[user.Id] = user
+	}
+	byAssignee := map[int]*Workload{}
+	var order []int
+	for _, ticket := range allTickets {
+		if ticket.AssigneeId == 0 {
+			continue
+		}
+		workload, ok := byAssignee[ticket.AssigneeId]
+		if !ok {
+			user := usersById[ticket.AssigneeId]
+			workload = &Workload{
+				AssigneeId:   ticket.AssigneeId,
+				Name:         displayName(user.Name, "(unknown)"),
+				Role:         user.Role,
+				Organization: user.OrganizationName,
+				Statuses:     map[string]int{},
+				Priorities:   map[string]int{},
+			}
+			byAssignee[ticket.AssigneeId] = workload
+			order = append(order, ticket.AssigneeId)
+		}
+		workload.Total++
+		workload.Statuses[ticket.Status]++
+		workload.Priorities[ticket.Priority]++
+		if ticket.HasIncidents {
+			workload.Incidents++
+		}
+		if isOverdue(ticket, now) {
+			workload.Overdue++
+		}
+	}
+	var workloads []Workload
+	for _, id := range order {
+		workloads = append(workloads, *byAssignee[id])
+	}
+	for _, workload := range workloads { // Every workload has a count of every status and priority, even if it is zero
+		for _, other := range workloads {
+			fillCounts(other.Statuses, workload.Statuses)
+			fillCounts(other.Priorities, workload.Priorities)
+		}
+	}
+	return workloads
+}
+
+// Add a zero count to counts for every key it does not have
+func fillCounts(keys map[string]int, counts map[string]int) {
+	for key := range keys {
+		if _, ok := counts[key]; !ok {
+			counts[key] = 0
+		}
+	}
+}
+
+/*
+*		Columns of the workload report: details of the assignee, then counts of tickets by every status and priority
+*		found, in their usual order and prefixed by status_ and priority_, so that they never clash with each other
+*		or with the other columns
+ */
+func workloadColumns(workloads []Workload) []string {
+	columns := []string{ColumnAssigneeId, ColumnName, ColumnRole, ColumnOrganization, ColumnTotal}
+	var statusColumns, priorityColumns []string
+	if len(workloads) > 0 {
+		for status := range workloads[0].Statuses {
+			statusColumns = append(statusColumns, status)
+		}
+		for priority := range workloads[0].Priorities {
+			priorityColumns = append(priorityColumns, priority)
+		}
+	}
+	sort.Slice(statusColumns, func(i, j int) bool {
+		return knownOrder(statuses, statusColumns[i], statusColumns[j])
+	})
+	sort.Slice(priorityColumns, func(i, j int) bool {
+		return knownOrder(priorities, priorityColumns[i], priorityColumns[j])
+	})
+	for _, status := range statusColumns {
+		columns = append(columns, ColumnStatusPrefix+status)
+	}
+	for _, priority := range priorityColumns {
+		columns = append(columns, ColumnPriorityPrefix+priority)
+	}
+	return append(columns, ColumnIncidents, ColumnOverdue)
+}
+
+/*
+*		Sort workloads by a column: counts from most to least tickets, and text alphabetically. Reverse sorts the other
+*		way around. Workloads with the same value are sorted by name
+ */
+func sortWorkloads(workloads []Workload, column string, reverse bool) {
+	sort.SliceStable(workloads, func(i, j int) bool {
+		a, isCount := workloads[i].column(column)
+		b, _ := workloads[j].column(column)
+		if a == b {
+			return workloads[i].Name < workloads[j].Name
+		}
+		var less bool
+		if isCount {
+			countA, _ := strconv.Atoi(a)
+			countB, _ := strconv.Atoi(b)
+			less = countA > countB
+		} else {
+			less = a < b
+		}
+		if reverse {
+			return !less
+		}
+		return less
+	})
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildWorkloads(t *testing.T) {
+	reportNow := time.Date(2016, 8, 1, 0, 0, 0, 0, time.UTC)
+	past := internal.Timestamp{Time: reportNow.Add(-time.Hour)}
+	allUsers := users.User{
+		{Id: 1, Name: "Ann", Role: "agent", OrganizationName: "Acme"},
+		{Id: 2, Name: "Bob", Role: "admin"},
+	}
+	allTickets := tickets.Ticket{
+		{Id: "a", AssigneeId: 1, Status: "open", Priority: "high", HasIncidents: true, DueAt: past},
+		{Id: "b", AssigneeId: 1, Status: "solved", Priority: "low", DueAt: past},
+		{Id: "c", AssigneeId: 2, Status: "pending", Priority: "urgent"},
+		{Id: "d", AssigneeId: 3, Status: "open", Priority: "high"},
+		{Id: "e", Status: "open", Priority: "high"},
+	}
+	workloads := buildWorkloads(allTickets, allUsers, reportNow)
+
+	t.Run("test tickets are counted for each assignee, leaving out unassigned tickets", func(t *testing.T) {
+		assert.Equal(t, 3, len(workloads))
+		assert.Equal(t, Workload{
+			AssigneeId:   1,
+			Name:         "Ann",
+			Role:         "agent",
+			Organization: "Acme",
+			Total:        2,
+			Statuses:     map[string]int{"open": 1, "pending": 0, "solved": 1},
+			Priorities:   map[string]int{"high": 1, "low": 1, "urgent": 0},
+			Incidents:    1,
+			Overdue:      1,
+		}, workloads[0])
+		assert.Equal(t, "(unknown)", workloads[2].Name, "assignees which are not users are still reported")
+	})
+	t.Run("test columns are in the usual order of statuses and priorities", func(t *testing.T) {
+		assert.Equal(t, []string{"assignee_id", "name", "role", "organization_name", "total", "status_open", "status_pending",
+			"status_solved", "priority_urgent", "priority_high", "priority_low", "incidents", "overdue"}, workloadColumns(workloads))
+	})
+	t.Run("test counts of a status and a priority of the same name are columns of their own", func(t *testing.T) {
+		workload := Workload{Statuses: map[string]int{"high": 2}, Priorities: map[string]int{"high": 1}}
+		status, isCount := workload.column("status_high")
+		assert.Equal(t, "2", status)
+		assert.True(t, isCount)
+		priority, _ := workload.column("priority_high")
+		assert.Equal(t, "1", priority)
+		unknown, isCount := workload.column("high")
+		assert.Equal(t, "", unknown)
+		assert.False(t, isCount)
+	})
+	t.Run("test workloads are sorted by counts from most to least, and by text alphabetically", func(t *testing.T) {
+		names := func() []string {
+			var names []string
+			for _, workload := range workloads {
+				names = append(names, workload.Name)
+			}
+			return names
+		}
+		sortWorkloads(workloads, ColumnTotal, false)
+		assert.Equal(t, []string{"Ann", "(unknown)", "Bob"}, names())
+		sortWorkloads(workloads, "priority_urgent", false)
+		assert.Equal(t, []string{"Bob", "(unknown)", "Ann"}, names())
+		sortWorkloads(workloads, ColumnName, true)
+		assert.Equal(t, []string{"Bob", "Ann", "(unknown)"}, names())
+	})
+}
+
+func Test_ExecuteWorkloadReportCommand(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+	t.Run("Execute workload report command and assert text output", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewWorkloadCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"--sort", "name"})
+		err := cmd.Execute()
+		assert.Nil(t, err)
+
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		assert.Equal(t, 4, len(lines))
+		assert.True(t, strings.HasPrefix(lines[0], "assignee_id  name"))
+		assert.True(t, strings.HasPrefix(lines[1], "41111113     (unknown)"))
+		assert.True(t, strings.HasPrefix(lines[2], "43           Catalina Simpson"))
+	})
+	t.Run("Execute workload report command and assert json output", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewWorkloadCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"--output", "json", "--now", "2016-08-20"})
+		err := cmd.Execute()
+		assert.Nil(t, err)
+
+		var workloads []Workload
+		assert.Nil(t, json.Unmarshal(buffer.Bytes(), &workloads))
+		assert.Equal(t, 3, len(workloads))
+		assert.Equal(t, 0, workloads[0].Overdue)
+	})
+	t.Run("Execute workload report command and assert csv output", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewWorkloadCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"--output", "csv"})
+		err := cmd.Execute()
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(buffer.String(), "assignee_id,name,role,organization_name,total,"))
+	})
+	t.Run("Execute workload report command and assert the output of the profile is used", func(t *testing.T) {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewWorkloadCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{})
+		err := cmd.ExecuteContext(internal.WithProfile(context.Background(), internal.Profile{Output: internal.OutputCSV}))
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(buffer.String(), "assignee_id,name,role,organization_name,total,"))
+	})
+	for _, args := range [][]string{{"--output", "xml"}, {"--sort", "bogus"}} {
+		t.Run("Execute workload report command with invalid "+args[0], func(t *testing.T) {
+			buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+			cmd := NewWorkloadCmd()
+			cmd.SetOut(buffer)
+			cmd.SetErr(buffer)
+			cmd.SetArgs(args)
+			err := cmd.Execute()
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Package search -
+//
+// This file is meant for completion of searches in a shell: --name by the searchable fields of an entity, and --value
+// by the distinct values of the field in the data, read from the data directory of the profile of the user
+//
+
+package search
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/pkg/zsearch"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// Completion of a flag, see cobra.Command#RegisterFlagCompletionFunc
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// Completion of --name of a search, by the searchable fields of the entity and the friendly names of its custom fields
+func completeFields(entity internal.Entity) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		fields := entity.SearchableFields()
+		if dataset, err := loadCompletionData(cmd, entity); err == nil {
+			fields = dataset.Fields(entity.EntityName())
+		}
+		return matchingPrefix(fields, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// Completion of --value of a search, by the distinct values of the field given by --name (eg. statuses of tickets)
+func completeValues(entity internal.Entity) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		field, _ := cmd.Flags().GetString("name")
+		if field == "" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		dataset, err := loadCompletionData(cmd, entity)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return matchingPrefix(dataset.Values(entity.EntityName(), field), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+/*
+*		Load data of an entity for completion. Hooks of the root command are not run with the flags of the completed
+*		command, so the profile it selects (by --profile) is applied here
+*
+*	    @return (*zsearch.Dataset, error): Data of the entity, and error if the config or data could not be loaded
+ */
+func loadCompletionData(cmd *cobra.Command, entity internal.Entity) (*zsearch.Dataset, error) {
+	profile, err := internal.LoadProfile(cmd)
+	if err != nil {
+		return nil, err
+	}
+	internal.SetDataDir(profile.DataDir)
+	return zsearch.Load(cmd.Context(), entity.EntityName())
+}
+
+// Completions starting with the text being completed
+func matchingPrefix(completions []string, toComplete string) []string {
+	var matching []string
+	for _, completion := range completions {
+		if strings.HasPrefix(completion, toComplete) {
+			matching = append(matching, completion)
+		}
+	}
+	return matching
+}
@@ -6,64 +6,55 @@
 package search
 
 import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"ZendeskChallenge/pkg/zsearch"
 	"github.com/spf13/cobra"
 	_ "time"
 )
 
-// NewSearchCmd - Parent command setup for all search commands (user, ticket, organization) /*
+// NewSearchCmd - Parent command setup for all search commands, one for each registered entity /*
 func NewSearchCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "search",
 		Short: "Zendesk Search",
 	}
-	cmd.AddCommand(NewUserSearchCmd())
-	cmd.AddCommand(NewOrgSearchCmd())
-	cmd.AddCommand(NewTicketSearchCmd())
-
-	return cmd
-}
-
-// NewUserSearchCmd - Define user search command /*
-func NewUserSearchCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "user",
-		Short: "trigger user search",
-		RunE:  triggerUserSearch,
-	}
-
-	cmd.PersistentFlags().String("name", "", "The name of the field to search for.")
-	cmd.PersistentFlags().String("value", "", "Name of the field to search for")
-	_ = cmd.MarkPersistentFlagRequired("name")
-	//_ = cmd.MarkPersistentFlagRequired("value")
-	return cmd
-}
-
-// NewTicketSearchCmd - Define ticket search command /*
-func NewTicketSearchCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "ticket",
-		Short: "trigger ticket search",
-		RunE:  triggerTicketSearch, // method to run when ticket search is triggered by user
+	for _, entity := range models.Registry.All() {
+		cmd.AddCommand(NewEntitySearchCmd(entity))
 	}
-
-	cmd.PersistentFlags().String("name", "", "The name of the field to search for.")
-	cmd.PersistentFlags().String("value", "", "Name of the field to search for")
-	_ = cmd.MarkPersistentFlagRequired("name")
-	//_ = cmd.MarkPersistentFlagRequired("value")
 	return cmd
 }
 
-// NewOrgSearchCmd - Define organization search command /*
-func NewOrgSearchCmd() *cobra.Command {
+// NewEntitySearchCmd - Define search command for an entity (user, ticket, organization etc.) /*
+func NewEntitySearchCmd(entity internal.Entity) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "organization",
-		Short: "trigger organization search",
-		RunE:  triggerOrgSearch, // method to run when organization search is triggered by user
+		Use:   entity.EntityName(),
+		Short: "trigger " + entity.EntityName() + " search",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return triggerSearch(cmd, entity) // method to run when search is triggered by user
+		},
 	}
 
 	cmd.PersistentFlags().String("name", "", "The name of the field to search for.")
 	cmd.PersistentFlags().String("value", "", "Name of the field to search for")
+	cmd.PersistentFlags().String("tz", "", "Timezone to search dates and display timestamps in, eg. Australia/Melbourne or +10:00")
+	cmd.PersistentFlags().String("output", internal.OutputText, "Format of results: text, or json in the schema of the data files")
+	cmd.PersistentFlags().String("fields", "", "Fields of results to display, separated by commas, eg. _id,name,email (default all)")
+	cmd.PersistentFlags().Bool("fuzzy", false, "Search string fields for values similar to --value, tolerating misspellings")
+	cmd.PersistentFlags().Bool("explain", false, "Display the plan of the search: how each condition is evaluated, and estimated vs actual counts")
+	cmd.PersistentFlags().Bool("timing", false, "Display the time spent in each phase of the search")
+	cmd.PersistentFlags().Bool(zsearch.PredicateMissing, false, "Search for entities which do not have the field at all")
+	cmd.PersistentFlags().Bool(zsearch.PredicateNull, false, "Search for entities which have the field set to null")
+	cmd.PersistentFlags().Bool(zsearch.PredicateEmpty, false, "Search for entities which have the field set to an empty string or list")
+	cmd.PersistentFlags().Bool(zsearch.PredicateNotMissing, false, "Search for entities which have the field")
+	cmd.PersistentFlags().Bool(zsearch.PredicateNotNull, false, "Search for entities which have the field set to anything but null")
+	cmd.PersistentFlags().Bool(zsearch.PredicateNotEmpty, false, "Search for entities which have the field set to anything but an empty string or list")
+	cmd.MarkFlagsMutuallyExclusive(append([]string{"value"}, zsearch.Predicates...)...)
+	cmd.MarkFlagsMutuallyExclusive(append([]string{"fuzzy"}, zsearch.Predicates...)...)
 	_ = cmd.MarkPersistentFlagRequired("name")
+	_ = cmd.RegisterFlagCompletionFunc("name", completeFields(entity))
+	_ = cmd.RegisterFlagCompletionFunc("value", completeValues(entity))
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(internal.ResultFormats, cobra.ShellCompDirectiveNoFileComp))
 	//_ = cmd.MarkPersistentFlagRequired("value")
 	return cmd
 }
@@ -1,168 +1,117 @@
 // Package search -
 //
-// This is the entry point of various search queries - user / ticket / organization, each of which invoke different
-// entry-points. The source of data is read from real JSON files for users and from testdata files for tests
+// This is the entry point of search queries for every registered entity (user / ticket / organization etc.), which are
+// evaluated by the zsearch package. The source of data is read from real JSON files for users and from testdata files
+// for tests
 //
 
 package search
 
 import (
 	"ZendeskChallenge/internal"
-	"ZendeskChallenge/models/organizations"
-	"ZendeskChallenge/models/tickets"
-	"ZendeskChallenge/models/users"
-	"encoding/json"
+	"ZendeskChallenge/pkg/zsearch"
+	"errors"
+	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
-const (
-	UsersFile         = "users.json"
-	TicketsFile       = "tickets.json"
-	OrganizationsFile = "organizations.json"
-)
-
-// Flags - Interface implemented by all models to allow easy retrieval of search flags, and use common data type
-type Flags interface {
-	FetchName() string
-	FetchValue() string
-}
-
-/*
-*		Get file data of specific file being queried.
-*		Method behaves differently in test environment to allow reading test files
-*
-*	    @return ([]byte, error): Data content of file and error if reading caused issue (such as fs.PathError)
- */
-func getFileData(fileName string) ([]byte, error) {
-	isTest, err := strconv.ParseBool(os.Getenv("TEST_ENV"))
-	var prefixPath string
-	if err == nil && isTest {
-		prefixPath = "testdata/"
-	}
-	return os.ReadFile(prefixPath + fileName)
-}
-
 /*
-*		Trigger user search. Extracts flag values and delegates processing of query and output evaluation to `process.go` methods
+*		Trigger search of an entity. Extracts flag values and delegates loading of data and evaluation of the search to
+*		the zsearch package
 *
-*	    @return (error): If any error occurs during validation of flags, or evaluation of search
+*	    @return (error): If any error occurs during validation of flags, or evaluation of search, or ErrNoResults if
+*		nothing matched
 *		Displays results if no errors
  */
-func triggerUserSearch(cmd *cobra.Command, args []string) error {
-	var data, _ = getFileData(UsersFile)
-	var orgData, _ = getFileData(OrganizationsFile)
-	var ticketData, _ = getFileData(TicketsFile)
-	// Declaration of the instance of the struct that we want to fill
-	var allUsers users.User
-	// Fill the instance from the JSON file content
-	err := json.Unmarshal(data, &allUsers)
-	if err != nil || len(allUsers) == 0 {
-		cmd.PrintErrf("error occurred during parsing %v: %v, length of data: %v",
-			UsersFile, err, len(allUsers))
+func triggerSearch(cmd *cobra.Command, entity internal.Entity) error {
+	start := time.Now()
+	explain, _ := cmd.Flags().GetBool("explain")
+	timing, _ := cmd.Flags().GetBool("timing")
+	ctx, trace := zsearch.Traced(cmd.Context(), explain, timing)
+	dataset, err := zsearch.Load(ctx, entity.EntityName())
+	if err != nil {
 		return err
 	}
-	userData := users.UserData{
-		Raw:       data,
-		Processed: allUsers,
-	}
 	value, _ := cmd.Flags().GetString("value")
 	name, _ := cmd.Flags().GetString("name") // This is already validated by Cobra framework before reaching here
-	flags := users.UserSearchFlags{
+	fuzzy, _ := cmd.Flags().GetBool("fuzzy")
+	condition := zsearch.Condition{
 		Name:  name,
 		Value: value,
+		Fuzzy: fuzzy,
 	}
-	result, err := evaluateSearch(flags, &userData, users.KeyMappings)
-	if err != nil {
-		cmd.PrintErr(err)
-		log.Errorf(err.Error())
-		return err
+	for _, predicate := range zsearch.Predicates {
+		if set, _ := cmd.Flags().GetBool(predicate); set {
+			condition.Predicate = predicate
+		}
 	}
-	filteredUsers := result.FetchFiltered().(users.User)
-	addRelatedUserEntities(filteredUsers, orgData, ticketData)
-	internal.DisplayResults(cmd, filteredUsers, users.KeyMappings)
-	log.Infof("All results displayed")
-	return nil
-}
-
-/*
-*		Trigger ticket search. Extracts flag values and delegates processing of query and output evaluation to `process.go` methods
-*
-*	    @return (error): If any error occurs during validation of flags, or evaluation of search
-*		Displays results if no errors
- */
-func triggerTicketSearch(cmd *cobra.Command, args []string) error {
-	var data, _ = getFileData(TicketsFile)
-	var orgData, _ = getFileData(OrganizationsFile)
-	var userData, _ = getFileData(UsersFile)
-	// Declaration of the instance of the struct that we want to fill
-	var allTickets tickets.Ticket
-	// Fill the instance from the JSON file content
-	err := json.Unmarshal(data, &allTickets)
-	if err != nil || len(allTickets) == 0 {
-		cmd.PrintErrf("error occurred during parsing %v: %v, length of data: %v",
-			TicketsFile, err, len(allTickets))
-		return err
+	if tz := internal.TimezoneOf(cmd); tz != "" {
+		condition.Location, err = internal.LoadTimezone(tz)
+		if err != nil {
+			return err
+		}
 	}
-	ticketData := tickets.TicketData{
-		Raw:       data,
-		Processed: allTickets,
+	options, err := internal.ResolveDisplayOptions(cmd, entity.EntityName(), entity.Mappings(), internal.ResultFormats)
+	if err != nil {
+		return cmd.FlagErrorFunc()(cmd, err)
 	}
-	value, _ := cmd.Flags().GetString("value")
-	name, _ := cmd.Flags().GetString("name") // This is already validated by Cobra framework before reaching here
-	flags := tickets.TicketSearchFlags{
-		Name:  name,
-		Value: value,
+	results, err := dataset.Find(ctx, entity.EntityName(), condition)
+	var searchError *zsearch.SearchError
+	if errors.Is(err, zsearch.ErrUnknownField) && errors.As(err, &searchError) {
+		if len(searchError.Suggestions) > 0 {
+			cmd.PrintErrln(fmt.Sprintf("Invalid field passed in for --name. Did you mean --name %v? Otherwise please use 'list' command to find searchable fields", searchError.Suggestions[0]))
+		} else {
+			cmd.PrintErrln("Invalid field passed in for --name. Please use 'list' command to find searchable fields")
+		}
 	}
-	result, err := evaluateSearch(flags, &ticketData, tickets.KeyMappings)
+	fields := log.Fields{"entity": entity.EntityName(), "field": name, "fuzzy": fuzzy, "duration": time.Since(start)}
 	if err != nil {
-		cmd.PrintErr(err)
-		log.Errorf(err.Error())
+		log.WithFields(fields).Error(err)
 		return err
 	}
-	filteredTickets := result.FetchFiltered().(tickets.Ticket)
-	addRelatedTicketEntities(filteredTickets, orgData, userData)
-	internal.DisplayResults(cmd, filteredTickets, tickets.KeyMappings)
-	log.Info("All results displayed")
+	displayed := time.Now()
+	filtered := internal.Records[any](results)
+	internal.InTimezone(filtered, condition.Location)
+	internal.DisplayResultsWith(cmd, filtered, entity.Mappings(), options)
+	trace.Observe(zsearch.PhaseDisplay, time.Since(displayed))
+	trace.Write(cmd.OutOrStdout(), explain, timing)
+	fields["results"] = len(results)
+	log.WithFields(fields).Infof("All results displayed")
+	if len(results) == 0 {
+		printNoMatches(cmd, dataset, entity, condition)
+		if condition.Predicate != "" {
+			return fmt.Errorf("%w for %v search of %v %v", internal.ErrNoResults, entity.EntityName(), name, condition.Predicate)
+		}
+		return fmt.Errorf("%w for %v search of %v %q", internal.ErrNoResults, entity.EntityName(), name, value)
+	}
 	return nil
 }
 
 /*
-*		Trigger organization search. Extracts flag values and delegates processing of query and output evaluation to `process.go` methods
-*
-*	    @return (error): If any error occurs during validation of flags, or evaluation of search
-*		Displays results if no errors
+*		Print that a search found nothing, along with the values closest to its value if there are any close enough
+*		(eg. a misspelled name of a user)
  */
-func triggerOrgSearch(cmd *cobra.Command, args []string) error {
-	var data, _ = getFileData(OrganizationsFile)
-	// Declaration of the instance of the struct that we want to fill
-	allOrgs := organizations.Organization{}
-	// Fill the instance from the JSON file content
-	err := json.Unmarshal(data, &allOrgs)
-	if err != nil || len(allOrgs) == 0 {
-		cmd.PrintErrf("error occurred during parsing %v: %v, length of data: %v",
-			OrganizationsFile, err, len(allOrgs))
-		return err
+func printNoMatches(cmd *cobra.Command, dataset *zsearch.Dataset, entity internal.Entity, condition zsearch.Condition) {
+	if condition.Predicate != "" {
+		cmd.PrintErrln(fmt.Sprintf("No %v found where %v is %v", entity.EntityName()+"s", condition.Name, condition.Predicate))
+		return
 	}
-	orgData := organizations.OrgData{
-		Raw:       data,
-		Processed: allOrgs,
+	message := fmt.Sprintf("No %v found with %v of %q", entity.EntityName()+"s", condition.Name, condition.Value)
+	var suggestions []string
+	if condition.Value != "" {
+		suggestions = dataset.SuggestValues(entity.EntityName(), condition.Name, condition.Value, 3)
 	}
-	value, _ := cmd.Flags().GetString("value")
-	name, _ := cmd.Flags().GetString("name") // This is already validated by Cobra framework before reaching here
-	flags := organizations.OrganizationSearchFlags{
-		Name:  name,
-		Value: value,
+	if len(suggestions) == 0 {
+		cmd.PrintErrln(message)
+		return
 	}
-	result, err := evaluateSearch(flags, &orgData, organizations.KeyMappings)
-	if err != nil {
-		cmd.PrintErr(err)
-		log.Errorf(err.Error())
-		return err
+	quoted := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		quoted[i] = strconv.Quote(suggestion)
 	}
-	internal.DisplayResults(cmd, result.FetchFiltered(), organizations.KeyMappings)
-	log.Info("All results displayed")
-	return nil
+	cmd.PrintErrln(fmt.Sprintf("%v. Did you mean %v? (or search with --fuzzy)", message, strings.Join(quoted, ", ")))
 }
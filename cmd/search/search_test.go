@@ -1,34 +1,51 @@
 package search
 
 import (
+	"ZendeskChallenge/internal"
 	"ZendeskChallenge/models/organizations"
 	"ZendeskChallenge/models/tickets"
 	"ZendeskChallenge/models/users"
+	"ZendeskChallenge/pkg/zsearch"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/fs"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"testing"
 )
 
+// Directory of the test data shared by all packages, relative to the directory of a package
+var testDataDir = filepath.Join("..", "..", "testdata")
+
 // Test entrypoint methods that trigger based on the user invoked search query (for user/ticket/org)
 
+type TestSuite struct {
+	suite.Suite
+	orgRaw    []byte
+	userRaw   []byte
+	ticketRaw []byte
+}
+
+func TestSearchSuite(t *testing.T) {
+	suite.Run(t, new(TestSuite))
+}
+
 func (suite *TestSuite) SetupSuite() {
-	suite.orgRaw, _ = os.ReadFile("testdata/organizations.json")
-	suite.ticketRaw, _ = os.ReadFile("testdata/tickets.json")
-	suite.userRaw, _ = os.ReadFile("testdata/users.json")
-	suite.orgData = GetSampleOrgData()
-	suite.ticketData = GetSampleTicketData()
-	suite.userData = GetSampleUserData()
+	suite.orgRaw, _ = os.ReadFile(filepath.Join(testDataDir, "organizations.json"))
+	suite.ticketRaw, _ = os.ReadFile(filepath.Join(testDataDir, "tickets.json"))
+	suite.userRaw, _ = os.ReadFile(filepath.Join(testDataDir, "users.json"))
 }
 
 func (suite *TestSuite) SetupTest() {
 	// reset StartingNumber to one
 	fmt.Println("-- From SetupTest")
-	_ = os.Setenv("TEST_ENV", "true") // Set for using different file data source for tests
+	suite.T().Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
 }
 
 // this function executes after each test case
@@ -36,22 +53,6 @@ func (suite *TestSuite) TearDownTest() {
 	fmt.Println("-- From TearDownTest")
 }
 
-func (suite *TestSuite) Test_getFileData() {
-	suite.Run("Testing test environment causes data to be read from different sources", func() {
-		_ = os.Unsetenv("TEST_ENV")
-		data1, err := getFileData(UsersFile)
-		suite.NotNil(err)
-		suite.Nil(data1)                        // Root data files not accessible in test execution
-		suite.IsType((*fs.PathError)(nil), err) // Assert correct error type thrown
-		_ = os.Setenv("TEST_ENV", "true")       // Set for using different file data source for tests
-		data2, err := getFileData(UsersFile)
-		suite.Nil(err)
-		suite.NotNil(data2)
-		suite.NotEqual(data1, data2, "Data is read from different sources if test environment is switched off/on")
-		suite.NotEmpty(data2, "Test data read is not empty")
-	})
-}
-
 func (suite *TestSuite) Test_ExecuteSearchCommand_Help() {
 	suite.Run("Execute search command with help flag invoked and assert output", func() {
 		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
@@ -77,13 +78,16 @@ func (suite *TestSuite) Test_ExecuteSearchCommand_Help() {
 		suite.True(strings.Contains(buffer.String(), "trigger organization search"), "Shows organization search usage")
 		suite.True(strings.Contains(buffer.String(), "trigger user search"), "Shows user search usage")
 		suite.True(strings.Contains(buffer.String(), "trigger ticket search"), "Shows ticket search usage")
+		suite.True(strings.Contains(buffer.String(), "trigger group search"), "Shows group search usage")
+		suite.True(strings.Contains(buffer.String(), "trigger comment search"), "Shows comment search usage")
+		suite.True(strings.Contains(buffer.String(), "trigger rating search"), "Shows rating search usage")
 	})
 }
 
 func (suite *TestSuite) Test_ExecuteSearchCommand_UserInvalid() {
 	suite.Run("Execute invalid ticket search", func() {
 		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
-		cmd := NewUserSearchCmd()
+		cmd := NewEntitySearchCmd(users.Model)
 
 		cmd.SetOut(buffer)
 		cmd.SetErr(buffer)
@@ -98,7 +102,7 @@ func (suite *TestSuite) Test_ExecuteSearchCommand_UserInvalid() {
 func (suite *TestSuite) Test_ExecuteSearchCommand_UserValid() {
 	suite.Run("Execute valid ticket search command and assert output", func() {
 		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
-		cmd := NewUserSearchCmd()
+		cmd := NewEntitySearchCmd(users.Model)
 
 		cmd.SetOut(buffer)
 		cmd.SetErr(buffer)
@@ -138,7 +142,7 @@ func (suite *TestSuite) Test_ExecuteSearchCommand_UserValid() {
 func (suite *TestSuite) Test_ExecuteSearchCommand_TicketInvalid() {
 	suite.Run("Execute invalid ticket search", func() {
 		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
-		cmd := NewTicketSearchCmd()
+		cmd := NewEntitySearchCmd(tickets.Model)
 
 		cmd.SetOut(buffer)
 		cmd.SetErr(buffer)
@@ -153,7 +157,7 @@ func (suite *TestSuite) Test_ExecuteSearchCommand_TicketInvalid() {
 func (suite *TestSuite) Test_ExecuteSearchCommand_TicketValid() {
 	suite.Run("Execute valid ticket search command and assert output ", func() {
 		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
-		cmd := NewTicketSearchCmd()
+		cmd := NewEntitySearchCmd(tickets.Model)
 
 		cmd.SetOut(buffer)
 		cmd.SetErr(buffer)
@@ -193,7 +197,7 @@ func (suite *TestSuite) Test_ExecuteSearchCommand_TicketValid() {
 func (suite *TestSuite) Test_ExecuteSearchCommand_OrgInvalid() {
 	suite.Run("Execute invalid organization search", func() {
 		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
-		cmd := NewOrgSearchCmd()
+		cmd := NewEntitySearchCmd(organizations.Model)
 
 		cmd.SetOut(buffer)
 		cmd.SetErr(buffer)
@@ -208,7 +212,7 @@ func (suite *TestSuite) Test_ExecuteSearchCommand_OrgInvalid() {
 func (suite *TestSuite) Test_ExecuteSearchCommand_OrgValid() {
 	suite.Run("Execute valid organization search command and assert output", func() {
 		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
-		cmd := NewOrgSearchCmd()
+		cmd := NewEntitySearchCmd(organizations.Model)
 
 		cmd.SetOut(buffer)
 		cmd.SetErr(buffer)
@@ -247,3 +251,165 @@ func (suite *TestSuite) Test_ExecuteSearchCommand_OrgValid() {
 		}
 	})
 }
+
+func (suite *TestSuite) Test_ExecuteSearchCommand_CustomField() {
+	suite.Run("Execute ticket search by friendly name of a custom field and assert output", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(tickets.Model)
+
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"ticket", "--name", "product", "--value", "chat"})
+		err := cmd.Execute()
+		suite.Nil(err)
+
+		suite.True(strings.HasPrefix(buffer.String(), "======== All results ========"), "Message output starts as expected")
+		suite.Equal(1, strings.Count(buffer.String(), "------------------------------------------------"))
+		suite.True(strings.Contains(buffer.String(), "_id: 20615fe1-765b-4ff5-b4f6-ea42dcc8cac3\n"))
+		suite.True(strings.Contains(buffer.String(), "custom_field.360001234: chat\n"), "Custom fields are displayed")
+		suite.True(strings.Contains(buffer.String(), "custom_field.360005678: true\n"), "Custom fields are displayed")
+	})
+}
+
+func (suite *TestSuite) Test_ExecuteSearchCommand_Predicate() {
+	suite.Run("Execute user search for a null field and assert output", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "alias", "--null"})
+		err := cmd.Execute()
+		suite.Nil(err)
+		suite.Equal(1, strings.Count(buffer.String(), "------------------------------------------------"))
+		suite.True(strings.Contains(buffer.String(), "_id: 74\n"))
+	})
+	suite.Run("Execute user search with both a value and a predicate", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "alias", "--value", "", "--not-null"})
+		err := cmd.Execute()
+		suite.NotNil(err)
+		suite.True(strings.Contains(err.Error(), "[not-null value] were all set"))
+	})
+}
+
+func (suite *TestSuite) Test_ExecuteSearchCommand_Explain() {
+	suite.Run("Execute user search with --explain and --timing and assert the plan and timing follow results", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "_id", "--value", "22", "--explain", "--timing"})
+		err := cmd.Execute()
+		suite.Nil(err)
+		output := buffer.String()
+		suite.True(strings.Index(output, "_id: 22\n") < strings.Index(output, "======== Query plan ========"))
+		suite.True(strings.Contains(output, "1. user where _id = \"22\"\n   scan of raw data (int equality): searched "))
+		suite.True(strings.Contains(output, "======== Timing ========"))
+		for _, phase := range []string{"load data:", "match conditions:", "display results:", "total:"} {
+			suite.True(strings.Contains(output, phase), phase)
+		}
+	})
+	suite.Run("Execute user search without --explain and assert there is no plan", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "_id", "--value", "22", "--timing"})
+		err := cmd.Execute()
+		suite.Nil(err)
+		suite.False(strings.Contains(buffer.String(), "======== Query plan ========"))
+		suite.True(strings.Contains(buffer.String(), "======== Timing ========"))
+	})
+}
+
+func (suite *TestSuite) Test_ExecuteSearchCommand_Fuzzy() {
+	suite.Run("Execute user search for a misspelled name with --fuzzy and assert output", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "name", "--value", "moran danials", "--fuzzy"})
+		err := cmd.Execute()
+		suite.Nil(err)
+		suite.Equal(1, strings.Count(buffer.String(), "------------------------------------------------"))
+		suite.True(strings.Contains(buffer.String(), "_id: 22\n"))
+	})
+	suite.Run("Execute user search for a misspelled name and assert the closest name is suggested", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "name", "--value", "Melisa Bishop"})
+		err := cmd.Execute()
+		suite.True(errors.Is(err, internal.ErrNoResults))
+		suite.Equal(0, strings.Count(buffer.String(), "------------------------------------------------"))
+		suite.True(strings.Contains(buffer.String(), `No users found with name of "Melisa Bishop". Did you mean "Melissa Bishop"?`))
+	})
+	suite.Run("Execute user search finding nothing and assert what was searched for is printed", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "_id", "--value", "9999"})
+		err := cmd.Execute()
+		suite.True(errors.Is(err, internal.ErrNoResults))
+		suite.True(strings.HasPrefix(buffer.String(), "No users found with _id of \"9999\"\n"))
+	})
+	suite.Run("Execute user search of a predicate finding nothing and assert the predicate is printed", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "_id", "--missing"})
+		err := cmd.Execute()
+		suite.True(errors.Is(err, internal.ErrNoResults))
+		suite.True(strings.HasPrefix(buffer.String(), "No users found where _id is missing\n"))
+	})
+	suite.Run("Execute user search of a misspelled field and assert the closest field is suggested", func() {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewEntitySearchCmd(users.Model)
+
+		cmd.SetOut(buffer)
+		cmd.SetErr(buffer)
+		cmd.SetArgs([]string{"user", "--name", "emial", "--value", "a@b.com"})
+		err := cmd.Execute()
+		suite.True(errors.Is(err, zsearch.ErrUnknownField))
+		suite.True(strings.Contains(buffer.String(), "Did you mean --name email?"))
+	})
+}
+
+func (suite *TestSuite) Test_ExecuteSearchCommand_Completion() {
+	suite.T().Setenv("XDG_CONFIG_HOME", suite.T().TempDir()) // No config of the user is applied
+
+	complete := func(args ...string) string {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := NewSearchCmd()
+		cmd.SetOut(buffer)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+		suite.Nil(cmd.Execute())
+		return buffer.String()
+	}
+	suite.Run("Complete --name by the searchable fields of the entity, and names of its custom fields", func() {
+		suite.Equal("role\n:4\n", complete("user", "--name", "ro"))
+		suite.Equal("plan\n:4\n", complete("user", "--name", "pl"))
+	})
+	suite.Run("Complete --value by the values of the field in the data", func() {
+		suite.Equal("admin\nagent\nend-user\n:4\n", complete("user", "--name", "role", "--value", ""))
+		suite.Equal("pending\n:4\n", complete("ticket", "--name", "status", "--value", "p"))
+	})
+	suite.Run("Complete --value without --name", func() {
+		suite.Equal(":4\n", complete("user", "--value", ""))
+	})
+}
@@ -0,0 +1,303 @@
+// Package serve -
+//
+// This file is meant for the GraphQL endpoint of the server (POST or GET /graphql), which serves entities as a graph:
+// each entity is a type with its searchable fields, and with a field for every relationship between entities, in both
+// directions (eg. ticket → submitter → organization → tickets). Types are built from the models, so that new entities
+// and fields are served without changes here.
+//
+// Related entities are looked up in indexes of the dataset, built once for each field they are looked up by, rather
+// than by scanning all entities for every entity resolved
+package serve
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"ZendeskChallenge/pkg/zsearch"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// GraphQLPath - Path of the GraphQL endpoint
+const GraphQLPath = "/graphql"
+
+// Key of the dataset a GraphQL request is resolved with, in the context of the request
+type datasetKey struct{}
+
+// graphQLRequest - Query of a GraphQL request, along with its variables and the operation to execute
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// Name of the GraphQL type of an entity, eg. User
+func typeName(entity internal.Entity) string {
+	return strings.ToUpper(entity.EntityName()[:1]) + entity.EntityName()[1:]
+}
+
+// GraphQL type of a field of an entity, or nil if the field is not served (eg. maps of custom fields)
+func fieldType(t reflect.Type) graphql.Output {
+	if t == reflect.TypeOf(internal.Timestamp{}) {
+		return graphql.String
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+		return graphql.Int
+	case reflect.Bool:
+		return graphql.Boolean
+	case reflect.String:
+		return graphql.String
+	case reflect.Slice:
+		if elem := fieldType(t.Elem()); elem != nil {
+			return graphql.NewList(elem)
+		}
+	}
+	return nil
+}
+
+// Value of a field of an entity, with timestamps as strings (or null if they are zero)
+func fieldValue(source any, entity internal.Entity, field string) any {
+	value := reflect.ValueOf(source).FieldByName(entity.Mappings()[field])
+	if !value.IsValid() {
+		return nil
+	}
+	if timestamp, ok := value.Interface().(internal.Timestamp); ok {
+		if timestamp.IsZero() {
+			return nil
+		}
+		return timestamp.String()
+	}
+	return value.Interface()
+}
+
+/*
+*		Arguments filtering a list of entities, one for each searchable field, with the same values as searches by that
+*		field (eg. dates, or a single value of a list field)
+ */
+func filterArgs(entity internal.Entity) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{}
+	for _, field := range entity.SearchableFields() {
+		var argType graphql.Input = graphql.String
+		if t := entity.FieldType(field); t != nil {
+			switch fieldType(t) {
+			case graphql.Int:
+				argType = graphql.Int
+			case graphql.Boolean:
+				argType = graphql.Boolean
+			}
+			if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Int {
+				argType = graphql.Int
+			}
+		}
+		args[field] = &graphql.ArgumentConfig{Type: argType}
+	}
+	args["limit"] = limitArg
+	return args
+}
+
+// Argument limiting a list of entities to a maximum number of entities
+var limitArg = &graphql.ArgumentConfig{Type: graphql.Int, Description: "Maximum number of entities"}
+
+// Search conditions of filter arguments, in order of the searchable fields
+func filterConditions(entity internal.Entity, args map[string]any) []zsearch.Condition {
+	var conditions []zsearch.Condition
+	for _, field := range entity.SearchableFields() {
+		if value, ok := args[field]; ok && value != nil {
+			conditions = append(conditions, zsearch.Condition{Name: field, Value: fmt.Sprint(value)})
+		}
+	}
+	return conditions
+}
+
+// Entities limited to the limit argument, if it is given
+func limit(entities []interface{}, args map[string]any) []interface{} {
+	if n, ok := args["limit"].(int); ok && n >= 0 && n < len(entities) {
+		return entities[:n]
+	}
+	return entities
+}
+
+// Dataset a GraphQL request is resolved with
+func datasetOf(ctx context.Context) *Dataset {
+	dataset, _ := ctx.Value(datasetKey{}).(*Dataset)
+	return dataset
+}
+
+/*
+*		Build the GraphQL schema of all entities. The query has a field for every entity by its primary key (eg. user),
+*		and for a filtered list of every entity (eg. users)
+ */
+func NewGraphQLSchema() (graphql.Schema, error) {
+	edges := zsearch.Edges()
+	types := map[string]*graphql.Object{}
+	for _, entity := range models.Registry.All() {
+		entity := entity
+		types[entity.EntityName()] = graphql.NewObject(graphql.ObjectConfig{
+			Name: typeName(entity),
+			Fields: graphql.FieldsThunk(func() graphql.Fields {
+				fields := graphql.Fields{}
+				for _, field := range entity.SearchableFields() {
+					field := field
+					t := fieldType(entity.FieldType(field))
+					if t == nil {
+						continue
+					}
+					fields[field] = &graphql.Field{
+						Type: t,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return fieldValue(p.Source, entity, field), nil
+						},
+					}
+				}
+				for _, e := range edges[entity.EntityName()] {
+					e := e
+					var t graphql.Output = types[e.Entity.EntityName()]
+					args := graphql.FieldConfigArgument{}
+					if e.List {
+						t = graphql.NewList(t)
+						args["limit"] = limitArg
+					}
+					fields[e.Name] = &graphql.Field{
+						Type: t,
+						Args: args,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							related := datasetOf(p.Context).lookup(e.Entity, e.ForeignKey, fieldValue(p.Source, entity, e.LocalKey))
+							if e.List {
+								return limit(related, p.Args), nil
+							}
+							if len(related) == 0 {
+								return nil, nil
+							}
+							return related[0], nil
+						},
+					}
+				}
+				return fields
+			}),
+		})
+	}
+	queryFields := graphql.Fields{}
+	for _, entity := range models.Registry.All() {
+		entity := entity
+		queryFields[entity.EntityName()] = &graphql.Field{
+			Type: types[entity.EntityName()],
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				related := datasetOf(p.Context).lookup(entity, entity.PrimaryKey(), p.Args["id"])
+				if len(related) == 0 {
+					return nil, nil
+				}
+				return related[0], nil
+			},
+		}
+		queryFields[entity.EntityName()+"s"] = &graphql.Field{
+			Type: graphql.NewList(types[entity.EntityName()]),
+			Args: filterArgs(entity),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				dataset := datasetOf(p.Context)
+				conditions := filterConditions(entity, p.Args)
+				if len(conditions) == 0 {
+					return limit(dataset.All(entity.EntityName()), p.Args), nil
+				}
+				entities, err := dataset.Find(p.Context, entity.EntityName(), conditions...)
+				if err != nil {
+					return nil, err
+				}
+				return limit(entities, p.Args), nil
+			},
+		}
+	}
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: queryFields}),
+	})
+}
+
+/*
+*		Entities of the dataset with a value of a field, looked up in an index of the field. The index is built the first
+*		time entities are looked up by the field, and indexes every value of list fields (eg. agent_ids of groups)
+ */
+func (dataset *Dataset) lookup(entity internal.Entity, field string, value any) []interface{} {
+	if value == nil {
+		return nil
+	}
+	dataset.indexMutex.Lock()
+	key := entity.EntityName() + "." + field
+	index, ok := dataset.indexes[key]
+	if !ok {
+		index = map[string][]interface{}{}
+		for _, record := range dataset.All(entity.EntityName()) {
+			for _, k := range indexValues(fieldValue(record, entity, field)) {
+				index[k] = append(index[k], record)
+			}
+		}
+		if dataset.indexes == nil {
+			dataset.indexes = map[string]map[string][]interface{}{}
+		}
+		dataset.indexes[key] = index
+	}
+	dataset.indexMutex.Unlock()
+	var all []interface{}
+	for _, k := range indexValues(value) {
+		all = append(all, index[k]...)
+	}
+	return all
+}
+
+// Values of a field as index keys. A list field has each of its values as a key
+func indexValues(value any) []string {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return []string{fmt.Sprint(value)}
+	}
+	var keys []string
+	for i := 0; i < v.Len(); i++ {
+		keys = append(keys, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return keys
+}
+
+/*
+*		Handle a GraphQL request, given as JSON in a POST request or as query parameters of a GET request. The request
+*		is resolved with the dataset being served when it started
+ */
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request graphQLRequest
+	switch r.Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New(fmt.Sprintf("invalid GraphQL request: %v", err)))
+			return
+		}
+	case http.MethodGet:
+		request.Query = r.URL.Query().Get("query")
+		request.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, errors.New(fmt.Sprintf("invalid GraphQL variables: %v", err)))
+				return
+			}
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("only GET and POST requests are supported"))
+		return
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         *s.schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        context.WithValue(r.Context(), datasetKey{}, s.Dataset()),
+	})
+	writeJSON(w, http.StatusOK, result)
+}
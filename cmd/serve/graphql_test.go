@@ -0,0 +1,78 @@
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Test GraphQL queries of entities and their relationships
+
+// Post a GraphQL query to the server, and decode the JSON response
+func (suite *TestSuite) query(query string, variables map[string]any) (map[string]any, []any) {
+	body, _ := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	resp, err := http.Post(suite.server.URL+GraphQLPath, "application/json", bytes.NewReader(body))
+	suite.Require().Nil(err)
+	defer resp.Body.Close()
+	suite.Equal(http.StatusOK, resp.StatusCode)
+	var response struct {
+		Data   map[string]any `json:"data"`
+		Errors []any          `json:"errors"`
+	}
+	suite.Require().Nil(json.NewDecoder(resp.Body).Decode(&response))
+	return response.Data, response.Errors
+}
+
+func (suite *TestSuite) TestGraphQL_Query() {
+	suite.Run("entity by id, along with related entities in both directions", func() {
+		data, errors := suite.query(`{
+			ticket(id: "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3") {
+				subject
+				created_at
+				submitter { name organization { name } }
+				organization { name tickets { _id } }
+			}
+		}`, nil)
+		suite.Nil(errors)
+		suite.Equal(map[string]any{
+			"subject":    "A Problem in Gambia",
+			"created_at": "2016-03-25T05:33:29 -11:00",
+			"submitter":  map[string]any{"name": "Moran Daniels", "organization": map[string]any{"name": "Terrasys"}},
+			"organization": map[string]any{"name": "Geekfarm", "tickets": []any{
+				map[string]any{"_id": "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"},
+				map[string]any{"_id": "3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+			}},
+		}, data["ticket"])
+	})
+	suite.Run("unknown entity by id", func() {
+		data, errors := suite.query(`{ user(id: 1) { name } }`, nil)
+		suite.Nil(errors)
+		suite.Nil(data["user"])
+	})
+	suite.Run("entities filtered by fields, with variables and a limit", func() {
+		data, errors := suite.query(`query($role: String) { users(role: $role, limit: 2) { _id groups { name } } }`, map[string]any{"role": "end-user"})
+		suite.Nil(errors)
+		suite.Equal([]any{
+			map[string]any{"_id": float64(707070707), "groups": []any{}},
+			map[string]any{"_id": float64(70), "groups": []any{}},
+		}, data["users"])
+	})
+	suite.Run("entities filtered by a value of a list field", func() {
+		data, errors := suite.query(`{ groups(agent_ids: 74) { _id agents { _id } } }`, nil)
+		suite.Nil(errors)
+		suite.Equal(2, len(data["groups"].([]any)))
+	})
+	suite.Run("invalid filter", func() {
+		_, errors := suite.query(`{ tickets(created_at: "last tuesday") { _id } }`, nil)
+		suite.NotEmpty(errors)
+	})
+	suite.Run("query as parameter of a GET request", func() {
+		resp, err := http.Get(suite.server.URL + GraphQLPath + "?query=" + url.QueryEscape(`{ organization(id: 107) { name users { name } } }`))
+		suite.Require().Nil(err)
+		defer resp.Body.Close()
+		var response map[string]any
+		suite.Require().Nil(json.NewDecoder(resp.Body).Decode(&response))
+		suite.Equal(map[string]any{"organization": map[string]any{"name": "Terrasys", "users": []any{map[string]any{"name": "Moran Daniels"}}}}, response["data"])
+	})
+}
@@ -0,0 +1,194 @@
+// Package serve -
+//
+// This file is meant for serving search over gRPC (see `api/search.proto`), which searches the same dataset as the
+// JSON API does. Search and List stream matching entities back one by one, rather than as a single response
+package serve
+
+import (
+	"ZendeskChallenge/api/searchpb"
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"ZendeskChallenge/pkg/zsearch"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"time"
+)
+
+// grpcService - Implements the search service of `api/search.proto` over the dataset of a server
+type grpcService struct {
+	searchpb.UnimplementedSearchServiceServer
+	server *Server
+}
+
+// Entity of each type of the search service
+var entityTypes = map[searchpb.EntityType]string{
+	searchpb.EntityType_ENTITY_TYPE_USER:         "user",
+	searchpb.EntityType_ENTITY_TYPE_ORGANIZATION: "organization",
+	searchpb.EntityType_ENTITY_TYPE_TICKET:       "ticket",
+}
+
+// Fields of entities which are not declared by the messages (eg. custom fields of the data) are left out of them
+var unmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// NewGRPCServer - gRPC server of the search service, searching the dataset currently served by a server
+func NewGRPCServer(server *Server) *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(logUnaryCalls),
+		grpc.StreamInterceptor(logStreamCalls),
+	)
+	searchpb.RegisterSearchServiceServer(grpcServer, &grpcService{server: server})
+	return grpcServer
+}
+
+// Search - Stream entities of a type matching all conditions
+func (g *grpcService) Search(request *searchpb.SearchRequest, stream searchpb.SearchService_SearchServer) error {
+	entity, err := entityOf(request.GetEntity())
+	if err != nil {
+		return err
+	}
+	var conditions []zsearch.Condition
+	for _, condition := range request.GetConditions() {
+		conditions = append(conditions, zsearch.Condition{Name: condition.GetName(), Value: condition.GetValue()})
+	}
+	results, err := g.server.search(stream.Context(), entity, conditions)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return sendRecords(stream, entity, results, request.GetLimit())
+}
+
+// Get - Get an entity of a type by its primary key
+func (g *grpcService) Get(ctx context.Context, request *searchpb.GetRequest) (*searchpb.Record, error) {
+	entity, err := entityOf(request.GetEntity())
+	if err != nil {
+		return nil, err
+	}
+	result, err := g.server.get(ctx, entity, request.GetId())
+	if errors.Is(err, zsearch.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toRecord(entity, result)
+}
+
+// List - Stream all entities of a type
+func (g *grpcService) List(request *searchpb.ListRequest, stream searchpb.SearchService_ListServer) error {
+	entity, err := entityOf(request.GetEntity())
+	if err != nil {
+		return err
+	}
+	results, err := g.server.search(stream.Context(), entity, nil)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return sendRecords(stream, entity, results, request.GetLimit())
+}
+
+// Registered entity of a type of the search service. A request without a type is invalid, and a type which is not
+// registered (eg. of a newer client) is not found
+func entityOf(entityType searchpb.EntityType) (internal.Entity, error) {
+	if entityType == searchpb.EntityType_ENTITY_TYPE_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "entity type is required")
+	}
+	name, ok := entityTypes[entityType]
+	if !ok {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("unknown entity type %v", entityType))
+	}
+	entity, _ := models.Registry.Get(name)
+	return entity, nil
+}
+
+// recordSender - Stream of records, sent by both Search and List
+type recordSender interface {
+	Send(*searchpb.Record) error
+	Context() context.Context
+}
+
+/*
+*		Send entities as records over a stream, up to a limit (or all of them if it is 0), stopping early if the
+*		client cancels the call
+ */
+func sendRecords(stream recordSender, entity internal.Entity, results []interface{}, limit int32) error {
+	for i, result := range results {
+		if limit > 0 && i >= int(limit) {
+			break
+		}
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		record, err := toRecord(entity, result)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+*		Convert an entity to a record, by way of its JSON, whose field names the messages of the records share
+*
+*	    @return (*searchpb.Record, error): Record, and error if the entity could not be converted
+ */
+func toRecord(entity internal.Entity, result interface{}) (*searchpb.Record, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	record := &searchpb.Record{}
+	switch entity.EntityName() {
+	case "user":
+		user := &searchpb.User{}
+		err = unmarshalOptions.Unmarshal(raw, user)
+		record.Entity = &searchpb.Record_User{User: user}
+	case "organization":
+		organization := &searchpb.Organization{}
+		err = unmarshalOptions.Unmarshal(raw, organization)
+		record.Entity = &searchpb.Record_Organization{Organization: organization}
+	case "ticket":
+		ticket := &searchpb.Ticket{}
+		err = unmarshalOptions.Unmarshal(raw, ticket)
+		record.Entity = &searchpb.Record_Ticket{Ticket: ticket}
+	default:
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("unsupported entity %v", entity.EntityName()))
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("error occurred during converting %v: %v", entity.EntityName(), err))
+	}
+	return record, nil
+}
+
+// Log method, status and duration of every unary call
+func logUnaryCalls(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	response, err := handler(ctx, request)
+	logCall(info.FullMethod, err, start)
+	return response, err
+}
+
+// Log method, status and duration of every streaming call
+func logStreamCalls(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(server, stream)
+	logCall(info.FullMethod, err, start)
+	return err
+}
+
+func logCall(method string, err error, start time.Time) {
+	log.WithFields(log.Fields{
+		"method":   method,
+		"status":   status.Code(err).String(),
+		"duration": time.Since(start),
+	}).Info("Call served")
+}
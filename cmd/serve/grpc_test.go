@@ -0,0 +1,178 @@
+package serve
+
+import (
+	"ZendeskChallenge/api/searchpb"
+	"ZendeskChallenge/internal"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+)
+
+// Serve the search service of the test data on an in-process listener, and connect a client to it
+func grpcClient(t *testing.T) searchpb.SearchServiceClient {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+	dataset, err := LoadDataset()
+	assert.Nil(t, err)
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := NewGRPCServer(NewServer(dataset))
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return searchpb.NewSearchServiceClient(conn)
+}
+
+// Receive all records of a stream
+func receiveAll(stream interface {
+	Recv() (*searchpb.Record, error)
+}) ([]*searchpb.Record, error) {
+	var records []*searchpb.Record
+	for {
+		record, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+func TestGRPCSearch(t *testing.T) {
+	client := grpcClient(t)
+	ctx := context.Background()
+
+	t.Run("test search streams entities matching all conditions", func(t *testing.T) {
+		stream, err := client.Search(ctx, &searchpb.SearchRequest{
+			Entity: searchpb.EntityType_ENTITY_TYPE_USER,
+			Conditions: []*searchpb.Condition{
+				{Name: "role", Value: "end-user"},
+				{Name: "organization_id", Value: "114"},
+			},
+		})
+		assert.Nil(t, err)
+		records, err := receiveAll(stream)
+		assert.Nil(t, err)
+		var ids []int64
+		for _, record := range records {
+			ids = append(ids, record.GetUser().GetId())
+		}
+		assert.Equal(t, []int64{707070707, 70}, ids)
+	})
+
+	t.Run("test search of tickets includes custom fields and related names", func(t *testing.T) {
+		stream, err := client.Search(ctx, &searchpb.SearchRequest{
+			Entity:     searchpb.EntityType_ENTITY_TYPE_TICKET,
+			Conditions: []*searchpb.Condition{{Name: "product", Value: "chat"}},
+		})
+		assert.Nil(t, err)
+		records, err := receiveAll(stream)
+		assert.Nil(t, err)
+		assert.Len(t, records, 1)
+		ticket := records[0].GetTicket()
+		assert.Equal(t, "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", ticket.GetId())
+		assert.NotEmpty(t, ticket.GetCustomFields())
+		assert.NotEmpty(t, ticket.GetOrganizationName())
+	})
+
+	t.Run("test search stops at the limit", func(t *testing.T) {
+		stream, err := client.Search(ctx, &searchpb.SearchRequest{
+			Entity:     searchpb.EntityType_ENTITY_TYPE_TICKET,
+			Conditions: []*searchpb.Condition{{Name: "status", Value: "pending"}},
+			Limit:      1,
+		})
+		assert.Nil(t, err)
+		records, err := receiveAll(stream)
+		assert.Nil(t, err)
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("test search of an unknown field is an invalid argument", func(t *testing.T) {
+		stream, err := client.Search(ctx, &searchpb.SearchRequest{
+			Entity:     searchpb.EntityType_ENTITY_TYPE_USER,
+			Conditions: []*searchpb.Condition{{Name: "nickname", Value: "abc"}},
+		})
+		assert.Nil(t, err)
+		_, err = receiveAll(stream)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("test search of an unspecified entity type is an invalid argument", func(t *testing.T) {
+		stream, err := client.Search(ctx, &searchpb.SearchRequest{})
+		assert.Nil(t, err)
+		_, err = receiveAll(stream)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("test search of an unknown entity type is not found", func(t *testing.T) {
+		stream, err := client.Search(ctx, &searchpb.SearchRequest{Entity: searchpb.EntityType(99)})
+		assert.Nil(t, err)
+		_, err = receiveAll(stream)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestGRPCGet(t *testing.T) {
+	client := grpcClient(t)
+	ctx := context.Background()
+
+	t.Run("test get returns an entity by its primary key", func(t *testing.T) {
+		record, err := client.Get(ctx, &searchpb.GetRequest{Entity: searchpb.EntityType_ENTITY_TYPE_USER, Id: "22"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(22), record.GetUser().GetId())
+		assert.Equal(t, "Moran Daniels", record.GetUser().GetName())
+		assert.NotEmpty(t, record.GetUser().GetTickets())
+	})
+
+	t.Run("test get of an unknown entity is not found", func(t *testing.T) {
+		_, err := client.Get(ctx, &searchpb.GetRequest{Entity: searchpb.EntityType_ENTITY_TYPE_USER, Id: "1"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("test get of an unknown entity type is not found", func(t *testing.T) {
+		_, err := client.Get(ctx, &searchpb.GetRequest{Entity: searchpb.EntityType(99), Id: "1"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestGRPCList(t *testing.T) {
+	client := grpcClient(t)
+	ctx := context.Background()
+
+	t.Run("test list streams all entities", func(t *testing.T) {
+		stream, err := client.List(ctx, &searchpb.ListRequest{Entity: searchpb.EntityType_ENTITY_TYPE_ORGANIZATION})
+		assert.Nil(t, err)
+		records, err := receiveAll(stream)
+		assert.Nil(t, err)
+		var ids []int64
+		for _, record := range records {
+			ids = append(ids, record.GetOrganization().GetId())
+		}
+		assert.Equal(t, []int64{919191919, 121, 102, 107, 114}, ids)
+	})
+
+	t.Run("test list stops at the limit", func(t *testing.T) {
+		stream, err := client.List(ctx, &searchpb.ListRequest{Entity: searchpb.EntityType_ENTITY_TYPE_ORGANIZATION, Limit: 2})
+		assert.Nil(t, err)
+		records, err := receiveAll(stream)
+		assert.Nil(t, err)
+		assert.Len(t, records, 2)
+	})
+}
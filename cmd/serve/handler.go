@@ -0,0 +1,27 @@
+// Package serve -
+//
+// Defines the command for serving search over HTTP, and which entrypoints to invoke for it
+//
+
+package serve
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewServeCmd - Define command serving search of all entities as a JSON API over HTTP /*
+func NewServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve search of all entities as a JSON API over HTTP",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return triggerServe(cmd)
+		},
+	}
+	cmd.Flags().String("addr", ":8080", "Address to listen on, eg. :8080 or 127.0.0.1:8080")
+	cmd.Flags().String("grpc-addr", "", "Address to serve search over gRPC on, eg. :9090, along with the JSON API")
+	cmd.Flags().Bool("graphql", false, "Serve GraphQL queries at /graphql, along with the JSON API")
+	cmd.Flags().Bool("watch", true, "Reload data when data files change")
+	cmd.Flags().Duration("poll-interval", 0, "Poll data files for changes at this interval (eg. 5s), instead of using file system events")
+	return cmd
+}
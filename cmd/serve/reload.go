@@ -0,0 +1,179 @@
+// Package serve -
+//
+// This file is meant for reloading the dataset of the server when its data files change, so that updated exports are
+// served without restarting. Changes are noticed through file system events (inotify etc. via fsnotify), or by
+// polling modification times of the files where events are not available. A dataset which fails to load is reported,
+// and the previous dataset is kept being served
+package serve
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"context"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultPollInterval - Interval of polling data files, where file system events are not available
+const DefaultPollInterval = 2 * time.Second
+
+// ReloadDelay - Time waited after a change before reloading, so that several changes (eg. of a file being written in
+// parts, or of several files being replaced) are reloaded once
+const ReloadDelay = 200 * time.Millisecond
+
+/*
+*		Paths of all files the dataset is loaded from
+ */
+func dataFiles() []string {
+	var paths []string
+	for _, entity := range models.Registry.All() {
+		paths = append(paths, internal.DataFilePath(entity.DataFile()))
+	}
+	return append(paths, internal.DataFilePath(internal.FieldDefinitionsFile))
+}
+
+/*
+*		Watch data files of the server until the context is done, reloading its dataset whenever they change. Files
+*		are polled every pollInterval if it is given, or if file system events are not available
+ */
+func Watch(ctx context.Context, server *Server, pollInterval time.Duration) {
+	files := dataFiles()
+	changes := make(chan struct{}, 1)
+	if pollInterval <= 0 {
+		err := watchEvents(ctx, files, changes)
+		if err != nil {
+			log.Warnf("File system events are not available, polling data files instead: %v", err)
+			pollInterval = DefaultPollInterval
+		}
+	}
+	if pollInterval > 0 {
+		go pollFiles(ctx, files, pollInterval, changes)
+	}
+	timer := time.NewTimer(ReloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-changes:
+			timer.Reset(ReloadDelay)
+		case <-timer.C:
+			Reload(server)
+		}
+	}
+}
+
+/*
+*		Reload the dataset of the server from its data files, swapping it in if it loads. Otherwise the error is logged,
+*		and the previous dataset is kept
+*
+*	    @return (bool): Whether the dataset was reloaded
+ */
+func Reload(server *Server) bool {
+	dataset, err := LoadDataset()
+	if err != nil {
+		log.Errorf("Reloading data failed, still serving previous data: %v", err)
+		return false
+	}
+	server.Swap(dataset)
+	log.Infof("Reloaded data")
+	return true
+}
+
+// Notify of a change, unless one is already pending
+func notify(changes chan<- struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+/*
+*		Watch file system events of the directories of the files, notifying of any event on the files. Directories are
+*		watched rather than the files, as files are often replaced (eg. renamed over) rather than written to
+ */
+func watchEvents(ctx context.Context, files []string, changes chan<- struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	watched := map[string]bool{}
+	for _, file := range files {
+		path, _ := filepath.Abs(file)
+		watched[path] = true
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			_ = watcher.Close()
+			return err
+		}
+	}
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if path, _ := filepath.Abs(event.Name); watched[path] && !event.Has(fsnotify.Chmod) {
+					log.Debugf("Data file changed: %v", event)
+					notify(changes)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Errorf("Encountered error while watching data files: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+/*
+*		Poll modification times and sizes of the files every interval, notifying of any file which changed, appeared or
+*		disappeared
+ */
+func pollFiles(ctx context.Context, files []string, interval time.Duration, changes chan<- struct{}) {
+	type fileState struct {
+		modified time.Time
+		size     int64
+		exists   bool
+	}
+	states := func() map[string]fileState {
+		all := map[string]fileState{}
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				all[file] = fileState{}
+				continue
+			}
+			all[file] = fileState{modified: info.ModTime(), size: info.Size(), exists: true}
+		}
+		return all
+	}
+	previous := states()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := states()
+			for file, state := range current {
+				if state != previous[file] {
+					log.Debugf("Data file changed: %v", file)
+					notify(changes)
+					break
+				}
+			}
+			previous = current
+		}
+	}
+}
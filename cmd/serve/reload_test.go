@@ -0,0 +1,85 @@
+package serve
+
+import (
+	"ZendeskChallenge/internal"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Copy test data files to a directory, and read data files from there
+func useDataDir(t *testing.T) string {
+	dir := t.TempDir()
+	files, _ := filepath.Glob(filepath.Join(testDataDir, "*.json"))
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		_ = os.WriteFile(filepath.Join(dir, filepath.Base(file)), data, 0o644)
+	}
+	t.Setenv(internal.DataDirEnv, dir)
+	return dir
+}
+
+// Number of users of the dataset being served
+func userCount(server *Server) int {
+	return len(server.Dataset().All("user"))
+}
+
+// Write users to the users data file, replacing the file as exports usually are
+func writeUsers(t *testing.T, dir string, users []map[string]any) {
+	data, _ := json.Marshal(users)
+	tmp := filepath.Join(dir, "users.json.tmp")
+	assert.Nil(t, os.WriteFile(tmp, data, 0o644))
+	assert.Nil(t, os.Rename(tmp, filepath.Join(dir, "users.json")))
+}
+
+func TestReload(t *testing.T) {
+	dir := useDataDir(t)
+	dataset, err := LoadDataset()
+	assert.Nil(t, err)
+	server := NewServer(dataset)
+	assert.Equal(t, 5, userCount(server))
+
+	t.Run("test invalid data is not reloaded, and previous data is still served", func(t *testing.T) {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "users.json"), []byte(`[{"_id": `), 0o644))
+		assert.False(t, Reload(server))
+		assert.Same(t, dataset, server.Dataset())
+	})
+	t.Run("test valid data is reloaded", func(t *testing.T) {
+		writeUsers(t, dir, []map[string]any{{"_id": 1, "name": "Ann"}})
+		assert.True(t, Reload(server))
+		assert.NotSame(t, dataset, server.Dataset())
+		assert.Equal(t, 1, userCount(server))
+	})
+}
+
+func TestWatch(t *testing.T) {
+	for _, tt := range []struct {
+		title        string
+		pollInterval time.Duration
+	}{
+		{title: "file system events", pollInterval: 0},
+		{title: "polling", pollInterval: 50 * time.Millisecond},
+	} {
+		t.Run("test data is reloaded when data files change, using "+tt.title, func(t *testing.T) {
+			dir := useDataDir(t)
+			dataset, err := LoadDataset()
+			assert.Nil(t, err)
+			server := NewServer(dataset)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go Watch(ctx, server, tt.pollInterval)
+			time.Sleep(100 * time.Millisecond) // Let watching start before changing files
+
+			writeUsers(t, dir, []map[string]any{{"_id": 1, "name": "Ann"}, {"_id": 2, "name": "Bob"}})
+			assert.Eventually(t, func() bool { return userCount(server) == 2 }, 5*time.Second, 20*time.Millisecond)
+
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, "tickets.json"), []byte(`not json`), 0o644))
+			time.Sleep(ReloadDelay + 300*time.Millisecond)
+			assert.Equal(t, 2, userCount(server), "previous data is still served")
+		})
+	}
+}
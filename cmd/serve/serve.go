@@ -0,0 +1,119 @@
+// Package serve -
+//
+// This is the entry point of the HTTP server. All entities are loaded once when the server starts and kept in memory
+// between requests. The server shuts down gracefully on interrupt, finishing requests in progress
+//
+
+package serve
+
+import (
+	"ZendeskChallenge/pkg/zsearch"
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ShutdownTimeout - Time given to requests in progress to finish, when shutting down
+const ShutdownTimeout = 10 * time.Second
+
+// Dataset - Data of all entities and definitions of custom fields, loaded once and kept in memory between requests,
+// along with indexes of it built for GraphQL queries
+type Dataset struct {
+	*zsearch.Dataset
+	indexes    map[string]map[string][]interface{}
+	indexMutex sync.Mutex
+}
+
+/*
+*		Load data of all registered entities, and the definitions of custom fields
+*
+*	    @return (*Dataset, error): All loaded data, and error if reading or parsing of any data file failed
+ */
+func LoadDataset() (*Dataset, error) {
+	dataset, err := zsearch.Load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &Dataset{Dataset: dataset}, nil
+}
+
+/*
+*		Trigger the server. Loads all data, then serves requests on --addr (and gRPC calls on --grpc-addr, if set)
+*		until interrupted, reloading data when its files change (unless --watch=false)
+*
+*	    @return (error): If any error occurs during loading of data, or listening on the address
+ */
+func triggerServe(cmd *cobra.Command) error {
+	addr, _ := cmd.Flags().GetString("addr")
+	dataset, err := LoadDataset()
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close() // Closed by the server once it shuts down, or here if serving fails to start
+	ctx := cmd.Context()   // Done once the CLI is interrupted
+	server := NewServer(dataset)
+	if enabled, _ := cmd.Flags().GetBool("graphql"); enabled {
+		if err := server.EnableGraphQL(); err != nil {
+			return err
+		}
+	}
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		pollInterval, _ := cmd.Flags().GetDuration("poll-interval")
+		go Watch(ctx, server, pollInterval)
+	}
+	if grpcAddr, _ := cmd.Flags().GetString("grpc-addr"); grpcAddr != "" {
+		grpcListener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return err
+		}
+		grpcServer := NewGRPCServer(server)
+		go func() {
+			log.Infof("Serving gRPC search on %v", grpcListener.Addr())
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				log.Errorf("Encountered error while serving gRPC: %v", err)
+			}
+		}()
+		defer grpcServer.GracefulStop() // Run returns once interrupted, then calls in progress are finished
+	}
+	log.Infof("Serving search on %v", listener.Addr())
+	return Run(ctx, listener, server.Handler())
+}
+
+/*
+*		Serve requests on a listener until the context is done, then shut down gracefully, giving requests in
+*		progress up to ShutdownTimeout to finish
+*
+*	    @return (error): If serving or shutting down failed
+ */
+func Run(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	log.Infof("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Infof("Server shut down")
+	return nil
+}
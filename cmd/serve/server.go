@@ -0,0 +1,233 @@
+// Package serve -
+//
+// This file is meant for handling requests to the JSON API, which searches entities the same way as the search
+// command does:
+//
+//   - GET /<entities>?<field>=<value>&...       entities matching all fields, eg. /users?role=admin&active=true
+//   - GET /<entities>/<id>                      entity by its primary key, eg. /tickets/436bf9b0-1147-4c0a-8439-6f79833bff5b
+//   - GET /<entities>/<id>/<related entities>   entities related to an entity, eg. /organizations/119/tickets
+//   - POST /graphql                             GraphQL queries, if enabled (see `graphql.go`)
+package serve
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"ZendeskChallenge/pkg/zsearch"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/graphql-go/graphql"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Server - Handles requests to the JSON API, searching a dataset kept in memory. The dataset is swapped as a whole
+// when it is reloaded, while requests in progress finish searching the dataset they started with
+type Server struct {
+	dataset atomic.Pointer[Dataset]
+	schema  *graphql.Schema // GraphQL schema, if the GraphQL endpoint is enabled
+}
+
+// NewServer - Server of a dataset
+func NewServer(dataset *Dataset) *Server {
+	server := &Server{}
+	server.dataset.Store(dataset)
+	return server
+}
+
+// Dataset - Dataset currently being served
+func (s *Server) Dataset() *Dataset {
+	return s.dataset.Load()
+}
+
+// EnableGraphQL - Serve the GraphQL endpoint, along with the JSON API
+func (s *Server) EnableGraphQL() error {
+	schema, err := NewGraphQLSchema()
+	if err != nil {
+		return err
+	}
+	s.schema = &schema
+	return nil
+}
+
+// Swap - Serve another dataset, from the next request onwards
+func (s *Server) Swap(dataset *Dataset) {
+	s.dataset.Store(dataset)
+}
+
+// Handler - Handler of all requests to the server, logging each request
+func (s *Server) Handler() http.Handler {
+	return logRequests(http.HandlerFunc(s.route))
+}
+
+/*
+*		Route a request by its path: /<entities>, /<entities>/<id> or /<entities>/<id>/<related entities>
+ */
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == GraphQLPath && s.schema != nil {
+		s.serveGraphQL(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("only GET requests are supported"))
+		return
+	}
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	entity, ok := entityByPath(segments[0])
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New(fmt.Sprintf("unknown entities %v", segments[0])))
+		return
+	}
+	conditions := queryConditions(r)
+	var result any
+	var err error
+	switch len(segments) {
+	case 1:
+		result, err = s.search(r.Context(), entity, conditions)
+	case 2:
+		result, err = s.get(r.Context(), entity, segments[1])
+	case 3:
+		related, ok := entityByPath(segments[2])
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New(fmt.Sprintf("unknown entities %v", segments[2])))
+			return
+		}
+		result, err = s.searchRelated(r.Context(), entity, segments[1], related, conditions)
+	default:
+		err = zsearch.ErrNotFound
+	}
+	if errors.Is(err, zsearch.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// Entity served at a path, which is the plural of its name (eg. users, tickets)
+func entityByPath(path string) (internal.Entity, bool) {
+	for _, entity := range models.Registry.All() {
+		if entity.EntityName()+"s" == path {
+			return entity, true
+		}
+	}
+	return nil, false
+}
+
+// Search flags for each query parameter, in order of their names
+func queryConditions(r *http.Request) []zsearch.Condition {
+	query := r.URL.Query()
+	var names []string
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var conditions []zsearch.Condition
+	for _, name := range names {
+		conditions = append(conditions, zsearch.Condition{Name: name, Value: query.Get(name)})
+	}
+	return conditions
+}
+
+// Search entities of the dataset currently being served, matching all conditions
+func (s *Server) search(ctx context.Context, entity internal.Entity, conditions []zsearch.Condition) ([]interface{}, error) {
+	return s.Dataset().Find(ctx, entity.EntityName(), conditions...)
+}
+
+// Get an entity of the dataset currently being served by its primary key
+func (s *Server) get(ctx context.Context, entity internal.Entity, id string) (interface{}, error) {
+	return s.Dataset().Get(ctx, entity.EntityName(), id)
+}
+
+/*
+*		Search entities related to an entity, matching all conditions. Related entities are found by the relationship
+*		of the entity to them (eg. tickets submitted by a user), or else by their field referencing the entity
+*		(eg. organization_id of tickets)
+*
+*	    @return ([]interface{}, error): Related entities, and error if the entity does not exist or is not related
+ */
+func (s *Server) searchRelated(ctx context.Context, entity internal.Entity, id string, related internal.Entity, conditions []zsearch.Condition) ([]interface{}, error) {
+	record, err := s.get(ctx, entity, id)
+	if err != nil {
+		return nil, err
+	}
+	localKey, foreignKey := entity.PrimaryKey(), entity.EntityName()+"_id"
+	for _, relation := range entity.Relations() {
+		if relation.Entity == related.EntityName() {
+			localKey, foreignKey = relation.LocalKey, relation.ForeignKey
+			break
+		}
+	}
+	if _, ok := related.Mappings()[foreignKey]; !ok {
+		return nil, errors.New(fmt.Sprintf("%v are not related to %v", related.EntityName()+"s", entity.EntityName()+"s"))
+	}
+	key := reflect.ValueOf(record).FieldByName(entity.Mappings()[localKey])
+	var keys []reflect.Value
+	if key.Kind() == reflect.Slice { // eg. agent_ids of a group
+		for i := 0; i < key.Len(); i++ {
+			keys = append(keys, key.Index(i))
+		}
+	} else {
+		keys = append(keys, key)
+	}
+	all := []interface{}{}
+	for _, k := range keys {
+		results, err := s.search(ctx, related, append([]zsearch.Condition{{Name: foreignKey, Value: fmt.Sprint(k.Interface())}}, conditions...))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, results...)
+	}
+	return all, nil
+}
+
+// Write a value as the JSON response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		log.Errorf("Encountered error while writing response: %v", err)
+	}
+}
+
+// Write an error as the JSON response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// statusRecorder - Response writer recording the status of the response, for logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Log method, path, status and duration of every request
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		log.WithFields(log.Fields{
+			"method":   r.Method,
+			"path":     r.URL.RequestURI(),
+			"status":   recorder.status,
+			"duration": time.Since(start),
+		}).Info("Request served")
+	})
+}
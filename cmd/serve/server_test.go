@@ -0,0 +1,180 @@
+package serve
+
+import (
+	"ZendeskChallenge/internal"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Directory of the test data shared by all packages, relative to the directory of a package
+var testDataDir = filepath.Join("..", "..", "testdata")
+
+type TestSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (suite *TestSuite) SetupSuite() {
+	suite.T().Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+	dataset, err := LoadDataset()
+	suite.Require().Nil(err)
+	server := NewServer(dataset)
+	suite.Require().Nil(server.EnableGraphQL())
+	suite.server = httptest.NewServer(server.Handler())
+}
+
+func (suite *TestSuite) TearDownSuite() {
+	suite.server.Close()
+}
+
+// Request a path of the server, and decode the JSON response
+func (suite *TestSuite) get(path string, response any) int {
+	resp, err := http.Get(suite.server.URL + path)
+	suite.Require().Nil(err)
+	defer resp.Body.Close()
+	suite.Equal("application/json", resp.Header.Get("Content-Type"))
+	suite.Require().Nil(json.NewDecoder(resp.Body).Decode(response))
+	return resp.StatusCode
+}
+
+// IDs of a list of entities in a JSON response
+func ids(entities []map[string]any) []any {
+	var all []any
+	for _, entity := range entities {
+		all = append(all, entity["_id"])
+	}
+	return all
+}
+
+func (suite *TestSuite) TestSearch() {
+	tests := []struct {
+		title string
+		path  string
+		ids   []any
+	}{
+		{title: "all entities", path: "/organizations", ids: []any{float64(919191919), float64(121), float64(102), float64(107), float64(114)}},
+		{title: "entities matching a field", path: "/users?role=end-user", ids: []any{float64(707070707), float64(70), float64(22)}},
+		{title: "entities matching all fields", path: "/users?role=end-user&organization_id=114", ids: []any{float64(707070707), float64(70)}},
+		{title: "entities matching none", path: "/users?role=admin&organization_id=114", ids: []any{}},
+		{title: "entities matching a custom field", path: "/tickets?product=chat", ids: []any{"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"}},
+		{title: "entities related by field", path: "/organizations/102/tickets", ids: []any{"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", "3ff0599a-fe0f-4f8f-ac31-e2636843bcea"}},
+		{title: "entities related by field, matching a field", path: "/organizations/114/users?_id=70", ids: []any{float64(70)}},
+		{title: "entities related by relationship", path: "/users/22/tickets", ids: []any{"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"}},
+		{title: "entities related by a list", path: "/groups/360000100/users", ids: []any{float64(43), float64(74)}},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.title, func() {
+			var entities []map[string]any
+			status := suite.get(tt.path, &entities)
+			suite.Equal(http.StatusOK, status)
+			all := ids(entities)
+			if all == nil {
+				all = []any{}
+			}
+			suite.Equal(tt.ids, all)
+		})
+	}
+}
+
+func (suite *TestSuite) TestGet() {
+	suite.Run("entity by primary key, along with its related entities", func() {
+		var entity map[string]any
+		status := suite.get("/users/22", &entity)
+		suite.Equal(http.StatusOK, status)
+		suite.Equal(float64(22), entity["_id"])
+		suite.Equal("Moran Daniels", entity["name"])
+		suite.NotEmpty(entity["tickets"])
+	})
+}
+
+func (suite *TestSuite) TestErrors() {
+	tests := []struct {
+		title  string
+		path   string
+		status int
+	}{
+		{title: "unknown entities", path: "/widgets", status: http.StatusNotFound},
+		{title: "unknown entity", path: "/users/1", status: http.StatusNotFound},
+		{title: "unknown related entities", path: "/users/22/widgets", status: http.StatusNotFound},
+		{title: "unrelated entities", path: "/users/22/ratings", status: http.StatusBadRequest},
+		{title: "unknown field", path: "/users?nickname=abc", status: http.StatusBadRequest},
+		{title: "invalid value", path: "/users?active=maybe", status: http.StatusBadRequest},
+		{title: "unknown path", path: "/organizations/102/tickets/1", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.title, func() {
+			var response map[string]string
+			status := suite.get(tt.path, &response)
+			suite.Equal(tt.status, status)
+			suite.NotEmpty(response["error"])
+		})
+	}
+	suite.Run("only GET requests", func() {
+		resp, err := http.Post(suite.server.URL+"/users", "application/json", nil)
+		suite.Require().Nil(err)
+		_ = resp.Body.Close()
+		suite.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
+
+func TestServer(t *testing.T) {
+	suite.Run(t, new(TestSuite))
+}
+
+func TestRun(t *testing.T) {
+	t.Run("test server shuts down gracefully when the context is done", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- Run(ctx, listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+		}()
+		resp, err := http.Get("http://" + listener.Addr().String())
+		assert.Nil(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		cancel()
+		select {
+		case err := <-done:
+			assert.Nil(t, err)
+		case <-time.After(ShutdownTimeout):
+			assert.Fail(t, "server did not shut down")
+		}
+	})
+}
+
+func TestTriggerServe(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+
+	t.Run("test address is released when the gRPC address can not be listened on", func(t *testing.T) {
+		free, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		addr := free.Addr().String()
+		_ = free.Close()
+		taken, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		defer taken.Close()
+
+		cmd := NewServeCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--addr", addr, "--grpc-addr", taken.Addr().String(), "--watch=false"})
+		assert.NotNil(t, cmd.ExecuteContext(context.Background()))
+		listener, err := net.Listen("tcp", addr)
+		if assert.Nil(t, err, "Address is listened on again") {
+			_ = listener.Close()
+		}
+	})
+}
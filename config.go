@@ -0,0 +1,58 @@
+// Package main -
+//
+// This file is meant for applying the config file of the user (--config) to every command, with the settings of the
+// profile selected by --profile (or current_profile of the config), see `internal/config.go`
+package main
+
+import (
+	"ZendeskChallenge/cmd/config"
+	"ZendeskChallenge/internal"
+	"github.com/spf13/cobra"
+)
+
+// addConfigFlags - Add the global config flags to the root command, inherited by all sub-commands
+func addConfigFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("config", "", "Config file of profiles (default config.yaml in the config directory of the user, eg. ~/.config/zsearch)")
+	cmd.PersistentFlags().String("profile", "", "Profile of the config file to use (default current_profile of the config, or default)")
+	cmd.PersistentFlags().String("color", "", "Color of results: auto (on a terminal, unless NO_COLOR is set), always or never (default auto)")
+	_ = cmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	_ = cmd.RegisterFlagCompletionFunc("color", cobra.FixedCompletions([]string{internal.ColorAuto, internal.ColorAlways, internal.ColorNever}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// Completion of --profile, by the profiles of the config file
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	path, err := internal.ConfigPath(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	config, err := internal.ReadConfig(path)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return config.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+/*
+*		Apply the profile selected for a command: data files are read from its data directory (unless DATA_DIR is
+*		set), and the profile is set on the context of the command for its other settings (eg. output, timezone).
+*		Commands of the config itself are run without a profile
+*
+*	    @return (error): UsageError if the selected profile is unknown, or error if the config file is invalid
+ */
+func applyConfig(cmd *cobra.Command) error {
+	for parent := cmd; parent != nil; parent = parent.Parent() {
+		if parent.Annotations[config.UnprofiledAnnotation] != "" {
+			return nil
+		}
+	}
+	profile, err := internal.LoadProfile(cmd)
+	if err != nil {
+		if name, _ := cmd.Flags().GetString("profile"); name != "" {
+			return &UsageError{Err: err}
+		}
+		return err
+	}
+	internal.SetDataDir(profile.DataDir)
+	cmd.SetContext(internal.WithProfile(cmd.Context(), profile))
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"ZendeskChallenge/internal"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test profiles of the config file are applied to every command, unless overridden by its flags
+
+func TestApplyConfig(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, "")
+	_ = os.Unsetenv(internal.DataDirEnv) // Data directory of the profile is used only if DATA_DIR is not set
+	t.Cleanup(func() { internal.SetDataDir("") })
+	path := filepath.Join(t.TempDir(), internal.ConfigFile)
+	dataDir, _ := filepath.Abs("testdata")
+	run := func(args ...string) (string, string, int) {
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		root := NewRootCmd()
+		root.SetOut(stdout)
+		root.SetErr(stderr)
+		exit := execute(root, append(args, "--config", path), stderr)
+		return stdout.String(), stderr.String(), exit
+	}
+
+	t.Run("test profiles are created by config commands", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"config", "set", "data_dir", dataDir, "--profile", "acme"},
+			{"config", "set", "output", "json", "--profile", "acme"},
+			{"config", "set", "fields.user", "_id,name", "--profile", "acme"},
+			{"config", "set", "current_profile", "acme"},
+		} {
+			_, stderr, exit := run(args...)
+			assert.Equal(t, ExitOK, exit, stderr)
+		}
+		stdout, _, _ := run("config", "get", "fields.user")
+		assert.Equal(t, "_id,name\n", stdout)
+	})
+	t.Run("test settings of the current profile are applied", func(t *testing.T) {
+		stdout, stderr, exit := run("search", "user", "--name", "_id", "--value", "22", "-q")
+		assert.Equal(t, ExitOK, exit, stderr)
+		assert.Equal(t, "[\n  {\n    \"_id\": 22,\n    \"name\": \"Moran Daniels\"\n  }\n]\n", stdout)
+	})
+	t.Run("test flags override settings of the profile", func(t *testing.T) {
+		stdout, _, exit := run("query", "user where _id = 22", "-q", "--output", "text", "--fields", "alias")
+		assert.Equal(t, ExitOK, exit)
+		assert.True(t, strings.HasSuffix(stdout, "alias: Miss Livingston\n"), stdout)
+	})
+	t.Run("test unknown profile is a usage error", func(t *testing.T) {
+		_, stderr, exit := run("search", "user", "--name", "_id", "--value", "22", "--profile", "globex")
+		assert.Equal(t, ExitUsage, exit)
+		assert.True(t, strings.HasPrefix(stderr, "Error: unknown profile globex, expected one of: default, acme"), stderr)
+	})
+	t.Run("test invalid settings are not set", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"config", "set", "data_dir", "missing"},
+			{"config", "set", "fields.user", "nmae"},
+			{"config", "set", "fields.widget", "_id"},
+			{"config", "set", "current_profile", "globex"},
+		} {
+			_, _, exit := run(args...)
+			assert.Equal(t, ExitUsage, exit, args)
+		}
+	})
+}
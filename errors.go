@@ -0,0 +1,204 @@
+// Package main -
+//
+// This file is meant for reporting errors of commands, as text or as JSON (--error-format json) for scripts, and for
+// exiting with a code by the kind of error, so that "no results" is told apart from an unknown field or corrupt data
+package main
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/pkg/zsearch"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// Exit codes of the CLI, by the kind of error a command failed with
+const (
+	ExitOK           = 0
+	ExitNoResults    = 1   // Search or query found nothing, or there is no entity with the key
+	ExitUsage        = 2   // Unknown command, or invalid flags or arguments
+	ExitInvalidField = 3   // Unknown entity or field
+	ExitInvalidValue = 4   // Value is not of the type of the field
+	ExitInvalidQuery = 5   // Syntax error in a query
+	ExitLoadFailure  = 6   // Data file could not be read or parsed
+	ExitInterrupted  = 130 // Interrupted by Ctrl-C (SIGINT) or SIGTERM before the command was done
+	ExitFailure      = 101 // Any other error
+)
+
+// Formats of errors, by --error-format
+const (
+	ErrorFormatText = "text"
+	ErrorFormatJSON = "json"
+)
+
+// UsageError - Error in usage of the CLI, eg. an unknown flag
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// errorKind - Kind of error, by the error it matches with errors.Is
+type errorKind struct {
+	err  error
+	code string // Code of the error in JSON
+	exit int
+}
+
+// Kinds of errors, in order they are matched, so that the cause of an error in a query comes before the query itself
+var errorKinds = []errorKind{
+	{err: context.Canceled, code: "interrupted", exit: ExitInterrupted},
+	{err: internal.ErrNoResults, code: "no_results", exit: ExitNoResults},
+	{err: zsearch.ErrNotFound, code: "not_found", exit: ExitNoResults},
+	{err: zsearch.ErrUnknownEntity, code: "unknown_entity", exit: ExitInvalidField},
+	{err: zsearch.ErrUnknownField, code: "unknown_field", exit: ExitInvalidField},
+	{err: zsearch.ErrInvalidValue, code: "invalid_value", exit: ExitInvalidValue},
+	{err: zsearch.ErrInvalidQuery, code: "invalid_query", exit: ExitInvalidQuery},
+	{err: zsearch.ErrLoad, code: "load_failed", exit: ExitLoadFailure},
+}
+
+// errorOutput - Error as reported in JSON
+type errorOutput struct {
+	Code        string   `json:"code"`
+	Message     string   `json:"message"`
+	ExitCode    int      `json:"exit_code"`
+	Column      int      `json:"column,omitempty"`      // Column of an error in a query
+	Suggestions []string `json:"suggestions,omitempty"` // Closest fields to an unknown field
+}
+
+/*
+*		Execute the root command with arguments, and report any error it fails with in the format of --error-format.
+*		Errors which come before any command runs (eg. of flags or arguments) are errors in usage. The context of the
+*		command is cancelled on Ctrl-C (SIGINT) or SIGTERM, so that loading and searching data stop
+*
+*	    @return (int): Exit code by the kind of error, or ExitOK
+ */
+func execute(root *cobra.Command, args []string, stderr io.Writer) int {
+	root.SetArgs(args)
+	root.SilenceErrors = true
+	root.SilenceUsage = true
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &UsageError{Err: err}
+	})
+	format := errorFormat(args)
+	if format == ErrorFormatJSON {
+		root.SetErr(io.Discard) // Hints of commands are for people, the error is reported in JSON instead
+	}
+	ran := false
+	var closer io.Closer           // Log file, once logging is configured
+	var stopProfiling func() error // Once profiling is started
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := cmd.ValidateRequiredFlags(); err != nil {
+			return &UsageError{Err: err} // Validated by cobra after this, but still before the command runs
+		}
+		if err := cmd.ValidateFlagGroups(); err != nil {
+			return &UsageError{Err: err}
+		}
+		if format != ErrorFormatText && format != ErrorFormatJSON {
+			return &UsageError{Err: fmt.Errorf("invalid --error-format %q, expected %v or %v", format, ErrorFormatText, ErrorFormatJSON)}
+		}
+		logFile, err := configureLogging(cmd)
+		if err != nil {
+			return err
+		}
+		closer = logFile
+		if err := applyConfig(cmd); err != nil {
+			return err
+		}
+		stopProfiling, err = startProfiling(cmd)
+		if err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop) // Once interrupted, interrupting again exits straight away
+	cmd, err := root.ExecuteContextC(ctx)
+	if stopProfiling != nil {
+		if profileErr := stopProfiling(); err == nil {
+			err = profileErr
+		}
+	}
+	if closer != nil {
+		_ = closer.Close()
+	}
+	if err == nil {
+		return ExitOK
+	}
+	if !ran && !errors.As(err, new(*UsageError)) {
+		err = &UsageError{Err: err}
+	}
+	output := describeError(err)
+	if format == ErrorFormatJSON {
+		encoded, _ := json.Marshal(output)
+		_, _ = fmt.Fprintln(stderr, string(encoded))
+		return output.ExitCode
+	}
+	if output.ExitCode == ExitNoResults && errors.Is(err, internal.ErrNoResults) {
+		return output.ExitCode // Results already say there are none
+	}
+	_, _ = fmt.Fprintf(stderr, "Error: %v\n", output.Message)
+	if output.ExitCode == ExitUsage {
+		_, _ = fmt.Fprintf(stderr, "Run '%v --help' for usage.\n", cmd.CommandPath())
+	}
+	return output.ExitCode
+}
+
+/*
+*		Format of errors by the --error-format flag in arguments, found before they are parsed so that errors in parsing
+*		them (eg. an unknown flag) are reported in it as well
+*
+*	    @return (string): Format of errors, ErrorFormatText if the flag is not given
+ */
+func errorFormat(args []string) string {
+	format := ErrorFormatText
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return format
+		case arg == "--error-format" && i+1 < len(args):
+			format = args[i+1]
+		case strings.HasPrefix(arg, "--error-format="):
+			format = strings.TrimPrefix(arg, "--error-format=")
+		}
+	}
+	return format
+}
+
+// Describe an error by its kind, along with its details (eg. column of an error in a query)
+func describeError(err error) errorOutput {
+	output := errorOutput{Code: "failure", Message: strings.TrimSpace(err.Error()), ExitCode: ExitFailure}
+	if errors.As(err, new(*UsageError)) {
+		output.Code, output.ExitCode = "usage", ExitUsage
+	}
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			output.Code, output.ExitCode = kind.code, kind.exit
+			break
+		}
+	}
+	var parseError *zsearch.ParseError
+	if errors.As(err, &parseError) {
+		output.Column = parseError.Column
+	}
+	var searchError *zsearch.SearchError
+	if errors.As(err, &searchError) {
+		output.Suggestions = searchError.Suggestions
+	}
+	return output
+}
@@ -0,0 +1,323 @@
+// Package internal -
+//
+// Defines the config file of the user, with named profiles of settings (eg. data directory, output format, timezone)
+// applied to every command, unless overridden by its flags. Config is read from config.yaml in the config directory
+// of the user, or from the file given by --config, eg.
+//
+//	current_profile: acme
+//	profiles:
+//	  acme:
+//	    data_dir: /srv/zendesk/acme
+//	    output: json
+//	    timezone: Australia/Melbourne
+//	    color: never
+//	    fields:
+//	      user: [_id, name, email]
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ConfigFile - Config file of the user, in the config directory of the user
+const ConfigFile = "config.yaml"
+
+// DefaultProfile - Profile used when none is selected by --profile or current_profile
+const DefaultProfile = "default"
+
+// Formats of displayed results, by --output
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputCSV  = "csv"
+)
+
+// OutputFormats - All formats of displayed results. Reports are displayed in any of them
+var OutputFormats = []string{OutputText, OutputJSON, OutputCSV}
+
+// ResultFormats - Formats results of searches and queries are displayed in
+var ResultFormats = []string{OutputText, OutputJSON}
+
+// Colors of displayed results, by --color
+const (
+	ColorAuto   = "auto" // Colored when displayed on a terminal, unless NO_COLOR is set
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Keys of settings of a profile, for `config get` and `config set`. Fields displayed for an entity are set by
+// fields.<entity>, eg. fields.user
+const (
+	KeyCurrentProfile = "current_profile"
+	KeyDataDir        = "data_dir"
+	KeyOutput         = "output"
+	KeyTimezone       = "timezone"
+	KeyColor          = "color"
+	KeyFields         = "fields"
+)
+
+// ConfigKeys - Keys of all settings, fields.<entity> standing for the fields of any entity
+var ConfigKeys = []string{KeyCurrentProfile, KeyDataDir, KeyOutput, KeyTimezone, KeyColor, KeyFields + ".<entity>"}
+
+// Config - Config file of the user, with its profiles by name
+type Config struct {
+	CurrentProfile string             `yaml:"current_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+// Profile - Settings applied to every command, unless overridden by its flags
+type Profile struct {
+	DataDir  string              `yaml:"data_dir,omitempty"` // Directory data files are read from, unless DATA_DIR is set
+	Output   string              `yaml:"output,omitempty"`   // Format of displayed results, text, json or csv
+	Timezone string              `yaml:"timezone,omitempty"` // Timezone to search dates and display timestamps in
+	Color    string              `yaml:"color,omitempty"`    // Color of displayed results, auto, always or never
+	Fields   map[string][]string `yaml:"fields,omitempty"`   // Fields displayed for each entity, all if not set
+}
+
+type profileKey struct{}
+
+/*
+*		Read a config file. A missing config file is an empty config, so that nothing has to be configured
+*
+*	    @return (*Config, error): Config, and error if the file could not be read or is invalid
+ */
+func ReadConfig(path string) (*Config, error) {
+	config := &Config{}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(raw, config); err != nil {
+		return nil, fmt.Errorf("invalid config file %v: %w", path, err)
+	}
+	return config, nil
+}
+
+// Write - Write the config to a file, see WriteFile
+func (c *Config) Write(path string) error {
+	raw, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, raw)
+}
+
+/*
+*		Name of the profile to use: the one given (by --profile), or the current profile of the config, or the default
+*		profile
+ */
+func (c *Config) ProfileName(name string) string {
+	switch {
+	case name != "":
+		return name
+	case c.CurrentProfile != "":
+		return c.CurrentProfile
+	default:
+		return DefaultProfile
+	}
+}
+
+/*
+*		Get a profile by its name, see ProfileName
+*
+*	    @return (Profile, error): Profile, empty if the default profile is not configured, and error if a profile
+*		selected by name is not configured
+ */
+func (c *Config) Profile(name string) (Profile, error) {
+	name = c.ProfileName(name)
+	profile, ok := c.Profiles[name]
+	if !ok && name != DefaultProfile {
+		return Profile{}, fmt.Errorf("unknown profile %v, expected one of: %v", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// ProfileNames - Names of all profiles of the config, the default profile first and the others sorted
+func (c *Config) ProfileNames() []string {
+	names := []string{DefaultProfile}
+	for name := range c.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+/*
+*		Get a setting of a profile by its key (eg. timezone, fields.user), or the current profile of the config
+*
+*	    @return (string, error): Value of the setting, empty if it is not set, and error if the key is unknown
+ */
+func (c *Config) Get(name, key string) (string, error) {
+	if key == KeyCurrentProfile {
+		return c.CurrentProfile, nil
+	}
+	profile := c.Profiles[c.ProfileName(name)]
+	switch key {
+	case KeyDataDir:
+		return profile.DataDir, nil
+	case KeyOutput:
+		return profile.Output, nil
+	case KeyTimezone:
+		return profile.Timezone, nil
+	case KeyColor:
+		return profile.Color, nil
+	}
+	if entity, ok := strings.CutPrefix(key, KeyFields+"."); ok && entity != "" {
+		return strings.Join(profile.Fields[entity], ","), nil
+	}
+	return "", unknownKey(key)
+}
+
+/*
+*		Set a setting of a profile by its key (eg. timezone, fields.user), or the current profile of the config. Fields
+*		are separated by commas, and an empty value unsets a setting
+*
+*	    @return (error): Error if the key is unknown, or the value is invalid for the key
+ */
+func (c *Config) Set(name, key, value string) error {
+	if key == KeyCurrentProfile {
+		if _, ok := c.Profiles[value]; !ok && value != "" && value != DefaultProfile {
+			return fmt.Errorf("unknown profile %v, expected one of: %v", value, strings.Join(c.ProfileNames(), ", "))
+		}
+		c.CurrentProfile = value
+		return nil
+	}
+	profile := c.Profiles[c.ProfileName(name)]
+	switch key {
+	case KeyDataDir:
+		profile.DataDir = value
+	case KeyOutput:
+		if err := ValidateOutput(value); err != nil && value != "" {
+			return err
+		}
+		profile.Output = value
+	case KeyTimezone:
+		if _, err := LoadTimezone(value); err != nil && value != "" {
+			return err
+		}
+		profile.Timezone = value
+	case KeyColor:
+		if err := ValidateColor(value); err != nil && value != "" {
+			return err
+		}
+		profile.Color = value
+	default:
+		entity, ok := strings.CutPrefix(key, KeyFields+".")
+		if !ok || entity == "" {
+			return unknownKey(key)
+		}
+		if profile.Fields == nil {
+			profile.Fields = map[string][]string{}
+		}
+		profile.Fields[entity] = SplitFields(value)
+		if value == "" {
+			delete(profile.Fields, entity)
+		}
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	c.Profiles[c.ProfileName(name)] = profile
+	return nil
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown config key %q, expected one of: %v", key, strings.Join(ConfigKeys, ", "))
+}
+
+// ValidateOutput - Error if a format of displayed results is not one of the formats, or of OutputFormats if none given
+func ValidateOutput(output string, formats ...string) error {
+	if len(formats) == 0 {
+		formats = OutputFormats
+	}
+	if !slices.Contains(formats, output) {
+		return fmt.Errorf("invalid output %q, expected one of: %v", output, strings.Join(formats, ", "))
+	}
+	return nil
+}
+
+// ValidateColor - Error if a color of displayed results is not auto, always or never
+func ValidateColor(color string) error {
+	if color != ColorAuto && color != ColorAlways && color != ColorNever {
+		return fmt.Errorf("invalid color %q, expected %v, %v or %v", color, ColorAuto, ColorAlways, ColorNever)
+	}
+	return nil
+}
+
+// SplitFields - Fields separated by commas, eg. _id, name,email
+func SplitFields(value string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+/*
+*		Path of the config file of a command: the file given by --config, or config.yaml in the config directory of the
+*		user
+*
+*	    @return (string, error): Path of the config file, and error if the config directory of the user is unknown
+ */
+func ConfigPath(cmd *cobra.Command) (string, error) {
+	if path, _ := cmd.Flags().GetString("config"); path != "" {
+		return path, nil
+	}
+	return ConfigFilePath(ConfigFile)
+}
+
+/*
+*		Load the profile of a command, selected by --profile, from its config file (see ConfigPath)
+*
+*	    @return (Profile, error): Profile, and error if the config file is invalid, or the selected profile is unknown
+ */
+func LoadProfile(cmd *cobra.Command) (Profile, error) {
+	path, err := ConfigPath(cmd)
+	if err != nil {
+		return Profile{}, nil // Nothing is configured without a config directory
+	}
+	config, err := ReadConfig(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	name, _ := cmd.Flags().GetString("profile")
+	return config.Profile(name)
+}
+
+// TimezoneOf - Timezone of a command, by its --tz flag if it is set, and otherwise by the profile of the user
+func TimezoneOf(cmd *cobra.Command) string {
+	if tz, _ := cmd.Flags().GetString("tz"); tz != "" {
+		return tz
+	}
+	return ProfileFrom(cmd.Context()).Timezone
+}
+
+// WithProfile - Context of a command, with the profile it is run with
+func WithProfile(ctx context.Context, profile Profile) context.Context {
+	return context.WithValue(ctx, profileKey{}, profile)
+}
+
+// ProfileFrom - Profile a command is run with, empty if none is set on its context
+func ProfileFrom(ctx context.Context) Profile {
+	if ctx == nil {
+		return Profile{}
+	}
+	profile, _ := ctx.Value(profileKey{}).(Profile)
+	return profile
+}
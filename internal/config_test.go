@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFile)
+
+	t.Run("Testing a missing config file is an empty config", func(t *testing.T) {
+		config, err := ReadConfig(path)
+		assert.Nil(t, err)
+		assert.Equal(t, &Config{}, config)
+	})
+	t.Run("Testing config is read as written", func(t *testing.T) {
+		config := &Config{CurrentProfile: "acme", Profiles: map[string]Profile{
+			"acme": {DataDir: "/srv/zendesk/acme", Output: OutputJSON, Fields: map[string][]string{"user": {"_id", "name"}}},
+		}}
+		assert.Nil(t, config.Write(path))
+		read, err := ReadConfig(path)
+		assert.Nil(t, err)
+		assert.Equal(t, config, read)
+	})
+	t.Run("Testing an invalid config file", func(t *testing.T) {
+		_ = os.WriteFile(path, []byte("profiles: [acme"), 0o600)
+		_, err := ReadConfig(path)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestConfig_Profile(t *testing.T) {
+	config := &Config{Profiles: map[string]Profile{
+		"acme":    {Timezone: "Australia/Melbourne"},
+		"default": {Output: OutputJSON},
+	}}
+
+	t.Run("Testing profile is selected by name, then current profile, then default", func(t *testing.T) {
+		profile, err := config.Profile("acme")
+		assert.Nil(t, err)
+		assert.Equal(t, "Australia/Melbourne", profile.Timezone)
+		profile, _ = config.Profile("")
+		assert.Equal(t, OutputJSON, profile.Output)
+		config.CurrentProfile = "acme"
+		profile, _ = config.Profile("")
+		assert.Equal(t, "Australia/Melbourne", profile.Timezone)
+	})
+	t.Run("Testing an unknown profile", func(t *testing.T) {
+		_, err := config.Profile("globex")
+		assert.Equal(t, "unknown profile globex, expected one of: default, acme", err.Error())
+		profile, err := (&Config{}).Profile(DefaultProfile)
+		assert.Nil(t, err, "Default profile needs no config")
+		assert.Equal(t, Profile{}, profile)
+	})
+}
+
+func TestConfig_GetSet(t *testing.T) {
+	config := &Config{}
+
+	t.Run("Testing settings are set on the selected profile", func(t *testing.T) {
+		assert.Nil(t, config.Set("acme", KeyTimezone, "Australia/Melbourne"))
+		assert.Nil(t, config.Set("acme", KeyFields+".user", "_id, name,email"))
+		assert.Nil(t, config.Set("", KeyOutput, OutputJSON))
+		assert.Equal(t, []string{"_id", "name", "email"}, config.Profiles["acme"].Fields["user"])
+
+		value, _ := config.Get("acme", KeyTimezone)
+		assert.Equal(t, "Australia/Melbourne", value)
+		value, _ = config.Get("acme", KeyFields+".user")
+		assert.Equal(t, "_id,name,email", value)
+		value, _ = config.Get("acme", KeyOutput)
+		assert.Equal(t, "", value)
+		value, _ = config.Get(DefaultProfile, KeyOutput)
+		assert.Equal(t, OutputJSON, value)
+	})
+	t.Run("Testing current profile must be a configured profile", func(t *testing.T) {
+		assert.NotNil(t, config.Set("", KeyCurrentProfile, "globex"))
+		assert.Nil(t, config.Set("", KeyCurrentProfile, "acme"))
+		value, _ := config.Get("", KeyTimezone)
+		assert.Equal(t, "Australia/Melbourne", value)
+	})
+	t.Run("Testing an empty value unsets a setting", func(t *testing.T) {
+		assert.Nil(t, config.Set("acme", KeyFields+".user", ""))
+		assert.NotContains(t, config.Profiles["acme"].Fields, "user")
+		assert.Nil(t, config.Set("", KeyTimezone, ""))
+		assert.Equal(t, "", config.Profiles["acme"].Timezone)
+	})
+	t.Run("Testing invalid keys and values", func(t *testing.T) {
+		assert.Equal(t, `unknown config key "theme", expected one of: current_profile, data_dir, output, timezone, color, fields.<entity>`, config.Set("", "theme", "dark").Error())
+		_, err := config.Get("", "fields.")
+		assert.NotNil(t, err)
+		assert.NotNil(t, config.Set("", KeyOutput, "yaml"))
+		assert.NotNil(t, config.Set("", KeyColor, "blue"))
+		assert.NotNil(t, config.Set("", KeyTimezone, "Mars/Olympus"))
+	})
+}
@@ -0,0 +1,55 @@
+// Package internal -
+//
+// Defines the generic model-helper structs, which implement DataStore and DataProcessor interfaces for any entity
+// struct, so that models only need to declare their fields.
+package internal
+
+import (
+	"encoding/json"
+)
+
+// Records - List of entities of any model. Implements DataStore
+type Records[T any] []T
+
+// Data - Raw, processed and filtered entities of any model. Implements DataProcessor
+type Data[T any] struct {
+	Raw       []byte
+	Processed Records[T]
+	Filtered  Records[T]
+}
+
+// Fetch - Get list of entities (usually after by Data#FetchFiltered method) is called
+func (r Records[T]) Fetch() []interface{} {
+	var all []interface{}
+	for _, entity := range r {
+		all = append(all, entity)
+	}
+	return all
+}
+
+// SetFiltered - Set the filtered list of entities, and return the parent struct (Data) they belong to
+func (d *Data[T]) SetFiltered(values any) (DataProcessor, error) {
+	var result Records[T]
+	jsonString, _ := json.Marshal(values)
+	err := json.Unmarshal(jsonString, &result)
+	if err != nil {
+		return nil, err
+	}
+	d.Filtered = result
+	return d, nil
+}
+
+// FetchFiltered - Get a filtered and processed list of entities (not raw bytes)
+func (d *Data[T]) FetchFiltered() DataStore {
+	return d.Filtered
+}
+
+// FetchProcessed - Get a processed list of entities (not raw bytes)
+func (d *Data[T]) FetchProcessed() []interface{} {
+	return d.Processed.Fetch()
+}
+
+// FetchRaw - Get the raw entity data
+func (d *Data[T]) FetchRaw() []byte {
+	return d.Raw
+}
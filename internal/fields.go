@@ -0,0 +1,126 @@
+// Package internal -
+//
+// Defines support for custom fields of entities (eg. custom_fields of tickets, user_fields of users), which are not
+// declared on the entity struct, along with the field definition file giving them friendly names and types. Also
+// allows fields of the data which are not declared on an entity struct to be preserved.
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// FieldDefinitionsFile - Optional data file with definitions of custom fields
+const FieldDefinitionsFile = "field_definitions.json"
+
+// Types of custom fields, used for validating and comparing searched values
+const (
+	FieldTypeString = "string"
+	FieldTypeInt    = "int"
+	FieldTypeBool   = "bool"
+	FieldTypeList   = "list"
+)
+
+// CustomFieldStore - Implemented by entities which have custom fields
+type CustomFieldStore interface {
+	// FetchCustomFields - Get values of all custom fields of the entity, keyed by their searchable name
+	FetchCustomFields() map[string]any
+}
+
+// FieldDefinition - Friendly name and type of a custom field of an entity
+type FieldDefinition struct {
+	Entity string `json:"entity"`
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+}
+
+// FieldDefinitions - All definitions from the field definition file
+type FieldDefinitions []FieldDefinition
+
+/*
+*		Load definitions of custom fields from the field definition file of a directory of data files (eg. DataDir).
+*		The file is optional, so no definitions are returned if it does not exist
+*
+*	    @return (FieldDefinitions, error): Definitions, and error if the file could not be read or is invalid
+ */
+func LoadFieldDefinitions(dir string) (FieldDefinitions, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, FieldDefinitionsFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var definitions FieldDefinitions
+	err = json.Unmarshal(raw, &definitions)
+	if err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// ForEntity - Get definitions of custom fields of an entity
+func (d FieldDefinitions) ForEntity(entity string) FieldDefinitions {
+	var definitions FieldDefinitions
+	for _, definition := range d {
+		if definition.Entity == entity {
+			definitions = append(definitions, definition)
+		}
+	}
+	return definitions
+}
+
+// Find - Get definition of a custom field of an entity, by either its searchable key or its friendly name
+func (d FieldDefinitions) Find(entity, name string) (FieldDefinition, bool) {
+	for _, definition := range d.ForEntity(entity) {
+		if definition.Key == name || definition.Name == name {
+			return definition, true
+		}
+	}
+	return FieldDefinition{}, false
+}
+
+// UnmarshalWithExtras - Unmarshal data into entity (pointer to a struct), and return all fields of the data which are
+// not declared on the struct, so that they can be preserved
+func UnmarshalWithExtras(data []byte, entity any) (map[string]json.RawMessage, error) {
+	err := json.Unmarshal(data, entity)
+	if err != nil {
+		return nil, err
+	}
+	var extras map[string]json.RawMessage
+	err = json.Unmarshal(data, &extras)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range JSONFieldNames(reflect.TypeOf(entity).Elem()) {
+		delete(extras, name)
+	}
+	if len(extras) == 0 {
+		return nil, nil
+	}
+	return extras, nil
+}
+
+// MarshalWithExtras - Marshal entity along with the preserved fields which are not declared on its struct
+func MarshalWithExtras(entity any, extras map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(entity)
+	if err != nil || len(extras) == 0 {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range extras {
+		if _, ok := all[name]; !ok {
+			all[name] = value
+		}
+	}
+	return json.Marshal(all)
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadFieldDefinitions(t *testing.T) {
+	t.Run("test field definitions are optional", func(t *testing.T) {
+		definitions, err := LoadFieldDefinitions(t.TempDir())
+		assert.Nil(t, err)
+		assert.Nil(t, definitions)
+	})
+	t.Run("test field definitions are loaded and found by key or friendly name", func(t *testing.T) {
+		definitions, err := LoadFieldDefinitions("testdata")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(definitions))
+		assert.Equal(t, FieldDefinitions{{Entity: "user", Key: "user_field.plan", Name: "plan", Type: FieldTypeString}}, definitions.ForEntity("user"))
+
+		definition, ok := definitions.Find("ticket", "product")
+		assert.True(t, ok)
+		assert.Equal(t, "custom_field.360001234", definition.Key)
+		definition, ok = definitions.Find("ticket", "custom_field.360001234")
+		assert.True(t, ok)
+		assert.Equal(t, "product", definition.Name)
+		_, ok = definitions.Find("user", "product") // Definitions belong to a single entity
+		assert.False(t, ok)
+	})
+}
+
+func TestUnmarshalWithExtras(t *testing.T) {
+	type entity struct {
+		Id      int    `json:"_id"`
+		Name    string `json:"name,omitempty"`
+		Derived string `json:",omitempty"`
+		Ignored string `json:"-"`
+	}
+	t.Run("test fields not declared on the struct are returned", func(t *testing.T) {
+		var e entity
+		extras, err := UnmarshalWithExtras([]byte(`{"_id": 1, "name": "one", "brand_id": 42, "Ignored": "x"}`), &e)
+		assert.Nil(t, err)
+		assert.Equal(t, entity{Id: 1, Name: "one"}, e)
+		assert.Equal(t, map[string]json.RawMessage{"brand_id": json.RawMessage("42"), "Ignored": json.RawMessage(`"x"`)}, extras)
+
+		extras, err = UnmarshalWithExtras([]byte(`{"_id": 1}`), &e)
+		assert.Nil(t, err)
+		assert.Nil(t, extras)
+
+		_, err = UnmarshalWithExtras([]byte(`[1]`), &e)
+		assert.NotNil(t, err)
+	})
+	t.Run("test fields not declared on the struct are marshalled back", func(t *testing.T) {
+		data, err := MarshalWithExtras(entity{Id: 1}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"_id":1}`, string(data))
+
+		data, err = MarshalWithExtras(entity{Id: 1}, map[string]json.RawMessage{"brand_id": json.RawMessage("42"), "_id": json.RawMessage("2")})
+		assert.Nil(t, err)
+		assert.Equal(t, `{"_id":1,"brand_id":42}`, string(data), "declared fields take precedence")
+	})
+}
@@ -0,0 +1,87 @@
+// Package internal -
+//
+// Defines how data files of the application are read, and how config files of the user are read and written
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DataDirEnv - Environment variable of the directory data files are read from, instead of the current directory
+const DataDirEnv = "DATA_DIR"
+
+// ConfigDirName - Directory of config files of the application, in the config directory of the user
+const ConfigDirName = "zsearch"
+
+// Directory data files are read from when DATA_DIR is not set, by the profile of the user, see SetDataDir
+var dataDir string
+
+// SetDataDir - Set the directory data files are read from when DATA_DIR is not set, or the current directory if empty
+func SetDataDir(dir string) {
+	dataDir = dir
+}
+
+/*
+*		Get directory data files are read from: DATA_DIR if it is set, then the data directory of the profile of the
+*		user, and otherwise the current directory (empty)
+ */
+func DataDir() string {
+	if dir := os.Getenv(DataDirEnv); dir != "" {
+		return dir
+	}
+	return dataDir
+}
+
+// DataFilePath - Get path of specific data file, in the directory data files are read from, see DataDir
+func DataFilePath(fileName string) string {
+	return filepath.Join(DataDir(), fileName)
+}
+
+/*
+*		Get file data of specific file being queried, from its DataFilePath
+*
+*	    @return ([]byte, error): Data content of file and error if reading caused issue (such as fs.PathError)
+ */
+func ReadDataFile(fileName string) ([]byte, error) {
+	return os.ReadFile(DataFilePath(fileName))
+}
+
+/*
+*		Get path of a config file of the user, in the config directory of the user: $XDG_CONFIG_HOME/zsearch (or
+*		~/.config/zsearch) on Linux, ~/Library/Application Support/zsearch on macOS and %AppData%/zsearch on Windows
+*
+*	    @return (string, error): Path of the config file, and error if the config directory of the user is unknown
+ */
+func ConfigFilePath(fileName string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ConfigDirName, fileName), nil
+}
+
+/*
+*		Write a file (eg. a config file, or an exported data file), creating its directory if needed. The file is written
+*		to a temporary file first and then renamed, so that it is never left half written
+*
+*	    @return (error): Error if the directory or the file could not be written
+ */
+func WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // Once renamed, there is nothing to remove
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadDataFile(t *testing.T) {
+	t.Run("Testing data directory causes data to be read from different sources", func(t *testing.T) {
+		data1, err := ReadDataFile(FieldDefinitionsFile)
+		assert.NotNil(t, err)
+		assert.Nil(t, data1)                        // Root data files not accessible in test execution
+		assert.IsType(t, (*fs.PathError)(nil), err) // Assert correct error type thrown
+		t.Setenv(DataDirEnv, "testdata")            // Set for using different file data source for tests
+		data2, err := ReadDataFile(FieldDefinitionsFile)
+		assert.Nil(t, err)
+		assert.NotNil(t, data2)
+		assert.NotEqual(t, data1, data2, "Data is read from different sources if data directory is unset/set")
+		assert.NotEmpty(t, data2, "Test data read is not empty")
+	})
+}
+
+func TestDataFilePath(t *testing.T) {
+	t.Run("Testing data directory takes precedence over data directory of the profile", func(t *testing.T) {
+		assert.Equal(t, "", DataDir())
+		assert.Equal(t, "users.json", DataFilePath("users.json"))
+		SetDataDir("/home/zendesk")
+		defer SetDataDir("")
+		assert.Equal(t, "/home/zendesk/users.json", DataFilePath("users.json"))
+		t.Setenv(DataDirEnv, "/srv/zendesk")
+		assert.Equal(t, "/srv/zendesk", DataDir())
+		assert.Equal(t, "/srv/zendesk/users.json", DataFilePath("users.json"))
+	})
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	t.Run("Testing config files are written to the config directory of the user", func(t *testing.T) {
+		path, err := ConfigFilePath("queries.json")
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(os.Getenv("XDG_CONFIG_HOME"), ConfigDirName, "queries.json"), path)
+		assert.Nil(t, WriteFile(path, []byte("[]")))
+		assert.Nil(t, WriteFile(path, []byte(`[{"name":"admins"}]`)), "Config file is replaced")
+		data, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, `[{"name":"admins"}]`, string(data))
+		entries, _ := os.ReadDir(filepath.Dir(path))
+		assert.Len(t, entries, 1, "No temporary files are left behind")
+	})
+}
@@ -4,16 +4,48 @@
 package internal
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
+	"os"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// ErrNoResults - Search (or query) of a command found nothing, which the command fails with after displaying results
+// so that scripts can tell it apart from a search which found something, by its exit code
+var ErrNoResults = errors.New("no results")
+
+// Escape sequences of colored output
+const (
+	colorBold  = "\033[1m"
+	colorKey   = "\033[36m"
+	colorReset = "\033[0m"
+)
+
+// DisplayOptions - How results are displayed, by the output flags of a command or the profile of the user
+type DisplayOptions struct {
+	Output string   // Format of results, OutputText (default), OutputJSON or OutputCSV (reports only)
+	Fields []string // Fields displayed in order, mapped or custom fields, or all fields if not set
+	Color  bool     // Whether names of fields are colored
+}
+
 // DisplayResults - Displays final result to user, for the command that they ran
 func DisplayResults(cmd *cobra.Command, results DataStore, keyMappings map[string]string) {
-	cmd.Print("======== All results ========\n")
+	DisplayResultsWith(cmd, results, keyMappings, DisplayOptions{})
+}
+
+// DisplayResultsWith - Displays final result to user, as the options say (eg. as JSON, or only some of their fields)
+func DisplayResultsWith(cmd *cobra.Command, results DataStore, keyMappings map[string]string, options DisplayOptions) {
+	if options.Output == OutputJSON {
+		displayJSON(cmd, results, keyMappings, options.Fields)
+		return
+	}
+	cmd.Print(options.colored(colorBold, "======== All results ========") + "\n")
 	if results == nil {
 		cmd.Print("Nothing to display")
 		return
@@ -22,19 +54,171 @@ func DisplayResults(cmd *cobra.Command, results DataStore, keyMappings map[strin
 		cmd.Print("------------------------------------------------\n")
 		var outputString = ""
 		r := reflect.ValueOf(entity)
-		for key, val := range keyMappings {
-			field := r.FieldByName(val)
-			switch r.FieldByName(val).Kind() {
-			case reflect.Slice:
-				for i := 0; i < field.Len(); i++ {
-					outputString += fmt.Sprintf("%v: %v\n", strings.Join([]string{key, strconv.Itoa(i)}, "_"), field.Index(i))
+		store, custom := entity.(CustomFieldStore)
+		if len(options.Fields) > 0 {
+			for _, key := range options.Fields {
+				if val, ok := keyMappings[key]; ok {
+					outputString += options.fieldOutput(key, r.FieldByName(val))
+				} else if custom {
+					outputString += options.customFieldsOutput(store.FetchCustomFields(), key)
 				}
-				break
-			default:
-				outputString += fmt.Sprintf("%v: %v\n", key, r.FieldByName(val))
-				break
 			}
+			cmd.Print(outputString)
+			continue
+		}
+		for key, val := range keyMappings {
+			outputString += options.fieldOutput(key, r.FieldByName(val))
+		}
+		if custom {
+			outputString += options.customFieldsOutput(store.FetchCustomFields())
 		}
 		cmd.Print(outputString)
 	}
 }
+
+// Output of a field of an entity, a line for each of its values if it is a list
+func (o DisplayOptions) fieldOutput(key string, field reflect.Value) string {
+	var outputString = ""
+	switch field.Kind() {
+	case reflect.Slice:
+		for i := 0; i < field.Len(); i++ {
+			outputString += fmt.Sprintf("%v: %v\n", o.colored(colorKey, strings.Join([]string{key, strconv.Itoa(i)}, "_")), field.Index(i))
+		}
+	default:
+		outputString += fmt.Sprintf("%v: %v\n", o.colored(colorKey, key), field)
+	}
+	return outputString
+}
+
+// customFieldsOutput - Output of custom fields of an entity (all, unless only some are given), sorted by their
+// searchable name
+func (o DisplayOptions) customFieldsOutput(fields map[string]any, only ...string) string {
+	var keys []string
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	if len(only) > 0 {
+		keys = only
+	}
+	sort.Strings(keys)
+	var outputString = ""
+	for _, key := range keys {
+		values, ok := fields[key]
+		if !ok {
+			continue
+		}
+		switch values := values.(type) {
+		case []any:
+			for i, value := range values {
+				outputString += fmt.Sprintf("%v: %v\n", o.colored(colorKey, strings.Join([]string{key, strconv.Itoa(i)}, "_")), value)
+			}
+		case nil:
+			outputString += fmt.Sprintf("%v: \n", o.colored(colorKey, key))
+		default:
+			outputString += fmt.Sprintf("%v: %v\n", o.colored(colorKey, key), values)
+		}
+	}
+	return outputString
+}
+
+// Text in a color, if output is colored
+func (o DisplayOptions) colored(color, text string) string {
+	if !o.Color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+/*
+*		Display results as a JSON array of entities, in the schema of their data files (along with related entities).
+*		If fields are given, entities only have those fields, custom fields by their searchable name
+ */
+func displayJSON(cmd *cobra.Command, results DataStore, keyMappings map[string]string, fields []string) {
+	entities := []any{}
+	if results != nil {
+		for _, entity := range results.Fetch() {
+			entities = append(entities, selectFields(entity, keyMappings, fields))
+		}
+	}
+	raw, err := json.MarshalIndent(entities, "", "  ")
+	if err != nil {
+		cmd.PrintErrln(fmt.Sprintf("Could not display results as JSON: %v", err))
+		return
+	}
+	cmd.Print(string(raw) + "\n")
+}
+
+// Entity with only the given fields, or the entity itself if no fields are given
+func selectFields(entity any, keyMappings map[string]string, fields []string) any {
+	if len(fields) == 0 {
+		return entity
+	}
+	selected := map[string]any{}
+	r := reflect.ValueOf(entity)
+	store, custom := entity.(CustomFieldStore)
+	for _, key := range fields {
+		if val, ok := keyMappings[key]; ok {
+			selected[key] = r.FieldByName(val).Interface()
+		} else if custom {
+			if value, ok := store.FetchCustomFields()[key]; ok {
+				selected[key] = value
+			}
+		}
+	}
+	return selected
+}
+
+/*
+*		Options of displaying results of an entity (or a report, with no entity), by the --output, --fields and --color
+*		flags of a command if they are set, and otherwise by the profile of the user. The output of the profile is only
+*		used if it is one of the formats the command displays results in (eg. csv, which only reports are displayed
+*		in). Output is colored (with --color auto) only on a terminal, unless NO_COLOR is set
+*
+*	    @return (DisplayOptions, error): Options, and error if any of them is invalid (eg. an unknown field)
+ */
+func ResolveDisplayOptions(cmd *cobra.Command, entity string, keyMappings map[string]string, formats []string) (DisplayOptions, error) {
+	profile := ProfileFrom(cmd.Context())
+	options := DisplayOptions{Output: profile.Output, Fields: profile.Fields[entity]}
+	if !slices.Contains(formats, options.Output) {
+		options.Output = OutputText
+	}
+	if cmd.Flags().Changed("output") {
+		options.Output, _ = cmd.Flags().GetString("output")
+	}
+	if err := ValidateOutput(options.Output, formats...); err != nil {
+		return options, err
+	}
+	if cmd.Flags().Changed("fields") {
+		value, _ := cmd.Flags().GetString("fields")
+		options.Fields = SplitFields(value)
+	}
+	for _, field := range options.Fields {
+		if _, ok := keyMappings[field]; !ok && !strings.Contains(field, ".") { // Custom fields are named <type>_field.<name>
+			return options, fmt.Errorf("unknown field %v of %vs to display, see 'list' command for fields", field, entity)
+		}
+	}
+	color := profile.Color
+	if cmd.Flags().Changed("color") {
+		color, _ = cmd.Flags().GetString("color")
+	}
+	switch color {
+	case ColorAlways:
+		options.Color = true
+	case ColorAuto, "":
+		options.Color = os.Getenv("NO_COLOR") == "" && isTerminal(cmd.OutOrStdout())
+	default:
+		return options, ValidateColor(color)
+	}
+	options.Color = options.Color && options.Output == OutputText
+	return options, nil
+}
+
+// Whether output is written to a terminal
+func isTerminal(output any) bool {
+	file, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
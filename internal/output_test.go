@@ -2,6 +2,7 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"strings"
@@ -21,3 +22,82 @@ func TestDisplayResults(t *testing.T) {
 		assert.True(t, strings.HasPrefix(buffer.String(), "======== All results ========"))
 	})
 }
+
+// Entity displayed by tests, as models cannot be imported here
+type displayed struct {
+	ID   int      `json:"_id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func TestDisplayResultsWith(t *testing.T) {
+	results := Records[displayed]{{ID: 1, Name: "Francisca Rasmussen", Tags: []string{"Springville", "Sutton"}}}
+	keyMappings := map[string]string{"_id": "ID", "name": "Name", "tags": "Tags"}
+	display := func(options DisplayOptions) string {
+		buffer := new(bytes.Buffer) // Redirecting output to custom buffer for testability.
+		cmd := cobra.Command{}      // Dummy command
+		cmd.SetOut(buffer)
+		DisplayResultsWith(&cmd, results, keyMappings, options)
+		return buffer.String()
+	}
+
+	t.Run("test only fields given are displayed, in order", func(t *testing.T) {
+		output := display(DisplayOptions{Fields: []string{"tags", "_id"}})
+		assert.True(t, strings.HasSuffix(output, "tags_0: Springville\ntags_1: Sutton\n_id: 1\n"))
+	})
+	t.Run("test results displayed as JSON", func(t *testing.T) {
+		assert.Equal(t, "[\n  {\n    \"_id\": 1,\n    \"name\": \"Francisca Rasmussen\",\n    \"tags\": [\n      \"Springville\",\n      \"Sutton\"\n    ]\n  }\n]\n", display(DisplayOptions{Output: OutputJSON}))
+		assert.Equal(t, "[\n  {\n    \"name\": \"Francisca Rasmussen\"\n  }\n]\n", display(DisplayOptions{Output: OutputJSON, Fields: []string{"name"}}))
+	})
+	t.Run("test colored output", func(t *testing.T) {
+		output := display(DisplayOptions{Fields: []string{"name"}, Color: true})
+		assert.True(t, strings.HasPrefix(output, "\033[1m======== All results ========\033[0m\n"))
+		assert.True(t, strings.HasSuffix(output, "\033[36mname\033[0m: Francisca Rasmussen\n"))
+	})
+}
+
+func TestResolveDisplayOptions(t *testing.T) {
+	keyMappings := map[string]string{"_id": "ID", "name": "Name"}
+	newCmd := func(profile Profile, args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("output", OutputText, "")
+		cmd.Flags().String("fields", "", "")
+		cmd.Flags().String("color", "", "")
+		_ = cmd.Flags().Parse(args)
+		cmd.SetContext(WithProfile(context.Background(), profile))
+		return cmd
+	}
+
+	t.Run("test options of the profile, unless flags are set", func(t *testing.T) {
+		profile := Profile{Output: OutputJSON, Color: ColorAlways, Fields: map[string][]string{"user": {"name"}}}
+		options, err := ResolveDisplayOptions(newCmd(profile), "user", keyMappings, ResultFormats)
+		assert.Nil(t, err)
+		assert.Equal(t, DisplayOptions{Output: OutputJSON, Fields: []string{"name"}}, options, "JSON is never colored")
+
+		options, err = ResolveDisplayOptions(newCmd(profile, "--output", "text", "--fields", "_id,organization_field.region"), "user", keyMappings, ResultFormats)
+		assert.Nil(t, err)
+		assert.Equal(t, DisplayOptions{Output: OutputText, Fields: []string{"_id", "organization_field.region"}, Color: true}, options)
+	})
+	t.Run("test output of the profile which the command does not display results in", func(t *testing.T) {
+		options, err := ResolveDisplayOptions(newCmd(Profile{Output: OutputCSV}), "user", keyMappings, ResultFormats)
+		assert.Nil(t, err)
+		assert.Equal(t, OutputText, options.Output)
+		options, err = ResolveDisplayOptions(newCmd(Profile{Output: OutputCSV}), "", nil, OutputFormats)
+		assert.Nil(t, err)
+		assert.Equal(t, OutputCSV, options.Output)
+	})
+	t.Run("test output is not colored unless on a terminal", func(t *testing.T) {
+		options, _ := ResolveDisplayOptions(newCmd(Profile{}), "user", keyMappings, ResultFormats)
+		assert.Equal(t, DisplayOptions{Output: OutputText}, options)
+	})
+	t.Run("test invalid options", func(t *testing.T) {
+		_, err := ResolveDisplayOptions(newCmd(Profile{}, "--fields", "email"), "user", keyMappings, ResultFormats)
+		assert.Equal(t, "unknown field email of users to display, see 'list' command for fields", err.Error())
+		_, err = ResolveDisplayOptions(newCmd(Profile{}, "--output", "yaml"), "user", keyMappings, ResultFormats)
+		assert.NotNil(t, err)
+		_, err = ResolveDisplayOptions(newCmd(Profile{}, "--output", "csv"), "user", keyMappings, ResultFormats)
+		assert.Equal(t, `invalid output "csv", expected one of: text, json`, err.Error())
+		_, err = ResolveDisplayOptions(newCmd(Profile{Color: "blue"}), "user", keyMappings, ResultFormats)
+		assert.NotNil(t, err)
+	})
+}
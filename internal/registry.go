@@ -0,0 +1,142 @@
+// Package internal -
+//
+// Defines the generic entity model and the registry of all models. A model declares its struct, data file, key field,
+// displayed fields and relationships once, and every command (search, list) is derived from that declaration.
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Relationship - Declares how an entity is enriched with a field of a related entity.
+//
+// Every entity whose LocalKey matches the ForeignKey of a related entity gets the Display field of the related
+// entity copied into Field. If Field is a list, all matching related entities are collected, otherwise the first one
+// is used. All fields are JSON field names.
+type Relationship struct {
+	Field      string // Field of the entity that is set with related details
+	Entity     string // Name of the related entity
+	LocalKey   string // Field of the entity that refers to the related entity
+	ForeignKey string // Field of the related entity matched against LocalKey
+	Display    string // Field of the related entity copied into Field
+}
+
+// Model - Declaration of an entity backed by struct T. Implements Entity
+type Model[T any] struct {
+	Name          string
+	File          string
+	KeyField      string
+	KeyMappings   map[string]string
+	Relationships []Relationship
+	CustomPrefix  string // Prefix of searchable names of custom fields, if T implements CustomFieldStore
+}
+
+// EntityName - Name of the entity, as used by CLI sub-commands
+func (m Model[T]) EntityName() string {
+	return m.Name
+}
+
+// DataFile - Name of the JSON file the entity is loaded from
+func (m Model[T]) DataFile() string {
+	return m.File
+}
+
+// PrimaryKey - JSON field which uniquely identifies each entity
+func (m Model[T]) PrimaryKey() string {
+	return m.KeyField
+}
+
+// Mappings - Mapping of JSON field names to struct field names
+func (m Model[T]) Mappings() map[string]string {
+	return m.KeyMappings
+}
+
+// Relations - Relationships used to enrich the entity
+func (m Model[T]) Relations() []Relationship {
+	return m.Relationships
+}
+
+// CustomFieldPrefix - Prefix of searchable names of custom fields, empty if the entity has no custom fields
+func (m Model[T]) CustomFieldPrefix() string {
+	return m.CustomPrefix
+}
+
+// FieldType - Type of a mapped field, nil if the field is not mapped
+func (m Model[T]) FieldType(field string) reflect.Type {
+	structField, ok := reflect.TypeOf((*T)(nil)).Elem().FieldByName(m.KeyMappings[field])
+	if !ok {
+		return nil
+	}
+	return structField.Type
+}
+
+// SearchableFields - All mapped fields, except the ones filled in from related entities (which are not in the data)
+func (m Model[T]) SearchableFields() []string {
+	related := map[string]bool{}
+	for _, relation := range m.Relationships {
+		related[relation.Field] = true
+	}
+	var fields []string
+	for field := range m.KeyMappings {
+		if !related[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Load - Parse raw JSON data into Data for the entity. An empty list has no entities, but fails if it is not a list
+func (m Model[T]) Load(raw []byte) (DataProcessor, error) {
+	var records Records[T]
+	err := json.Unmarshal(raw, &records)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		return nil, fmt.Errorf("no list of %v entities found in %v", m.Name, m.File)
+	}
+	return &Data[T]{
+		Raw:       raw,
+		Processed: records,
+	}, nil
+}
+
+// Registry - All entities known to the application, in the order they were registered
+type Registry struct {
+	entities []Entity
+	byName   map[string]Entity
+}
+
+// NewRegistry - Create a registry of the given entities
+func NewRegistry(entities ...Entity) *Registry {
+	registry := &Registry{byName: map[string]Entity{}}
+	for _, entity := range entities {
+		registry.entities = append(registry.entities, entity)
+		registry.byName[entity.EntityName()] = entity
+	}
+	return registry
+}
+
+// All - Get all registered entities
+func (r *Registry) All() []Entity {
+	return r.entities
+}
+
+// Get - Get a registered entity by its name
+func (r *Registry) Get(name string) (Entity, bool) {
+	entity, ok := r.byName[name]
+	return entity, ok
+}
+
+// Names - Get names of all registered entities, in the order they were registered
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.entities))
+	for i, entity := range r.entities {
+		names[i] = entity.EntityName()
+	}
+	return names
+}
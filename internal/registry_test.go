@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testEntity struct {
+	Id       int    `json:"_id"`
+	Name     string `json:"name"`
+	ParentId int    `json:"parent_id"`
+	Parent   string `json:",omitempty"`
+}
+
+var testModel = Model[testEntity]{
+	Name:        "test",
+	File:        "test.json",
+	KeyField:    "_id",
+	KeyMappings: map[string]string{"_id": "Id", "name": "Name", "parent_id": "ParentId", "parent": "Parent"},
+	Relationships: []Relationship{
+		{Field: "parent", Entity: "test", LocalKey: "parent_id", ForeignKey: "_id", Display: "name"},
+	},
+}
+
+func TestModel(t *testing.T) {
+	t.Run("test model exposes its declaration", func(t *testing.T) {
+		assert.Equal(t, "test", testModel.EntityName())
+		assert.Equal(t, "test.json", testModel.DataFile())
+		assert.Equal(t, "_id", testModel.PrimaryKey())
+		assert.Equal(t, []string{"_id", "name", "parent_id"}, testModel.SearchableFields(), "related fields are not searchable")
+	})
+	t.Run("test model loads data", func(t *testing.T) {
+		raw := []byte(`[{"_id": 1, "name": "one"}, {"_id": 2, "name": "two", "parent_id": 1}]`)
+		data, err := testModel.Load(raw)
+		assert.Nil(t, err)
+		assert.Equal(t, raw, data.FetchRaw())
+		assert.Equal(t, []interface{}{testEntity{Id: 1, Name: "one"}, testEntity{Id: 2, Name: "two", ParentId: 1}}, data.FetchProcessed())
+		assert.Nil(t, data.FetchFiltered())
+	})
+	t.Run("test model loads an empty list of entities", func(t *testing.T) {
+		data, err := testModel.Load([]byte(`[]`))
+		assert.Nil(t, err)
+		assert.Empty(t, data.FetchProcessed())
+	})
+	t.Run("test model fails to load invalid data", func(t *testing.T) {
+		data, err := testModel.Load([]byte(`{"_id": 1}`))
+		assert.NotNil(t, err)
+		assert.Nil(t, data)
+		data, err = testModel.Load([]byte(`null`))
+		assert.Equal(t, "no list of test entities found in test.json", err.Error())
+		assert.Nil(t, data)
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("test registry keeps entities in order and finds them by name", func(t *testing.T) {
+		other := Model[testEntity]{Name: "other"}
+		registry := NewRegistry(testModel, other)
+		assert.Equal(t, []Entity{testModel, other}, registry.All())
+		entity, ok := registry.Get("other")
+		assert.True(t, ok)
+		assert.Equal(t, other, entity)
+		_, ok = registry.Get("missing")
+		assert.False(t, ok)
+	})
+}
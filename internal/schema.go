@@ -0,0 +1,53 @@
+// Package internal -
+//
+// Derives the displayed and searchable fields of an entity, and their types, from the json tags of its struct, so that
+// the struct is the single source of truth for all of them.
+package internal
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaTag - Struct tag which leaves a field out of the displayed and searchable fields with `schema:"-"`, for fields
+// which are displayed and searched for differently (eg. custom fields)
+const SchemaTag = "schema"
+
+// FieldMappings - Mapping of JSON field names to struct field names of T, derived from its json tags. Fields which are
+// not in JSON, or are tagged with `schema:"-"`, are left out
+func FieldMappings[T any]() map[string]string {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	mappings := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok || field.Tag.Get(SchemaTag) == "-" {
+			continue
+		}
+		mappings[name] = field.Name
+	}
+	return mappings
+}
+
+// JSONFieldNames - Names of all fields of a struct type, as they appear in JSON
+func JSONFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := jsonFieldName(t.Field(i)); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Name of a struct field as it appears in JSON, and whether it appears in JSON at all
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" || !field.IsExported() {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
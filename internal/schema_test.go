@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type schemaEntity struct {
+	Id         int            `json:"_id"`
+	Name       string         `json:"name,omitempty"`
+	Derived    string         `json:",omitempty"`
+	Custom     map[string]any `json:"custom" schema:"-"`
+	Ignored    string         `json:"-"`
+	unexported string
+	Extra      map[string]string `json:"-"`
+}
+
+func TestFieldMappings(t *testing.T) {
+	t.Run("test mappings are derived from json tags", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"_id": "Id", "name": "Name", "Derived": "Derived"}, FieldMappings[schemaEntity]())
+	})
+	t.Run("test JSON field names include fields left out of mappings", func(t *testing.T) {
+		assert.Equal(t, []string{"_id", "name", "Derived", "custom"}, JSONFieldNames(reflect.TypeOf(schemaEntity{})))
+	})
+	t.Run("test field types are declared by the struct", func(t *testing.T) {
+		model := Model[schemaEntity]{KeyMappings: FieldMappings[schemaEntity]()}
+		assert.Equal(t, reflect.TypeOf(0), model.FieldType("_id"))
+		assert.Equal(t, reflect.TypeOf(""), model.FieldType("name"))
+		assert.Nil(t, model.FieldType("custom"))
+	})
+}
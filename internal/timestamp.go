@@ -0,0 +1,155 @@
+// Package internal -
+//
+// Defines the Timestamp type for date/time fields of entities (eg. created_at), which are stored as strings like
+// "2016-04-15T05:19:46 -10:00" in the data, along with converting them to a user-selected timezone for display
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+	_ "time/tzdata" // Timezones are loaded even where the system has no timezone database
+)
+
+// TimestampLayout - Layout of all date/time fields in the data
+const TimestampLayout = "2006-01-02T15:04:05 -07:00"
+
+// Timestamp - Date/time field of an entity, which keeps the offset it was stored with. A missing, null or empty field
+// is the zero Timestamp
+type Timestamp struct {
+	time.Time
+}
+
+// Offset of a timezone in hours and minutes, eg. +10:00 or -0930
+var offsetPattern = regexp.MustCompile(`^([+-])(\d{2}):?(\d{2})$`)
+
+// Durations in a single unit, eg. 30d or 2w
+var durationPattern = regexp.MustCompile(`^(\d+)\s*([smhdw])$`)
+
+// DurationUnits - Duration of each unit of durations given by users (seconds, minutes, hours, days and weeks)
+var DurationUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// UnmarshalJSON - Parse a timestamp from a JSON string in TimestampLayout
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var value *string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
+	if value == nil || *value == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	parsed, err := time.Parse(TimestampLayout, *value)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q, expected format %q", *value, TimestampLayout)
+	}
+	t.Time = parsed
+	return nil
+}
+
+// MarshalJSON - Format a timestamp as a JSON string in TimestampLayout, or null if it is zero
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String())
+}
+
+// String - Format a timestamp in TimestampLayout, or as an empty string if it is zero
+func (t Timestamp) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(TimestampLayout)
+}
+
+/*
+*		Parse a duration given by users, either in a single unit of DurationUnits (eg. 30d, 2w) or as accepted by
+*		time.ParseDuration (eg. 1h30m)
+*
+*	    @return (time.Duration, error): Duration, and error if it is in neither format
+ */
+func ParseDuration(value string) (time.Duration, error) {
+	if match := durationPattern.FindStringSubmatch(value); match != nil {
+		amount, _ := strconv.Atoi(match[1])
+		return time.Duration(amount) * DurationUnits[match[2]], nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q, use eg. 30m, 12h, 7d or 2w", value)
+	}
+	return duration, nil
+}
+
+/*
+*		Load a timezone by its IANA name (eg. Australia/Melbourne, UTC, Local) or by its offset (eg. +10:00)
+*
+*	    @return (*time.Location, error): Timezone, and error if it is neither a known name nor an offset
+ */
+func LoadTimezone(name string) (*time.Location, error) {
+	if match := offsetPattern.FindStringSubmatch(name); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi(match[3])
+		offset := hours*3600 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(name, offset), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q, use an IANA name like Australia/Melbourne or an offset like +10:00", name)
+	}
+	return location, nil
+}
+
+// InTimezone - Convert all Timestamp fields of all entities in results to a timezone, for displaying them in it.
+// Results may hold entities of any type (eg. Records[any]), which are then copied to convert their fields
+func InTimezone(results DataStore, location *time.Location) {
+	records := reflect.ValueOf(results)
+	if location == nil || records.Kind() != reflect.Slice {
+		return
+	}
+	for i := 0; i < records.Len(); i++ {
+		record := records.Index(i)
+		if record.Kind() != reflect.Interface {
+			fieldsInTimezone(record, location)
+			continue
+		}
+		if record.IsNil() || record.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		copied := reflect.New(record.Elem().Type()).Elem()
+		copied.Set(record.Elem())
+		fieldsInTimezone(copied, location)
+		record.Set(copied)
+	}
+}
+
+// Convert all Timestamp fields of an entity to a timezone
+func fieldsInTimezone(record reflect.Value, location *time.Location) {
+	if record.Kind() != reflect.Struct {
+		return
+	}
+	timestampType := reflect.TypeOf(Timestamp{})
+	for j := 0; j < record.NumField(); j++ {
+		field := record.Field(j)
+		if field.Type() != timestampType || !field.CanSet() {
+			continue
+		}
+		timestamp := field.Interface().(Timestamp)
+		if !timestamp.IsZero() {
+			field.Set(reflect.ValueOf(Timestamp{timestamp.In(location)}))
+		}
+	}
+}
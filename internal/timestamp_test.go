@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTimestamp(t *testing.T) {
+	type entity struct {
+		CreatedAt Timestamp `json:"created_at"`
+	}
+	t.Run("test timestamps keep their offset when unmarshalled and marshalled again", func(t *testing.T) {
+		var e entity
+		err := json.Unmarshal([]byte(`{"created_at":"2016-04-15T05:19:46 -10:00"}`), &e)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2016, 4, 15, 15, 19, 46, 0, time.UTC).Unix(), e.CreatedAt.Unix())
+		assert.Equal(t, "2016-04-15T05:19:46 -10:00", e.CreatedAt.String())
+		raw, err := json.Marshal(e)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"created_at":"2016-04-15T05:19:46 -10:00"}`, string(raw))
+	})
+	t.Run("test missing, null and empty timestamps are zero", func(t *testing.T) {
+		for _, raw := range []string{`{}`, `{"created_at":null}`, `{"created_at":""}`} {
+			var e entity
+			assert.Nil(t, json.Unmarshal([]byte(raw), &e))
+			assert.True(t, e.CreatedAt.IsZero())
+			assert.Equal(t, "", e.CreatedAt.String())
+		}
+	})
+	t.Run("test invalid timestamps", func(t *testing.T) {
+		var e entity
+		err := json.Unmarshal([]byte(`{"created_at":"15/04/2016"}`), &e)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestLoadTimezone(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+	}{
+		{name: "UTC", offset: 0},
+		{name: "+10:00", offset: 10 * 60 * 60},
+		{name: "-0930", offset: -(9*60*60 + 30*60)},
+		{name: "Asia/Kolkata", offset: 5*60*60 + 30*60},
+	}
+	for _, tt := range tests {
+		t.Run("test timezone "+tt.name, func(t *testing.T) {
+			location, err := LoadTimezone(tt.name)
+			assert.Nil(t, err)
+			_, offset := time.Date(2016, 4, 15, 0, 0, 0, 0, location).Zone()
+			assert.Equal(t, tt.offset, offset)
+		})
+	}
+	t.Run("test unknown timezone", func(t *testing.T) {
+		_, err := LoadTimezone("Mars/Olympus_Mons")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestInTimezone(t *testing.T) {
+	type entity struct {
+		Id        int
+		CreatedAt Timestamp
+		DueAt     Timestamp
+	}
+	t.Run("test timestamps are converted to a timezone, leaving out zero timestamps", func(t *testing.T) {
+		created, _ := time.Parse(TimestampLayout, "2016-04-15T05:19:46 -10:00")
+		results := Records[entity]{{Id: 1, CreatedAt: Timestamp{created}}}
+		location, _ := LoadTimezone("+10:00")
+		InTimezone(results, location)
+		assert.Equal(t, "2016-04-16T01:19:46 +10:00", results[0].CreatedAt.String())
+		assert.True(t, results[0].DueAt.IsZero())
+	})
+	t.Run("test timestamps of entities of any type are converted to a timezone", func(t *testing.T) {
+		created, _ := time.Parse(TimestampLayout, "2016-04-15T05:19:46 -10:00")
+		results := Records[any]{entity{Id: 1, CreatedAt: Timestamp{created}}}
+		location, _ := LoadTimezone("+10:00")
+		InTimezone(results, location)
+		assert.Equal(t, "2016-04-16T01:19:46 +10:00", results[0].(entity).CreatedAt.String())
+	})
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		duration time.Duration
+	}{
+		{value: "30m", duration: 30 * time.Minute},
+		{value: "7d", duration: 7 * 24 * time.Hour},
+		{value: "2 w", duration: 14 * 24 * time.Hour},
+		{value: "1h30m", duration: 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run("test duration "+tt.value, func(t *testing.T) {
+			duration, err := ParseDuration(tt.value)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.duration, duration)
+		})
+	}
+	t.Run("test invalid duration", func(t *testing.T) {
+		_, err := ParseDuration("a fortnight")
+		assert.NotNil(t, err)
+	})
+}
@@ -4,6 +4,8 @@
 // and conform to standards.
 package internal
 
+import "reflect"
+
 // DataProcessor - Defines common methods that all model-helper structs conform to,
 // to allow fetching filtered, processed, raw data at any time
 type DataProcessor interface {
@@ -25,3 +27,34 @@ type DataStore interface {
 	// Fetch - Get list of underlying entity (usually after by FetchFiltered method) is called
 	Fetch() []interface{}
 }
+
+// Entity - Defines what every registered model exposes, regardless of the struct it is backed by. Allows commands to
+// be generated for, and searches to be run against, any model without knowing its concrete type
+type Entity interface {
+	// EntityName - Name of the entity, as used by CLI sub-commands (eg. 'search user')
+	EntityName() string
+
+	// DataFile - Name of the JSON file the entity is loaded from
+	DataFile() string
+
+	// PrimaryKey - JSON field which uniquely identifies each entity
+	PrimaryKey() string
+
+	// Mappings - Mapping of JSON field names to struct field names, for all displayed fields
+	Mappings() map[string]string
+
+	// FieldType - Type of a mapped field, nil if the field is not mapped
+	FieldType(field string) reflect.Type
+
+	// SearchableFields - Sorted list of JSON field names that can be searched on
+	SearchableFields() []string
+
+	// CustomFieldPrefix - Prefix of searchable names of custom fields (eg. custom_field.), empty if there are none
+	CustomFieldPrefix() string
+
+	// Relations - Relationships used to enrich entities with details of related entities
+	Relations() []Relationship
+
+	// Load - Parse raw JSON data into the DataProcessor for the entity
+	Load(raw []byte) (DataProcessor, error)
+}
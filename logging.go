@@ -0,0 +1,92 @@
+// Package main -
+//
+// This file is meant for configuring logs of every command by the global logging flags (--log-level, --log-format,
+// --log-file, -v and -q). Logs always go to stderr (or the log file), never to stdout where results are displayed
+package main
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+)
+
+// LogLevelEnv - Environment variable of the log level, when --log-level, -v or -q are not given
+const LogLevelEnv = "LOG_LEVEL"
+
+// Formats of logs, by --log-format
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// addLoggingFlags - Add the global logging flags to the root command, inherited by all sub-commands
+func addLoggingFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("log-level", "", "Level of logs: trace, debug, info, warn or error (default info, or LOG_LEVEL)")
+	cmd.PersistentFlags().String("log-format", LogFormatText, "Format of logs: text, or json for structured logs")
+	cmd.PersistentFlags().String("log-file", "", "File logs are appended to, instead of stderr")
+	cmd.PersistentFlags().CountP("verbose", "v", "Log more details: -v for debug logs, -vv for trace logs")
+	cmd.PersistentFlags().BoolP("quiet", "q", false, "Log errors only")
+	cmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
+}
+
+/*
+*		Configure the level, format and output of logs by the logging flags of a command. --log-level takes precedence
+*		over -v and -q, which take precedence over LOG_LEVEL
+*
+*	    @return (io.Closer, error): Log file to close once the command is done, or nil if --log-file is not set, and a
+*		UsageError if any flag is invalid
+ */
+func configureLogging(cmd *cobra.Command) (io.Closer, error) {
+	level, err := logLevel(cmd)
+	if err != nil {
+		return nil, &UsageError{Err: err}
+	}
+	format, _ := cmd.Flags().GetString("log-format")
+	switch format {
+	case LogFormatText:
+		log.SetFormatter(&log.TextFormatter{})
+	case LogFormatJSON:
+		log.SetFormatter(&log.JSONFormatter{})
+	default:
+		return nil, &UsageError{Err: fmt.Errorf("invalid --log-format %q, expected %v or %v", format, LogFormatText, LogFormatJSON)}
+	}
+	log.SetLevel(level)
+	log.SetOutput(os.Stderr)
+	path, _ := cmd.Flags().GetString("log-file")
+	if path == "" {
+		return nil, nil // Logs are written to stderr, which is never closed
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, &UsageError{Err: fmt.Errorf("invalid --log-file: %w", err)}
+	}
+	log.SetOutput(file)
+	return file, nil
+}
+
+// Level of logs by the logging flags of a command, or by LOG_LEVEL if none are set
+func logLevel(cmd *cobra.Command) (log.Level, error) {
+	if name, _ := cmd.Flags().GetString("log-level"); name != "" {
+		level, err := log.ParseLevel(name)
+		if err != nil {
+			return log.InfoLevel, fmt.Errorf("invalid --log-level %q, expected trace, debug, info, warn or error", name)
+		}
+		return level, nil
+	}
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		return log.ErrorLevel, nil
+	}
+	switch verbose, _ := cmd.Flags().GetCount("verbose"); {
+	case verbose == 1:
+		return log.DebugLevel, nil
+	case verbose > 1:
+		return log.TraceLevel, nil
+	}
+	if level, err := log.ParseLevel(strings.ToLower(os.Getenv(LogLevelEnv))); err == nil {
+		return level, nil
+	}
+	return log.InfoLevel, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test logging flags, along with the structured fields of logs of searches
+
+func TestLogLevel(t *testing.T) {
+	tests := []struct {
+		title string
+		args  []string
+		env   string
+		level log.Level
+	}{
+		{title: "default level", level: log.InfoLevel},
+		{title: "level of the environment variable", env: "debug", level: log.DebugLevel},
+		{title: "verbose", args: []string{"-v"}, env: "error", level: log.DebugLevel},
+		{title: "very verbose", args: []string{"-vv"}, level: log.TraceLevel},
+		{title: "quiet", args: []string{"-q"}, level: log.ErrorLevel},
+		{title: "log level over verbosity", args: []string{"--log-level", "warn", "-v"}, level: log.WarnLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			t.Setenv(LogLevelEnv, tt.env)
+			cmd := NewRootCmd()
+			assert.Nil(t, cmd.ParseFlags(tt.args))
+			level, err := logLevel(cmd)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.level, level)
+		})
+	}
+	t.Run("invalid log level", func(t *testing.T) {
+		cmd := NewRootCmd()
+		assert.Nil(t, cmd.ParseFlags([]string{"--log-level", "loud"}))
+		_, err := logLevel(cmd)
+		assert.Equal(t, `invalid --log-level "loud", expected trace, debug, info, warn or error`, err.Error())
+	})
+}
+
+func TestConfigureLogging(t *testing.T) {
+	t.Setenv("DATA_DIR", "testdata")
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFormatter(&log.TextFormatter{})
+		log.SetLevel(log.InfoLevel)
+	})
+
+	t.Run("test structured logs of a search in the log file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cli.log")
+		root := NewRootCmd()
+		root.SetOut(new(bytes.Buffer))
+		args := []string{"search", "user", "--name", "_id", "--value", "22", "--log-format", "json", "--log-file", path, "-v"}
+		assert.Equal(t, ExitOK, execute(root, args, new(bytes.Buffer)))
+
+		raw, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		var entries []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(raw), []byte("\n")) {
+			var entry map[string]any
+			assert.Nil(t, json.Unmarshal(line, &entry))
+			entries = append(entries, entry)
+		}
+		var evaluated map[string]any
+		for _, entry := range entries {
+			if entry["msg"] == "Evaluated condition" {
+				evaluated = entry
+			}
+		}
+		assert.NotNil(t, evaluated, "conditions are logged at debug level")
+		assert.Equal(t, "user", evaluated["entity"])
+		assert.Equal(t, "_id", evaluated["field"])
+		assert.Equal(t, "int", evaluated["value_type"])
+		assert.Equal(t, float64(1), evaluated["results"])
+		assert.Contains(t, evaluated, "duration")
+		assert.Equal(t, "All results displayed", entries[len(entries)-1]["msg"])
+	})
+	t.Run("test no log file to close without --log-file", func(t *testing.T) {
+		cmd := NewRootCmd()
+		assert.Nil(t, cmd.ParseFlags([]string{"-v"}))
+		closer, err := configureLogging(cmd)
+		assert.Nil(t, err)
+		assert.Nil(t, closer)
+
+		assert.Nil(t, cmd.ParseFlags([]string{"--log-file", filepath.Join(t.TempDir(), "cli.log")}))
+		closer, err = configureLogging(cmd)
+		assert.Nil(t, err)
+		if assert.NotNil(t, closer) {
+			assert.Nil(t, closer.Close())
+		}
+		log.SetOutput(os.Stderr)
+	})
+	t.Run("test invalid log format", func(t *testing.T) {
+		stderr := new(bytes.Buffer)
+		assert.Equal(t, ExitUsage, execute(NewRootCmd(), []string{"list", "--log-format", "xml"}, stderr))
+		assert.Equal(t, "Error: invalid --log-format \"xml\", expected text or json\nRun 'cli list --help' for usage.\n", stderr.String())
+	})
+	t.Run("test verbose and quiet together", func(t *testing.T) {
+		assert.Equal(t, ExitUsage, execute(NewRootCmd(), []string{"list", "-v", "-q"}, new(bytes.Buffer)))
+	})
+}
@@ -1,21 +1,29 @@
 // Package main -
 //
 // - Defines the root command, to which all subcommands are added
-// - Defines log levels for the application based on LOG_LEVEL environment variable.
+// - Defines global logging flags for the application (or LOG_LEVEL environment variable), see `logging.go`
+// - Defines global profiling flags (--cpuprofile and --memprofile), see `profile.go`
+// - Defines global config flags (--config, --profile and --color), see `config.go`
+// - Defines the completion command, generating completion scripts of shells, see `cmd/completion`
+// - Exits with a code by the kind of error a command failed with, see `errors.go`
 //
 
 package main
 
 import (
+	"ZendeskChallenge/cmd/completion"
+	"ZendeskChallenge/cmd/config"
+	"ZendeskChallenge/cmd/export"
 	"ZendeskChallenge/cmd/list"
+	"ZendeskChallenge/cmd/query"
+	"ZendeskChallenge/cmd/report"
 	"ZendeskChallenge/cmd/search"
-	log "github.com/sirupsen/logrus"
+	"ZendeskChallenge/cmd/serve"
 	"github.com/spf13/cobra"
 	"os"
-	"strings"
 )
 
-// NewRootCmd - Defines root command, which adds all sub-commands (search & list) using cobra API.
+// NewRootCmd - Defines root command, which adds all sub-commands (search, query, list, report, serve, config, export & completion) using cobra API.
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "cli",
@@ -30,25 +38,23 @@ func NewRootCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.SetOut(os.Stdout) // Results are displayed on stdout, apart from logs and errors on stderr
+	addLoggingFlags(cmd)
+	addProfilingFlags(cmd)
+	addConfigFlags(cmd)
+	cmd.PersistentFlags().String("error-format", ErrorFormatText, "Format errors are reported in on stderr: text, or json for scripts")
 	cmd.AddCommand(search.NewSearchCmd())
+	cmd.AddCommand(query.NewQueryCmd())
 	cmd.AddCommand(list.NewListCmd())
+	cmd.AddCommand(report.NewReportCmd())
+	cmd.AddCommand(serve.NewServeCmd())
+	cmd.AddCommand(config.NewConfigCmd())
+	cmd.AddCommand(export.NewExportCmd())
+	cmd.AddCommand(completion.NewCompletionCmd())
+	cmd.CompletionOptions.DisableDefaultCmd = true // Replaced by the completion command, with help for each shell
 	return cmd
 }
 
 func main() {
-	ll := strings.ToUpper(os.Getenv("LOG_LEVEL"))
-	if ll == "ERROR" {
-		log.SetLevel(log.ErrorLevel)
-	} else if ll == "DEBUG" {
-		log.SetLevel(log.DebugLevel)
-	} else if ll == "TRACE" {
-		log.SetLevel(log.TraceLevel)
-	} else {
-		log.SetLevel(log.InfoLevel)
-	}
-
-	if err := NewRootCmd().Execute(); err != nil {
-		log.Error(err)
-		os.Exit(101)
-	}
+	os.Exit(execute(NewRootCmd(), os.Args[1:], os.Stderr))
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// Test exit codes of the CLI by the kind of error commands fail with, and errors reported as text or JSON
+
+func TestExecute(t *testing.T) {
+	t.Setenv("DATA_DIR", "testdata")
+	tests := []struct {
+		title string
+		args  []string
+		exit  int
+		code  string
+	}{
+		{title: "results found", args: []string{"search", "user", "--name", "_id", "--value", "22"}, exit: ExitOK},
+		{title: "no results", args: []string{"search", "user", "--name", "name", "--value", "Nobody"}, exit: ExitNoResults, code: "no_results"},
+		{title: "no results of a query", args: []string{"query", "user where name = Nobody"}, exit: ExitNoResults, code: "no_results"},
+		{title: "unknown flag", args: []string{"search", "user", "--nmae", "name"}, exit: ExitUsage, code: "usage"},
+		{title: "required flag not set", args: []string{"search", "user"}, exit: ExitUsage, code: "usage"},
+		{title: "mutually exclusive flags", args: []string{"search", "user", "--name", "alias", "--value", "x", "--null"}, exit: ExitUsage, code: "usage"},
+		{title: "wrong number of arguments", args: []string{"query"}, exit: ExitUsage, code: "usage"},
+		{title: "unknown command", args: []string{"find"}, exit: ExitUsage, code: "usage"},
+		{title: "unknown field", args: []string{"search", "user", "--name", "nmae", "--value", "x"}, exit: ExitInvalidField, code: "unknown_field"},
+		{title: "unknown field of a query", args: []string{"query", "user where nmae = x"}, exit: ExitInvalidField, code: "unknown_field"},
+		{title: "invalid value", args: []string{"search", "user", "--name", "_id", "--value", "abc"}, exit: ExitInvalidValue, code: "invalid_value"},
+		{title: "invalid value of a query", args: []string{"query", "user where verified = maybe"}, exit: ExitInvalidValue, code: "invalid_value"},
+		{title: "unknown organization to export", args: []string{"export", "--organization", "999", "--out", "unused"}, exit: ExitNoResults, code: "not_found"},
+		{title: "invalid query", args: []string{"query", "user wher name = x"}, exit: ExitInvalidQuery, code: "invalid_query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			stderr := new(bytes.Buffer)
+			root := NewRootCmd()
+			root.SetOut(new(bytes.Buffer))
+			assert.Equal(t, tt.exit, execute(root, append(tt.args, "--error-format", "json"), stderr))
+			if tt.exit == ExitOK {
+				assert.Empty(t, stderr.String())
+				return
+			}
+			var output errorOutput
+			assert.Nil(t, json.Unmarshal(stderr.Bytes(), &output), "only the error is reported: %v", stderr.String())
+			assert.Equal(t, tt.code, output.Code)
+			assert.Equal(t, tt.exit, output.ExitCode)
+		})
+	}
+}
+
+func TestExecute_Text(t *testing.T) {
+	t.Setenv("DATA_DIR", "testdata")
+
+	t.Run("test error along with hints of the command", func(t *testing.T) {
+		stderr := new(bytes.Buffer)
+		root := NewRootCmd()
+		root.SetErr(stderr)
+		assert.Equal(t, ExitInvalidField, execute(root, []string{"search", "user", "--name", "nmae", "--value", "x"}, stderr))
+		assert.Equal(t, "Invalid field passed in for --name. Did you mean --name name? Otherwise please use 'list' command to find searchable fields\n"+
+			"Error: invalid search of users: unknown field nmae\n", stderr.String())
+	})
+	t.Run("test usage error points at help of the command", func(t *testing.T) {
+		stderr := new(bytes.Buffer)
+		root := NewRootCmd()
+		assert.Equal(t, ExitUsage, execute(root, []string{"search", "user"}, stderr))
+		assert.Equal(t, "Error: required flag(s) \"name\" not set\nRun 'cli search user --help' for usage.\n", stderr.String())
+	})
+	t.Run("test no results are not reported as an error", func(t *testing.T) {
+		stderr := new(bytes.Buffer)
+		root := NewRootCmd()
+		root.SetOut(new(bytes.Buffer))
+		assert.Equal(t, ExitNoResults, execute(root, []string{"search", "user", "--name", "_id", "--value", "1"}, stderr))
+		assert.False(t, strings.Contains(stderr.String(), "Error"))
+	})
+	t.Run("test data which fails to load", func(t *testing.T) {
+		t.Setenv("DATA_DIR", "missing")
+		stderr := new(bytes.Buffer)
+		root := NewRootCmd()
+		assert.Equal(t, ExitLoadFailure, execute(root, []string{"search", "user", "--name", "_id", "--value", "1"}, stderr))
+		assert.True(t, strings.HasPrefix(stderr.String(), "Error: error occurred during parsing users.json"))
+	})
+	t.Run("test invalid error format", func(t *testing.T) {
+		stderr := new(bytes.Buffer)
+		root := NewRootCmd()
+		assert.Equal(t, ExitUsage, execute(root, []string{"list", "--error-format", "xml"}, stderr))
+		assert.True(t, strings.HasPrefix(stderr.String(), `Error: invalid --error-format "xml"`))
+	})
+}
+
+func TestDescribeError(t *testing.T) {
+	t.Run("test command interrupted by Ctrl-C", func(t *testing.T) {
+		output := describeError(fmt.Errorf("searching users: %w", context.Canceled))
+		assert.Equal(t, "interrupted", output.Code)
+		assert.Equal(t, ExitInterrupted, output.ExitCode)
+	})
+	t.Run("test any other error", func(t *testing.T) {
+		output := describeError(errors.New("disk full"))
+		assert.Equal(t, errorOutput{Code: "failure", Message: "disk full", ExitCode: ExitFailure}, output)
+	})
+}
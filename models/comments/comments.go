@@ -0,0 +1,42 @@
+// Package comments -
+//
+// Defines the ticket comment model, its key fields and its relationships to other entities
+package comments
+
+import (
+	"ZendeskChallenge/internal"
+)
+
+type CommentEntity struct {
+	Id            int                `json:"_id"`
+	Url           string             `json:"url"`
+	TicketId      string             `json:"ticket_id"`
+	AuthorId      int                `json:"author_id"`
+	Body          string             `json:"body"`
+	Public        bool               `json:"public"`
+	Via           string             `json:"via"`
+	CreatedAt     internal.Timestamp `json:"created_at"`
+	AuthorName    string             `json:"author_name,omitempty"`
+	TicketSubject string             `json:"ticket_subject,omitempty"`
+}
+
+// Comment - List of ticket comments
+type Comment = internal.Records[CommentEntity]
+
+// CommentData - Raw, processed and filtered ticket comments
+type CommentData = internal.Data[CommentEntity]
+
+// KeyMappings - Mapping of JSON field names to struct field names, derived from the json tags of CommentEntity
+var KeyMappings = internal.FieldMappings[CommentEntity]()
+
+// Model - Declaration of the ticket comment entity: each comment shows its author name and the subject of its ticket
+var Model = internal.Model[CommentEntity]{
+	Name:        "comment",
+	File:        "ticket_comments.json",
+	KeyField:    "_id",
+	KeyMappings: KeyMappings,
+	Relationships: []internal.Relationship{
+		{Field: "author_name", Entity: "user", LocalKey: "author_id", ForeignKey: "_id", Display: "name"},
+		{Field: "ticket_subject", Entity: "ticket", LocalKey: "ticket_id", ForeignKey: "_id", Display: "subject"},
+	},
+}
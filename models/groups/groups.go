@@ -0,0 +1,40 @@
+// Package groups -
+//
+// Defines the group model (agents assigned to groups), its key fields and its relationships to other entities
+package groups
+
+import (
+	"ZendeskChallenge/internal"
+)
+
+type GroupEntity struct {
+	Id          int                `json:"_id"`
+	Url         string             `json:"url"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Default     bool               `json:"default"`
+	Deleted     bool               `json:"deleted"`
+	CreatedAt   internal.Timestamp `json:"created_at"`
+	AgentIds    []int              `json:"agent_ids"`
+	Agents      []string           `json:"agents,omitempty"`
+}
+
+// Group - List of groups
+type Group = internal.Records[GroupEntity]
+
+// GroupData - Raw, processed and filtered groups
+type GroupData = internal.Data[GroupEntity]
+
+// KeyMappings - Mapping of JSON field names to struct field names, derived from the json tags of GroupEntity
+var KeyMappings = internal.FieldMappings[GroupEntity]()
+
+// Model - Declaration of the group entity: each group shows the names of the agents assigned to it
+var Model = internal.Model[GroupEntity]{
+	Name:        "group",
+	File:        "groups.json",
+	KeyField:    "_id",
+	KeyMappings: KeyMappings,
+	Relationships: []internal.Relationship{
+		{Field: "agents", Entity: "user", LocalKey: "agent_ids", ForeignKey: "_id", Display: "name"},
+	},
+}
@@ -0,0 +1,25 @@
+// Package models -
+//
+// Registers all entity models with the application. Adding a new entity only requires declaring its Model in its own
+// package, and adding it to the Registry below.
+package models
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/comments"
+	"ZendeskChallenge/models/groups"
+	"ZendeskChallenge/models/organizations"
+	"ZendeskChallenge/models/ratings"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+)
+
+// Registry - All searchable entities, in the order they are listed by the CLI
+var Registry = internal.NewRegistry(
+	users.Model,
+	organizations.Model,
+	tickets.Model,
+	groups.Model,
+	comments.Model,
+	ratings.Model,
+)
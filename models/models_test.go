@@ -0,0 +1,125 @@
+package models
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/comments"
+	"ZendeskChallenge/models/groups"
+	"ZendeskChallenge/models/organizations"
+	"ZendeskChallenge/models/ratings"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Test that models stay in line with the data they are loaded from, and with each other. Fails whenever the fields of
+// an entity struct and its data diverge.
+
+func TestRegistry_ModelsMatchData(t *testing.T) {
+	for _, entity := range Registry.All() {
+		t.Run("model matches data of "+entity.EntityName(), func(t *testing.T) {
+			raw, err := os.ReadFile("../" + entity.DataFile())
+			assert.Nil(t, err)
+			var records []map[string]any
+			assert.Nil(t, json.Unmarshal(raw, &records))
+			data, err := entity.Load(raw)
+			assert.Nil(t, err)
+			declared := internal.JSONFieldNames(reflect.TypeOf(data.FetchProcessed()[0]))
+
+			seen := map[string]bool{}
+			for _, record := range records {
+				for key, value := range record {
+					seen[key] = true
+					assert.Contains(t, declared, key, "field '"+key+"' of the data is declared on the entity")
+					if fieldType := entity.FieldType(key); fieldType != nil && value != nil {
+						assert.Equal(t, jsonKind(fieldType), reflect.TypeOf(value).Kind(), "field '"+key+"' has the declared type")
+					}
+				}
+			}
+			for _, field := range entity.SearchableFields() {
+				assert.True(t, seen[field], "searchable field '"+field+"' is in the data")
+			}
+		})
+	}
+}
+
+func TestRegistry_Models(t *testing.T) {
+	tests := []struct {
+		entity internal.Entity
+		name   string
+		count  int // Number of records of the test data of the entity
+	}{
+		{entity: users.Model, name: "user", count: 5},
+		{entity: organizations.Model, name: "organization", count: 5},
+		{entity: tickets.Model, name: "ticket", count: 4},
+		{entity: groups.Model, name: "group", count: 3},
+		{entity: comments.Model, name: "comment", count: 3},
+		{entity: ratings.Model, name: "rating", count: 2},
+	}
+	for _, tt := range tests {
+		t.Run("model loads data and declares fields that exist on the entity of "+tt.name, func(t *testing.T) {
+			raw, err := os.ReadFile(filepath.Join("..", "testdata", tt.entity.DataFile()))
+			assert.Nil(t, err)
+			data, err := tt.entity.Load(raw)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.count, len(data.FetchProcessed()))
+			assert.Equal(t, raw, data.FetchRaw())
+			entityType := reflect.TypeOf(data.FetchProcessed()[0])
+			for key, field := range tt.entity.Mappings() {
+				_, ok := entityType.FieldByName(field)
+				assert.True(t, ok, "field mapped for '"+key+"' exists")
+			}
+			for _, relation := range tt.entity.Relations() {
+				assert.Contains(t, tt.entity.Mappings(), relation.Field)
+				assert.Contains(t, tt.entity.Mappings(), relation.LocalKey)
+				assert.NotContains(t, tt.entity.SearchableFields(), relation.Field) // Related fields are not in the data
+			}
+			assert.Contains(t, tt.entity.SearchableFields(), tt.entity.PrimaryKey())
+			assert.Equal(t, tt.name, tt.entity.EntityName())
+		})
+	}
+}
+
+func TestRegistry_RelationshipsMatchModels(t *testing.T) {
+	for _, entity := range Registry.All() {
+		for _, relation := range entity.Relations() {
+			t.Run(entity.EntityName()+" related to "+relation.Entity+" for "+relation.Field, func(t *testing.T) {
+				related, ok := Registry.Get(relation.Entity)
+				assert.True(t, ok, "related entity is registered")
+				assert.NotNil(t, entity.FieldType(relation.Field))
+				assert.NotNil(t, entity.FieldType(relation.LocalKey))
+				assert.NotNil(t, related.FieldType(relation.ForeignKey))
+				assert.NotNil(t, related.FieldType(relation.Display))
+				assert.Equal(t, jsonKind(elementType(entity.FieldType(relation.LocalKey))), jsonKind(elementType(related.FieldType(relation.ForeignKey))), "keys are of same type")
+				assert.Equal(t, elementType(entity.FieldType(relation.Field)), related.FieldType(relation.Display), "related field is of same type as displayed field")
+			})
+		}
+	}
+}
+
+// Kind of value a field of given type is decoded from, when JSON is decoded without a struct
+func jsonKind(fieldType reflect.Type) reflect.Kind {
+	if fieldType == reflect.TypeOf(internal.Timestamp{}) {
+		return reflect.String
+	}
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int64:
+		return reflect.Float64
+	case reflect.Map:
+		return reflect.Map
+	default:
+		return fieldType.Kind()
+	}
+}
+
+// Type of elements of a list type, or the type itself otherwise
+func elementType(fieldType reflect.Type) reflect.Type {
+	if fieldType != nil && fieldType.Kind() == reflect.Slice {
+		return fieldType.Elem()
+	}
+	return fieldType
+}
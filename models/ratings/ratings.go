@@ -0,0 +1,49 @@
+// Package ratings -
+//
+// Defines the satisfaction (CSAT) rating model, its key fields and its relationships to other entities
+package ratings
+
+import (
+	"ZendeskChallenge/internal"
+)
+
+type RatingEntity struct {
+	Id            int                `json:"_id"`
+	Url           string             `json:"url"`
+	TicketId      string             `json:"ticket_id"`
+	AssigneeId    int                `json:"assignee_id,omitempty"`
+	RequesterId   int                `json:"requester_id"`
+	GroupId       int                `json:"group_id"`
+	Score         string             `json:"score"`
+	Comment       string             `json:"comment"`
+	Reason        string             `json:"reason"`
+	CreatedAt     internal.Timestamp `json:"created_at"`
+	TicketSubject string             `json:"ticket_subject,omitempty"`
+	AssigneeName  string             `json:"assignee_name,omitempty"`
+	RequesterName string             `json:"requester_name,omitempty"`
+	GroupName     string             `json:"group_name,omitempty"`
+}
+
+// Rating - List of satisfaction ratings
+type Rating = internal.Records[RatingEntity]
+
+// RatingData - Raw, processed and filtered satisfaction ratings
+type RatingData = internal.Data[RatingEntity]
+
+// KeyMappings - Mapping of JSON field names to struct field names, derived from the json tags of RatingEntity
+var KeyMappings = internal.FieldMappings[RatingEntity]()
+
+// Model - Declaration of the satisfaction rating entity: each rating shows the subject of its ticket, and names of
+// its assignee, requester and group
+var Model = internal.Model[RatingEntity]{
+	Name:        "rating",
+	File:        "satisfaction_ratings.json",
+	KeyField:    "_id",
+	KeyMappings: KeyMappings,
+	Relationships: []internal.Relationship{
+		{Field: "ticket_subject", Entity: "ticket", LocalKey: "ticket_id", ForeignKey: "_id", Display: "subject"},
+		{Field: "assignee_name", Entity: "user", LocalKey: "assignee_id", ForeignKey: "_id", Display: "name"},
+		{Field: "requester_name", Entity: "user", LocalKey: "requester_id", ForeignKey: "_id", Display: "name"},
+		{Field: "group_name", Entity: "group", LocalKey: "group_id", ForeignKey: "_id", Display: "name"},
+	},
+}
@@ -1,109 +1,90 @@
 // Package tickets -
 //
-// Defines the ticket model and its key fields. Implements DataStore and DataProcessor interfaces
+// Defines the ticket model, its key fields and its relationships to other entities
 package tickets
 
 import (
 	"ZendeskChallenge/internal"
 	"encoding/json"
+	"strconv"
 )
 
-type Ticket []struct {
-	Id               string   `json:"_id"`
-	ExternalId       string   `json:"external_id"`
-	Type             string   `json:"type"`
-	Description      string   `json:"description,omitempty"`
-	Priority         string   `json:"priority"`
-	Status           string   `json:"status"`
-	Subject          string   `json:"subject"`
-	OrganizationId   int      `json:"organization_id"`
-	SubmitterId      int      `json:"submitter_id"`
-	AssigneeId       int      `json:"assignee_id,omitempty"`
-	CreatedAt        string   `json:"created_at"`
-	HasIncidents     bool     `json:"has_incidents"`
-	DueAt            string   `json:"due_at"`
-	Via              string   `json:"via"`
-	Tags             []string `json:"tags"`
-	SubmitterName    string   `json:",omitempty"`
-	AssigneeName     string   `json:",omitempty"`
-	OrganizationName string   `json:",omitempty"`
-	Url              string   `json:"url"`
-}
-
-type TicketData struct {
-	Raw       []byte
-	Processed Ticket
-	Filtered  Ticket
-}
+// CustomFieldPrefix - Prefix of searchable names of ticket custom fields, followed by the ID of the field
+const CustomFieldPrefix = "custom_field."
 
-var KeyMappings = map[string]string{
-	"_id":             "Id",
-	"external_id":     "ExternalId",
-	"type":            "Type",
-	"description":     "Description",
-	"priority":        "Priority",
-	"status":          "Status",
-	"subject":         "Subject",
-	"organization_id": "OrganizationId",
-	"submitter_id":    "SubmitterId",
-	"assignee_id":     "AssigneeId",
-	"created_at":      "CreatedAt",
-	"has_incidents":   "HasIncidents",
-	"due_at":          "DueAt",
-	"via":             "Via",
-	"tags":            "Tags",
-	"url":             "Url",
+type TicketEntity struct {
+	Id               string                     `json:"_id"`
+	ExternalId       string                     `json:"external_id"`
+	Type             string                     `json:"type"`
+	Description      string                     `json:"description,omitempty"`
+	Priority         string                     `json:"priority"`
+	Status           string                     `json:"status"`
+	Subject          string                     `json:"subject"`
+	OrganizationId   int                        `json:"organization_id"`
+	SubmitterId      int                        `json:"submitter_id"`
+	AssigneeId       int                        `json:"assignee_id,omitempty"`
+	CreatedAt        internal.Timestamp         `json:"created_at"`
+	HasIncidents     bool                       `json:"has_incidents"`
+	DueAt            internal.Timestamp         `json:"due_at"`
+	Via              string                     `json:"via"`
+	Tags             []string                   `json:"tags"`
+	CustomFields     []CustomField              `json:"custom_fields,omitempty" schema:"-"`
+	SubmitterName    string                     `json:"submitter_name,omitempty"`
+	AssigneeName     string                     `json:"assignee_name,omitempty"`
+	OrganizationName string                     `json:"organization_name,omitempty"`
+	Url              string                     `json:"url"`
+	Extra            map[string]json.RawMessage `json:"-"` // Fields of the data not declared above
 }
 
-type TicketSearchFlags struct {
-	Value string
-	Name  string `validate:"required,oneof=_id external_id type description priority status subject created_at due_at submitter_id assignee_id has_incidents via tags organization_id url"`
+// CustomField - ID and value of a custom field of a ticket
+type CustomField struct {
+	Id    int64 `json:"id"`
+	Value any   `json:"value"`
 }
 
-func (t TicketSearchFlags) FetchName() string {
-	return t.Name
+// FetchCustomFields - Get values of all custom fields of the ticket, keyed by their searchable name
+func (t TicketEntity) FetchCustomFields() map[string]any {
+	fields := map[string]any{}
+	for _, field := range t.CustomFields {
+		fields[CustomFieldPrefix+strconv.FormatInt(field.Id, 10)] = field.Value
+	}
+	return fields
 }
 
-func (t TicketSearchFlags) FetchValue() string {
-	return t.Value
+// UnmarshalJSON - Unmarshal a ticket, preserving fields not declared on TicketEntity
+func (t *TicketEntity) UnmarshalJSON(data []byte) error {
+	type ticket TicketEntity
+	extra, err := internal.UnmarshalWithExtras(data, (*ticket)(t))
+	t.Extra = extra
+	return err
 }
 
-// SetFiltered - Set the filtered list of Ticket, and return the parent struct (TicketData) they belong to
-func (t *TicketData) SetFiltered(values any) (internal.DataProcessor, error) {
-	var result Ticket
-	jsonString, _ := json.Marshal(values)
-	err := json.Unmarshal(jsonString, &result)
-	if err != nil {
-		return nil, err
-	}
-	t.Filtered = result
-	return t, nil
+// MarshalJSON - Marshal a ticket, including fields not declared on TicketEntity
+func (t TicketEntity) MarshalJSON() ([]byte, error) {
+	type ticket TicketEntity
+	return internal.MarshalWithExtras(ticket(t), t.Extra)
 }
 
-// FetchFiltered - Get a filtered and processed list of Ticket (not raw bytes)
-func (t *TicketData) FetchFiltered() internal.DataStore {
-	return t.Filtered
-}
+// Ticket - List of tickets
+type Ticket = internal.Records[TicketEntity]
 
-// FetchProcessed - Get a processed list of Ticket (not raw bytes)
-func (t *TicketData) FetchProcessed() []interface{} {
-	var ticketData []interface{}
-	for _, user := range t.Processed {
-		ticketData = append(ticketData, user)
-	}
-	return ticketData
-}
+// TicketData - Raw, processed and filtered tickets
+type TicketData = internal.Data[TicketEntity]
 
-// FetchRaw - Get the raw Ticket data
-func (t *TicketData) FetchRaw() []byte {
-	return t.Raw
-}
+// KeyMappings - Mapping of JSON field names to struct field names, derived from the json tags of TicketEntity
+var KeyMappings = internal.FieldMappings[TicketEntity]()
 
-// Fetch - Get list of Ticket's (usually after by *TicketData#FetchFiltered method) is called
-func (t Ticket) Fetch() []interface{} {
-	var allTickets []interface{}
-	for _, ticket := range t {
-		allTickets = append(allTickets, ticket)
-	}
-	return allTickets
+// Model - Declaration of the ticket entity: each ticket shows its organization, submitter and assignee names, and its
+// custom fields are searchable as custom_field.<id>
+var Model = internal.Model[TicketEntity]{
+	Name:         "ticket",
+	File:         "tickets.json",
+	KeyField:     "_id",
+	KeyMappings:  KeyMappings,
+	CustomPrefix: CustomFieldPrefix,
+	Relationships: []internal.Relationship{
+		{Field: "organization_name", Entity: "organization", LocalKey: "organization_id", ForeignKey: "_id", Display: "name"},
+		{Field: "submitter_name", Entity: "user", LocalKey: "submitter_id", ForeignKey: "_id", Display: "name"},
+		{Field: "assignee_name", Entity: "user", LocalKey: "assignee_id", ForeignKey: "_id", Display: "name"},
+	},
 }
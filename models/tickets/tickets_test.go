@@ -97,3 +97,19 @@ func (suite *TestSuite) TestTicketData_FetchProcessed() {
 		}
 	})
 }
+
+func (suite *TestSuite) TestTicket_CustomFields() {
+	suite.Run("Test custom fields are searchable by name, and unknown fields are preserved", func() {
+		suite.Equal(map[string]any{"custom_field.360001234": "chat", "custom_field.360005678": true}, suite.ticket[0].FetchCustomFields())
+		suite.Equal(map[string]any{}, suite.ticket[1].FetchCustomFields())
+		suite.Nil(suite.ticket[0].Extra)
+		suite.Equal(json.RawMessage("360000042"), suite.ticket[1].Extra["brand_id"])
+
+		data, err := json.Marshal(suite.ticket[1])
+		suite.Nil(err)
+		var ticket TicketEntity
+		suite.Nil(json.Unmarshal(data, &ticket))
+		suite.Equal(suite.ticket[1], ticket) // Unknown fields survive a round trip
+		suite.Contains(string(data), `"brand_id":360000042`)
+	})
+}
@@ -1,6 +1,6 @@
 // Package users -
 //
-// Defines the users model and its key fields. Implements DataStore and DataProcessor interfaces
+// Defines the users model, its key fields and its relationships to other entities
 //
 
 package users
@@ -10,109 +10,79 @@ import (
 	"encoding/json"
 )
 
-type User []struct {
-	Id               int      `json:"_id"`
-	Alias            string   `json:"alias,omitempty"`
-	ExternalId       string   `json:"external_id"`
-	Name             string   `json:"name"`
-	Signature        string   `json:"signature"`
-	Email            string   `json:"email,omitempty"`
-	Phone            string   `json:"phone"`
-	Role             string   `json:"role"`
-	Locale           string   `json:"locale"`
-	CreatedAt        string   `json:"created_at"`
-	LastLoginAt      string   `json:"last_login_at"`
-	Timezone         string   `json:"timezone"`
-	Shared           bool     `json:"shared"`
-	Suspended        bool     `json:"suspended"`
-	Active           bool     `json:"active"`
-	Verified         bool     `json:"verified"`
-	OrganizationId   int      `json:"organization_id,omitempty"`
-	Tags             []string `json:"tags"`
-	OrganizationName string   `json:",omitempty"`
-	Tickets          []string `json:",omitempty"`
-	Url              string   `json:"url"`
-}
-
-type UserData struct {
-	Raw       []byte
-	Processed User
-	Filtered  User
-}
+// CustomFieldPrefix - Prefix of searchable names of user fields, followed by the key of the field
+const CustomFieldPrefix = "user_field."
 
-var KeyMappings = map[string]string{
-	"_id":               "Id",
-	"alias":             "Alias",
-	"external_id":       "ExternalId",
-	"name":              "Name",
-	"suspended":         "Suspended",
-	"active":            "Active",
-	"tags":              "Tags",
-	"verified":          "Verified",
-	"shared":            "Shared",
-	"timezone":          "Timezone",
-	"last_login_at":     "LastLoginAt",
-	"organization_id":   "OrganizationId",
-	"created_at":        "CreatedAt",
-	"locale":            "Locale",
-	"phone":             "Phone",
-	"email":             "Email",
-	"signature":         "Signature",
-	"role":              "Role",
-	"organization_name": "OrganizationName",
-	"tickets":           "Tickets",
-	"url":               "Url",
+type UserEntity struct {
+	Id               int                        `json:"_id"`
+	Alias            string                     `json:"alias,omitempty"`
+	ExternalId       string                     `json:"external_id"`
+	Name             string                     `json:"name"`
+	Signature        string                     `json:"signature"`
+	Email            string                     `json:"email,omitempty"`
+	Phone            string                     `json:"phone"`
+	Role             string                     `json:"role"`
+	Locale           string                     `json:"locale"`
+	CreatedAt        internal.Timestamp         `json:"created_at"`
+	LastLoginAt      internal.Timestamp         `json:"last_login_at"`
+	Timezone         string                     `json:"timezone"`
+	Shared           bool                       `json:"shared"`
+	Suspended        bool                       `json:"suspended"`
+	Active           bool                       `json:"active"`
+	Verified         bool                       `json:"verified"`
+	OrganizationId   int                        `json:"organization_id,omitempty"`
+	Tags             []string                   `json:"tags"`
+	UserFields       map[string]any             `json:"user_fields,omitempty" schema:"-"`
+	OrganizationName string                     `json:"organization_name,omitempty"`
+	Tickets          []string                   `json:"tickets,omitempty"`
+	Groups           []string                   `json:"groups,omitempty"`
+	Url              string                     `json:"url"`
+	Extra            map[string]json.RawMessage `json:"-"` // Fields of the data not declared above
 }
 
-type UserSearchFlags struct {
-	Value string
-	Name  string `validate:"required,oneof=_id alias external_id name signature email phone role locale created_at last_login_at timezone details shared suspended active verified organization_id tags url"`
-}
-
-func (u UserSearchFlags) FetchName() string {
-	return u.Name
+// FetchCustomFields - Get values of all user fields of the user, keyed by their searchable name
+func (u UserEntity) FetchCustomFields() map[string]any {
+	fields := map[string]any{}
+	for key, value := range u.UserFields {
+		fields[CustomFieldPrefix+key] = value
+	}
+	return fields
 }
 
-func (u UserSearchFlags) FetchValue() string {
-	return u.Value
+// UnmarshalJSON - Unmarshal a user, preserving fields not declared on UserEntity
+func (u *UserEntity) UnmarshalJSON(data []byte) error {
+	type user UserEntity
+	extra, err := internal.UnmarshalWithExtras(data, (*user)(u))
+	u.Extra = extra
+	return err
 }
 
-// SetFiltered - Set the filtered list of User, and return the parent struct (UserData) they belong to
-func (u *UserData) SetFiltered(values any) (internal.DataProcessor, error) {
-	var result User
-	jsonString, _ := json.Marshal(values)
-	err := json.Unmarshal(jsonString, &result)
-	if err != nil {
-		return nil, err
-	}
-	u.Filtered = result
-	return u, nil
+// MarshalJSON - Marshal a user, including fields not declared on UserEntity
+func (u UserEntity) MarshalJSON() ([]byte, error) {
+	type user UserEntity
+	return internal.MarshalWithExtras(user(u), u.Extra)
 }
 
-// FetchFiltered - Get a filtered and processed list of User (not raw bytes)
-func (u *UserData) FetchFiltered() internal.DataStore {
-	return u.Filtered
-}
+// User - List of users
+type User = internal.Records[UserEntity]
 
-// FetchProcessed - Get a processed list of User (not raw bytes)
-func (u *UserData) FetchProcessed() []interface{} {
-	var userData []interface{}
-	for _, user := range u.Processed {
-		userData = append(userData, user)
-	}
-	return userData
-}
+// UserData - Raw, processed and filtered users
+type UserData = internal.Data[UserEntity]
 
-// FetchRaw - Get the raw User data
-func (u *UserData) FetchRaw() []byte {
-	return u.Raw
-}
+// KeyMappings - Mapping of JSON field names to struct field names, derived from the json tags of UserEntity
+var KeyMappings = internal.FieldMappings[UserEntity]()
 
-// Fetch - Get list of users (usually after by UserData#FetchFiltered method) is called
-func (u User) Fetch() []interface{} {
-	var allUsers []interface{}
-	for _, ticket := range u {
-		allUsers = append(allUsers, ticket)
-	}
-	return allUsers
+// Model - Declaration of the user entity: each user shows its organization name, descriptions of tickets it submitted
+// and names of groups it is an agent of. Its user fields are searchable as user_field.<key>
+var Model = internal.Model[UserEntity]{
+	Name:         "user",
+	File:         "users.json",
+	KeyField:     "_id",
+	KeyMappings:  KeyMappings,
+	CustomPrefix: CustomFieldPrefix,
+	Relationships: []internal.Relationship{
+		{Field: "organization_name", Entity: "organization", LocalKey: "organization_id", ForeignKey: "_id", Display: "name"},
+		{Field: "tickets", Entity: "ticket", LocalKey: "_id", ForeignKey: "submitter_id", Display: "description"},
+		{Field: "groups", Entity: "group", LocalKey: "_id", ForeignKey: "agent_ids", Display: "name"},
+	},
 }
@@ -97,3 +97,18 @@ func (suite *TestSuite) TestUserData_FetchProcessed() {
 		}
 	})
 }
+
+func (suite *TestSuite) TestUser_CustomFields() {
+	suite.Run("Test user fields are searchable by name, and unknown fields are preserved", func() {
+		suite.Equal(map[string]any{"user_field.plan": "enterprise", "user_field.seats": float64(25)}, suite.user[1].FetchCustomFields())
+		suite.Equal(map[string]any{}, suite.user[0].FetchCustomFields())
+		suite.Equal(json.RawMessage("true"), suite.user[1].Extra["moderator"])
+
+		data, err := json.Marshal(suite.user[1])
+		suite.Nil(err)
+		var user UserEntity
+		suite.Nil(json.Unmarshal(data, &user))
+		suite.Equal(suite.user[1], user) // Unknown fields survive a round trip
+		suite.Contains(string(data), `"moderator":true`)
+	})
+}
@@ -0,0 +1,371 @@
+// Package zsearch -
+//
+// Search engine of users, organizations, tickets and their related entities, as used by the CLI. Services can embed
+// it by loading a Dataset once, and searching it for entities of any type:
+//
+//	dataset, err := zsearch.Load(ctx)
+//	...
+//	admins, err := zsearch.Search(ctx, dataset, zsearch.NewQuery(zsearch.Users).Where("role", "admin").Where("active", "true"))
+//
+// Data files are read from the current directory, or from the directory set in the DATA_DIR environment variable.
+// Services with data files of their own read them from a directory instead:
+//
+//	dataset, err := zsearch.LoadFrom(ctx, "/srv/zendesk")
+//
+// Values are searched for the same way as `search` command does: by the type of the field, custom fields by their
+// friendly name, dates by date, timestamp or relative time, and missing, null or empty fields by a predicate
+//
+// This file is meant for loading data of entities, and searching it
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Dataset - Data of entities and definitions of custom fields, loaded once and searched any number of times. Safe for
+// concurrent use, as searches never change the data, see `table.go`
+type Dataset struct {
+	entities    map[string]internal.DataProcessor
+	tables      map[string]*table // Entities parsed once when loaded, searched by every search
+	definitions internal.FieldDefinitions
+}
+
+/*
+*		Load data of entities by their names (eg. user, ticket) from the directory data files are read from by default:
+*		DATA_DIR if it is set, and otherwise the current directory. See LoadFrom
+*
+*	    @return (*Dataset, error): Loaded data, and error if reading or parsing of any named data file failed
+ */
+func Load(ctx context.Context, names ...string) (*Dataset, error) {
+	return LoadFrom(ctx, internal.DataDir(), names...)
+}
+
+/*
+*		Load data of entities by their names (eg. user, ticket) from the data files of a directory, or of all registered
+*		entities if no names are given, along with definitions of custom fields. Entities related to them are loaded as
+*		well, but any which fail to load are left out, so that results are still found without their details. Data
+*		files are read and parsed in parallel, and loading stops at the first named data file which fails, or once the
+*		context is done
+*
+*	    @return (*Dataset, error): Loaded data, and error if reading or parsing of any named data file failed
+ */
+func LoadFrom(ctx context.Context, dir string, names ...string) (*Dataset, error) {
+	var entities []internal.Entity
+	for _, name := range names {
+		entity, ok := models.Registry.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("%w %v", ErrUnknownEntity, name)
+		}
+		entities = append(entities, entity)
+	}
+	if len(names) == 0 {
+		entities = models.Registry.All()
+	}
+	loaded := time.Now()
+	dataset := &Dataset{entities: map[string]internal.DataProcessor{}, tables: map[string]*table{}}
+	var mutex sync.Mutex // Guards entities of the dataset, as they are loaded
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, entity := range entities {
+		entity := entity
+		group.Go(func() error {
+			if err := groupCtx.Err(); err != nil {
+				return err
+			}
+			start := time.Now()
+			data, err := loadEntityData(dir, entity)
+			if err != nil {
+				return err
+			}
+			parsed, err := newTable(data)
+			if err != nil {
+				return &LoadError{File: entity.DataFile(), Err: err}
+			}
+			log.WithFields(log.Fields{
+				"entity":   entity.EntityName(),
+				"file":     filepath.Join(dir, entity.DataFile()),
+				"records":  len(data.FetchProcessed()),
+				"duration": time.Since(start),
+			}).Debug("Loaded data")
+			mutex.Lock()
+			defer mutex.Unlock()
+			dataset.entities[entity.EntityName()] = data
+			dataset.tables[entity.EntityName()] = parsed
+			return nil
+		})
+	}
+	var related map[string]internal.DataProcessor
+	group.Go(func() error {
+		related = loadRelatedData(groupCtx, dir, relatedEntities(entities))
+		return nil
+	})
+	group.Go(func() error {
+		definitions, err := internal.LoadFieldDefinitions(dir)
+		if err != nil {
+			return &LoadError{File: internal.FieldDefinitionsFile, Err: err}
+		}
+		dataset.definitions = definitions
+		return nil
+	})
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err // Related entities are left out once the context is done, rather than failing
+	}
+	for name, data := range related {
+		parsed, err := newTable(data)
+		if err != nil {
+			log.Errorf("Encountered error while parsing related %v data: %v", name, err)
+			continue
+		}
+		dataset.entities[name] = data
+		dataset.tables[name] = parsed
+	}
+	traceFrom(ctx).Observe(PhaseLoad, time.Since(loaded))
+	return dataset, nil
+}
+
+/*
+*		Load data of an entity from its data file in a directory
+*
+*	    @return (DataProcessor, error): Loaded entities and a LoadError if reading or parsing the file failed
+ */
+func loadEntityData(dir string, entity internal.Entity) (internal.DataProcessor, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, entity.DataFile()))
+	if err != nil {
+		return nil, &LoadError{File: entity.DataFile(), Err: err}
+	}
+	data, err := entity.Load(raw)
+	if err != nil {
+		return nil, &LoadError{File: entity.DataFile(), Err: err}
+	}
+	return data, nil
+}
+
+// Entities related to any of the entities, other than the entities themselves
+func relatedEntities(entities []internal.Entity) []internal.Entity {
+	seen := map[string]bool{}
+	for _, entity := range entities {
+		seen[entity.EntityName()] = true
+	}
+	var related []internal.Entity
+	for _, entity := range entities {
+		for _, relation := range entity.Relations() {
+			if seen[relation.Entity] {
+				continue
+			}
+			seen[relation.Entity] = true
+			relatedEntity, ok := models.Registry.Get(relation.Entity)
+			if !ok {
+				log.Errorf("Unknown entity %v related to %v", relation.Entity, entity.EntityName())
+				continue
+			}
+			related = append(related, relatedEntity)
+		}
+	}
+	return related
+}
+
+/*
+*		Load data of related entities from a directory in parallel. Entities which fail to load, or are not loaded yet
+*		once the context is done, are left out
+*
+*	    @return (map[string]DataProcessor): Loaded entities by entity name
+ */
+func loadRelatedData(ctx context.Context, dir string, entities []internal.Entity) map[string]internal.DataProcessor {
+	related := map[string]internal.DataProcessor{}
+	var mutex sync.Mutex // Guards related entities, as they are loaded
+	var group errgroup.Group
+	for _, entity := range entities {
+		entity := entity
+		group.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+			data, err := loadEntityData(dir, entity)
+			if err != nil {
+				log.Errorf("Encountered error while loading related %v data: %v", entity.EntityName(), err)
+				return nil
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			related[entity.EntityName()] = data
+			return nil
+		})
+	}
+	_ = group.Wait()
+	return related
+}
+
+// All - All entities of a type in the dataset, without their related entities
+func (d *Dataset) All(name string) []interface{} {
+	data := d.entities[name]
+	if data == nil {
+		return nil
+	}
+	return data.FetchProcessed()
+}
+
+/*
+*		Find entities of a type matching all conditions, along with their related entities. Each condition is evaluated
+*		on the entities matching the ones before it, and the matching entities are copied before their related entities
+*		are added, so that the data of the dataset is never changed by a search. Conditions estimated to match the
+*		fewest entities are evaluated first, see planConditions, and the plan and timing of the search are recorded in
+*		the trace of the context, if any, see WithTrace
+*
+*	    @return ([]interface{}, error): Matching entities, and error if any condition is invalid or the context is done
+ */
+func (d *Dataset) Find(ctx context.Context, name string, conditions ...Condition) ([]interface{}, error) {
+	start := time.Now()
+	entity, rows, err := d.search(ctx, name, conditions...)
+	if err != nil {
+		return nil, err
+	}
+	all, err := d.enrich(ctx, entity, rows)
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"entity":     name,
+		"conditions": len(conditions),
+		"results":    len(all),
+		"duration":   time.Since(start),
+	}).Debug("Searched data")
+	return all, nil
+}
+
+/*
+*		Rows of the entities of a type matching all conditions, see Find
+*
+*	    @return (internal.Entity, []int, error): Entity searched, matching rows of its table, and error if any condition
+*		is invalid or the context is done
+ */
+func (d *Dataset) search(ctx context.Context, name string, conditions ...Condition) (internal.Entity, []int, error) {
+	entity, ok := models.Registry.Get(name)
+	entities := d.tables[name]
+	if !ok || entities == nil {
+		return nil, nil, fmt.Errorf("%w %v", ErrUnknownEntity, name)
+	}
+	trace := traceFrom(ctx)
+	rows := entities.rows()
+	conditions, estimates := planConditions(conditions, entity, entities.records, d.definitions)
+	for i, condition := range conditions {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		count := len(rows)
+		if count == 0 {
+			break
+		}
+		evaluated := time.Now()
+		m, err := compileCondition(condition, entity, d.definitions)
+		if err == nil {
+			rows, err = entities.filter(rows, m)
+		}
+		duration := time.Since(evaluated)
+		trace.Observe(PhaseMatch, duration)
+		if err != nil {
+			return nil, nil, d.conditionError(entity, condition, err)
+		}
+		log.WithFields(log.Fields{
+			"entity":     name,
+			"field":      condition.Name,
+			"value_type": valueType(condition, entity, d.definitions),
+			"duration":   duration,
+			"results":    len(rows),
+		}).Debug("Evaluated condition")
+		trace.record(Step{
+			Entity:    name,
+			Condition: describeCondition(condition),
+			Strategy:  strategyOf(condition, entity, d.definitions),
+			Searched:  count,
+			Estimated: estimates[i],
+			Actual:    len(rows),
+			Duration:  duration,
+		})
+	}
+	return entity, rows, nil
+}
+
+// Error of a condition of a search of an entity, with the closest fields to it if its field is unknown
+func (d *Dataset) conditionError(entity internal.Entity, condition Condition, err error) *SearchError {
+	if isUnknownField(err) {
+		return &SearchError{
+			Entity:      entity.EntityName(),
+			Field:       condition.Name,
+			Err:         fmt.Errorf("%w %v", ErrUnknownField, condition.Name),
+			Suggestions: d.suggestFields(entity, condition.Name, 3),
+		}
+	}
+	return &SearchError{Entity: entity.EntityName(), Field: condition.Name, Err: err}
+}
+
+/*
+*		Copies of the entities of rows of a type, along with their related entities
+*
+*	    @return ([]interface{}, error): Entities, and error of the context, if it is done before they are all enriched
+ */
+func (d *Dataset) enrich(ctx context.Context, entity internal.Entity, rows []int) ([]interface{}, error) {
+	enriched := time.Now()
+	records := d.tables[entity.EntityName()].copyRows(rows)
+	if err := addRelatedEntities(ctx, records, entity, d.entities); err != nil {
+		return nil, err
+	}
+	traceFrom(ctx).Observe(PhaseEnrich, time.Since(enriched))
+	return entitiesOf(records), nil
+}
+
+/*
+*		Type of the value of a condition, for logs: the type of its field (eg. int, []string, timestamp), of a custom
+*		field (by its definition), or predicate if it is searched for by a predicate instead
+ */
+func valueType(condition Condition, entity internal.Entity, definitions internal.FieldDefinitions) string {
+	if condition.Predicate != "" {
+		return "predicate"
+	}
+	if definition, ok := findCustomField(condition.Name, entity, definitions); ok {
+		if definition.Type == "" {
+			return "custom"
+		}
+		return "custom " + definition.Type
+	}
+	fieldType := entity.FieldType(condition.Name)
+	switch {
+	case fieldType == nil:
+		return "unknown"
+	case fieldType == reflect.TypeOf(internal.Timestamp{}):
+		return "timestamp"
+	default:
+		return fieldType.String()
+	}
+}
+
+/*
+*		Get an entity of a type by its primary key, along with its related entities
+*
+*	    @return (interface{}, error): Entity, and error wrapping ErrNotFound if there is no entity with the key
+ */
+func (d *Dataset) Get(ctx context.Context, name, id string) (interface{}, error) {
+	entity, ok := models.Registry.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w %v", ErrUnknownEntity, name)
+	}
+	results, err := d.Find(ctx, name, Condition{Name: entity.PrimaryKey(), Value: id})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%v %v %w", name, id, ErrNotFound)
+	}
+	return results[0], nil
+}
@@ -0,0 +1,316 @@
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/errgroup"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Directory of the test data shared by all packages, relative to the directory of a package
+var testDataDir = filepath.Join("..", "..", "testdata")
+
+// Test loading and searching of a dataset, through both untyped and typed results
+
+func loadTestDataset(t *testing.T) *Dataset {
+	dataset, err := LoadFrom(context.Background(), testDataDir) // Data files are read from the test data shared by all packages
+	assert.Nil(t, err)
+	return dataset
+}
+
+// Dataset of data of entities by entity name, as if it was loaded from data files
+func newTestDataset(t *testing.T, entities map[string]internal.DataProcessor, definitions internal.FieldDefinitions) *Dataset {
+	dataset := &Dataset{entities: entities, tables: map[string]*table{}, definitions: definitions}
+	for name, data := range entities {
+		parsed, err := newTable(data)
+		assert.Nil(t, err)
+		dataset.tables[name] = parsed
+	}
+	return dataset
+}
+
+// IDs of entities, in the order they were found
+func idsOf(entities []interface{}) []any {
+	var ids []any
+	for _, entity := range entities {
+		ids = append(ids, reflect.ValueOf(entity).FieldByName("Id").Interface())
+	}
+	return ids
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, testDataDir) // Data files are read from the test data shared by all packages
+
+	t.Run("test named entities are loaded along with their related entities", func(t *testing.T) {
+		dataset, err := Load(context.Background(), "ticket")
+		assert.Nil(t, err)
+		assert.Len(t, dataset.All("ticket"), 4)
+		assert.Len(t, dataset.All("user"), 5)
+		assert.Nil(t, dataset.All("rating"))
+	})
+	t.Run("test unknown entity", func(t *testing.T) {
+		_, err := Load(context.Background(), "widget")
+		assert.True(t, errors.Is(err, ErrUnknownEntity))
+	})
+	t.Run("test loading stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Load(ctx)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestLoadFrom(t *testing.T) {
+	t.Setenv(internal.DataDirEnv, t.TempDir()) // Directory data files are read from by default, without any data files
+
+	t.Run("test data files are read from the directory, rather than by default", func(t *testing.T) {
+		dataset, err := LoadFrom(context.Background(), testDataDir, "ticket")
+		assert.Nil(t, err)
+		assert.Len(t, dataset.All("ticket"), 4)
+		assert.Len(t, dataset.All("user"), 5)
+		_, ok := dataset.definitions.Find("ticket", "product")
+		assert.True(t, ok, "Definitions of custom fields are read from the directory")
+
+		_, err = Load(context.Background(), "ticket")
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+}
+
+func TestDataset_Concurrent(t *testing.T) {
+	dataset := loadTestDataset(t)
+
+	t.Run("test concurrent loads and searches", func(t *testing.T) {
+		var group errgroup.Group
+		for i := 0; i < 8; i++ {
+			group.Go(func() error {
+				loaded, err := LoadFrom(context.Background(), testDataDir, "user")
+				if err != nil {
+					return err
+				}
+				if len(loaded.All("organization")) != 5 {
+					return fmt.Errorf("related organizations not loaded")
+				}
+				return nil
+			})
+			group.Go(func() error {
+				results, err := dataset.Find(context.Background(), "user", Condition{Name: "role", Value: "end-user"}, Condition{Name: "organization_id", Value: "114"})
+				if err != nil {
+					return err
+				}
+				if len(results) != 2 || results[0].(User).OrganizationName != "Isotronic" {
+					return fmt.Errorf("unexpected results %v", results)
+				}
+				return nil
+			})
+		}
+		assert.Nil(t, group.Wait())
+	})
+	t.Run("test searches stop when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := dataset.Find(ctx, "user", Condition{Name: "role", Value: "end-user"})
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestDataset_Find(t *testing.T) {
+	dataset := loadTestDataset(t)
+	ctx := context.Background()
+
+	t.Run("test entities matching all conditions, along with their related entities", func(t *testing.T) {
+		results, err := dataset.Find(ctx, "user", Condition{Name: "role", Value: "end-user"}, Condition{Name: "organization_id", Value: "114"})
+		assert.Nil(t, err)
+		assert.Len(t, results, 2)
+		assert.Equal(t, "Isotronic", results[0].(User).OrganizationName)
+	})
+	t.Run("test related entities are added to results only, not to the data searched", func(t *testing.T) {
+		_, err := dataset.Find(ctx, "user", Condition{Name: "organization_id", Value: "114"})
+		assert.Nil(t, err)
+		for _, record := range dataset.All("user") {
+			assert.Empty(t, record.(User).OrganizationName)
+		}
+	})
+	t.Run("test all entities without conditions", func(t *testing.T) {
+		results, err := dataset.Find(ctx, "organization")
+		assert.Nil(t, err)
+		assert.Len(t, results, 5)
+	})
+	t.Run("test no entities matching", func(t *testing.T) {
+		results, err := dataset.Find(ctx, "user", Condition{Name: "role", Value: "admin"}, Condition{Name: "organization_id", Value: "114"})
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{}, results)
+	})
+	t.Run("test unknown field", func(t *testing.T) {
+		_, err := dataset.Find(ctx, "user", Condition{Name: "nickname", Value: "abc"})
+		assert.True(t, errors.Is(err, ErrUnknownField))
+		assert.Equal(t, "invalid search of users: unknown field nickname", err.Error())
+	})
+	t.Run("test unknown entity", func(t *testing.T) {
+		_, err := dataset.Find(ctx, "widget")
+		assert.True(t, errors.Is(err, ErrUnknownEntity))
+	})
+	t.Run("test search stops when the context is done", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := dataset.Find(cancelled, "user", Condition{Name: "role", Value: "admin"})
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestDataset_FindRelatedNames(t *testing.T) {
+	dataset := loadTestDataset(t)
+	tests := []struct {
+		entity    string
+		condition Condition
+		related   map[string]any
+	}{
+		{
+			entity:    "comment",
+			condition: Condition{Name: "via", Value: "chat"},
+			related:   map[string]any{"author_name": "Melissa Bishop", "ticket_subject": "A Nuisance in Greenland"},
+		},
+		{
+			entity:    "rating",
+			condition: Condition{Name: "score", Value: "bad"},
+			related: map[string]any{
+				"ticket_subject": "A Problem in Gambia", "assignee_name": "Catalina Simpson",
+				"requester_name": "Moran Daniels", "group_name": "Support",
+			},
+		},
+		{
+			entity:    "group",
+			condition: Condition{Name: "name", Value: "Support"},
+			related:   map[string]any{"agents": []any{"Catalina Simpson", "Melissa Bishop"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("test "+tt.entity+" found along with the names of its related entities", func(t *testing.T) {
+			results, err := dataset.Find(context.Background(), tt.entity, tt.condition)
+			assert.Nil(t, err)
+			assert.Len(t, results, 1)
+			raw, err := json.Marshal(results[0])
+			assert.Nil(t, err)
+			var fields map[string]any
+			assert.Nil(t, json.Unmarshal(raw, &fields))
+			for field, value := range tt.related {
+				assert.Equal(t, value, fields[field], field)
+			}
+		})
+	}
+}
+
+func TestDataset_Get(t *testing.T) {
+	dataset := loadTestDataset(t)
+	ctx := context.Background()
+
+	t.Run("test entity by its primary key", func(t *testing.T) {
+		result, err := dataset.Get(ctx, "user", "22")
+		assert.Nil(t, err)
+		assert.Equal(t, "Moran Daniels", result.(User).Name)
+	})
+	t.Run("test unknown key", func(t *testing.T) {
+		_, err := dataset.Get(ctx, "user", "1")
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func TestSearch(t *testing.T) {
+	dataset := loadTestDataset(t)
+	ctx := context.Background()
+
+	t.Run("test typed results of a query", func(t *testing.T) {
+		tickets, err := Search(ctx, dataset, NewQuery(Tickets).Where("status", "pending").Where("organization_id", "102"))
+		assert.Nil(t, err)
+		assert.Len(t, tickets, 1)
+		assert.Equal(t, "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", tickets[0].Id)
+		assert.Equal(t, "Geekfarm", tickets[0].OrganizationName)
+	})
+	t.Run("test query of a predicate", func(t *testing.T) {
+		users, err := Search(ctx, dataset, NewQuery(Users).Is("alias", PredicateNull))
+		assert.Nil(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, 74, users[0].Id)
+	})
+	t.Run("test query limit", func(t *testing.T) {
+		organizations, err := Search(ctx, dataset, NewQuery(Organizations).Limit(2))
+		assert.Nil(t, err)
+		assert.Len(t, organizations, 2)
+	})
+	t.Run("test query in a timezone", func(t *testing.T) {
+		location, _ := time.LoadLocation("UTC")
+		users, err := Search(ctx, dataset, NewQuery(Users).Where("_id", "22").In(location))
+		assert.Nil(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "+00:00", users[0].CreatedAt.Format("-07:00"))
+	})
+	t.Run("test typed entity by its primary key", func(t *testing.T) {
+		organization, err := Get(ctx, dataset, Organizations, "107")
+		assert.Nil(t, err)
+		assert.Equal(t, "Terrasys", organization.Name)
+		_, err = Get(ctx, dataset, Organizations, "1")
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func TestQuery_Conditions(t *testing.T) {
+	t.Run("test conditions in order they were added, in the timezone of the query", func(t *testing.T) {
+		location, _ := time.LoadLocation("Australia/Melbourne")
+		query := NewQuery(Users).Where("role", "admin").Is("alias", PredicateMissing).In(location)
+		assert.Equal(t, []Condition{
+			{Name: "role", Value: "admin", Location: location},
+			{Name: "alias", Predicate: PredicateMissing, Location: location},
+		}, query.Conditions())
+		assert.Equal(t, "user", Users.Name())
+	})
+}
+
+func TestErrors(t *testing.T) {
+	dataset := loadTestDataset(t)
+	ctx := context.Background()
+
+	t.Run("test unknown field is a search error, along with the closest fields", func(t *testing.T) {
+		_, err := dataset.Find(ctx, "user", Condition{Name: "emial", Value: "abc"})
+		var searchError *SearchError
+		assert.True(t, errors.As(err, &searchError))
+		assert.Equal(t, "user", searchError.Entity)
+		assert.Equal(t, "emial", searchError.Field)
+		assert.Equal(t, []string{"email"}, searchError.Suggestions)
+		assert.True(t, errors.Is(err, ErrUnknownField))
+		assert.False(t, errors.Is(err, ErrInvalidValue))
+	})
+	t.Run("test value of the wrong type is an invalid value", func(t *testing.T) {
+		_, err := dataset.Find(ctx, "user", Condition{Name: "created_at", Value: "yesterday-ish"})
+		var valueError *ValueError
+		assert.True(t, errors.As(err, &valueError))
+		assert.Equal(t, "created_at", valueError.Name)
+		assert.True(t, errors.Is(err, ErrInvalidValue))
+		assert.Equal(t, "invalid search of users: Please specify a date (2016-04-15), timestamp (2016-04-15T05:19:46 -10:00) or relative time (now, today, 30d ago) as --value associated with --name of created_at", err.Error())
+	})
+	t.Run("test errors in queries match their cause", func(t *testing.T) {
+		statement, err := ParseQuery("user where verified = maybe")
+		assert.Nil(t, err)
+		_, err = dataset.Query(ctx, statement)
+		assert.True(t, errors.Is(err, ErrInvalidQuery))
+		assert.True(t, errors.Is(err, ErrInvalidValue))
+
+		_, err = ParseQuery("user wher")
+		assert.True(t, errors.Is(err, ErrInvalidQuery))
+		assert.False(t, errors.Is(err, ErrUnknownField))
+	})
+	t.Run("test data file which fails to load", func(t *testing.T) {
+		_, err := LoadFrom(ctx, "missing", "user")
+		var loadError *LoadError
+		assert.True(t, errors.As(err, &loadError))
+		assert.Equal(t, "users.json", loadError.File)
+		assert.True(t, errors.Is(err, ErrLoad))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+}
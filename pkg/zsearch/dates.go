@@ -0,0 +1,131 @@
+// Package zsearch -
+//
+// This file is meant for searching date/time fields (eg. created_at, due_at), by full timestamp, by date only, or by
+// time relative to now, optionally compared with an operator (eg. '<30d ago', '>=2016-04-15', '<now')
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout - Layout of dates searched for without a time
+const DateLayout = "2006-01-02"
+
+// Operators comparing timestamps, in order they are matched as a prefix of the searched value
+var timeOperators = []string{"<=", ">=", "<", ">", "="}
+
+// Relative times, eg. '30d ago' or 'in 2w'
+var (
+	agoPattern = regexp.MustCompile(`^(\d+)\s*([smhdw])\s+ago$`)
+	inPattern  = regexp.MustCompile(`^in\s+(\d+)\s*([smhdw])$`)
+)
+
+// now - Current time, replaced in tests
+var now = time.Now
+
+// timeQuery - Parsed value searched for in a date/time field. Dates are compared by calendar day, in the selected
+// timezone or else in the timezone each timestamp was stored with, and everything else is compared as an instant
+type timeQuery struct {
+	operator string
+	instant  time.Time
+	date     time.Time
+	isDate   bool
+}
+
+/*
+*	Parse value searched for in a date/time field: a date (2016-04-15), a full timestamp (2016-04-15T05:19:46 -10:00),
+*	or a time relative to now (now, today, yesterday, 30d ago, in 2w), optionally prefixed by an operator
+*	(<, <=, >, >=, =). Relative times are compared by day, unless an operator is given
+ */
+func parseTimeQuery(value, name string, location *time.Location) (timeQuery, error) {
+	query := timeQuery{operator: "="}
+	value = strings.TrimSpace(value)
+	for _, operator := range timeOperators {
+		if strings.HasPrefix(value, operator) {
+			query.operator = operator
+			value = strings.TrimSpace(strings.TrimPrefix(value, operator))
+			break
+		}
+	}
+	current := now()
+	if location != nil {
+		current = current.In(location)
+	}
+	if match := agoPattern.FindStringSubmatch(value); match != nil {
+		current = current.Add(-relativeDuration(match))
+		value = "now"
+	} else if match := inPattern.FindStringSubmatch(value); match != nil {
+		current = current.Add(relativeDuration(match))
+		value = "now"
+	}
+	switch value {
+	case "now":
+		query.instant = current
+		query.isDate = query.operator == "="
+		query.date = dateOf(current)
+		return query, nil
+	case "today":
+		query.isDate, query.date = true, dateOf(current)
+		return query, nil
+	case "yesterday":
+		query.isDate, query.date = true, dateOf(current).AddDate(0, 0, -1)
+		return query, nil
+	}
+	if date, err := time.Parse(DateLayout, value); err == nil {
+		query.isDate, query.date = true, date
+		return query, nil
+	}
+	for _, layout := range []string{internal.TimestampLayout, time.RFC3339} {
+		if instant, err := time.Parse(layout, value); err == nil {
+			query.instant = instant
+			return query, nil
+		}
+	}
+	return query, &ValueError{Name: name, Expected: "a date (2016-04-15), timestamp (2016-04-15T05:19:46 -10:00) or relative time (now, today, 30d ago) as"}
+}
+
+// Duration of a matched relative time
+func relativeDuration(match []string) time.Duration {
+	amount, _ := strconv.Atoi(match[1])
+	return time.Duration(amount) * internal.DurationUnits[match[2]]
+}
+
+// Calendar day of a time, in the timezone of the time
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+/*
+*	Match a timestamp against the query. Missing timestamps never match
+ */
+func (q timeQuery) match(timestamp internal.Timestamp, location *time.Location) bool {
+	if timestamp.IsZero() {
+		return false
+	}
+	var compared int
+	if q.isDate {
+		t := timestamp.Time
+		if location != nil {
+			t = t.In(location)
+		}
+		compared = dateOf(t).Compare(q.date)
+	} else {
+		compared = timestamp.Truncate(time.Second).Compare(q.instant.Truncate(time.Second))
+	}
+	switch q.operator {
+	case "<":
+		return compared < 0
+	case "<=":
+		return compared <= 0
+	case ">":
+		return compared > 0
+	case ">=":
+		return compared >= 0
+	default:
+		return compared == 0
+	}
+}
@@ -0,0 +1,98 @@
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/tickets"
+	"context"
+	"time"
+)
+
+// Test searching for dates, timestamps and relative times
+
+func (suite *TestSuite) TestFind_Dates() {
+	now = func() time.Time {
+		return time.Date(2016, 7, 5, 12, 0, 0, 0, time.FixedZone("-10:00", -10*60*60))
+	}
+	defer func() { now = time.Now }()
+	melbourne, _ := internal.LoadTimezone("+10:00")
+	testsSuccess := []struct {
+		title     string
+		condition Condition
+		ids       []any
+	}{
+		{
+			title:     "date, in timezone of each timestamp",
+			condition: Condition{Name: "created_at", Value: "2016-03-25"},
+			ids:       []any{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"},
+		},
+		{
+			title:     "date, in selected timezone",
+			condition: Condition{Name: "created_at", Value: "2016-03-26", Location: melbourne},
+			ids:       []any{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"},
+		},
+		{
+			title:     "date before",
+			condition: Condition{Name: "created_at", Value: "<2016-05-15"},
+			ids:       []any{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"},
+		},
+		{
+			title:     "date on or before",
+			condition: Condition{Name: "created_at", Value: "<= 2016-05-15"},
+			ids:       []any{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", "3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "date after",
+			condition: Condition{Name: "created_at", Value: ">2016-05-15"},
+			ids:       []any{"7c67b6ed-6776-4065-bd4a-f2d9d12c33b7"},
+		},
+		{
+			title:     "timestamp, in the same layout as the data",
+			condition: Condition{Name: "created_at", Value: "2016-05-15T12:59:16 -10:00"},
+			ids:       []any{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "timestamp, in another timezone",
+			condition: Condition{Name: "created_at", Value: "2016-05-15T22:59:16Z"},
+			ids:       []any{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "relative time without operator, by day",
+			condition: Condition{Name: "created_at", Value: "2d ago"},
+			ids:       []any{"7c67b6ed-6776-4065-bd4a-f2d9d12c33b7"},
+		},
+		{
+			title:     "relative time with operator",
+			condition: Condition{Name: "created_at", Value: "<30d ago"},
+			ids:       []any{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", "3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "future relative time",
+			condition: Condition{Name: "due_at", Value: "<in 6w"},
+			ids:       []any{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "yesterday",
+			condition: Condition{Name: "created_at", Value: "yesterday"},
+			ids:       nil,
+		},
+		{
+			title:     "now",
+			condition: Condition{Name: "due_at", Value: ">now"},
+			ids:       []any{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", "3ff0599a-fe0f-4f8f-ac31-e2636843bcea", "7c67b6ed-6776-4065-bd4a-f2d9d12c33b7"},
+		},
+	}
+	for _, tt := range testsSuccess {
+		suite.Run(tt.title, func() {
+			results, err := suite.dataset.Find(context.Background(), tickets.Model.Name, tt.condition)
+			suite.Nil(err)
+			suite.Equal(tt.ids, idsOf(results))
+		})
+	}
+	for _, value := range []string{"last tuesday", "2016-13-01", "<<2016-01-01", "3 fortnights ago"} {
+		suite.Run("invalid date "+value, func() {
+			results, err := suite.dataset.Find(context.Background(), tickets.Model.Name, Condition{Name: "created_at", Value: value})
+			suite.Nil(results)
+			suite.NotNil(err)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Package zsearch -
+//
+// This file is meant for relating entities to each other in both directions, from the relationships declared by their
+// models (eg. organization of a ticket, and tickets of an organization)
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Edge - Relationship of an entity to entities it is related to, named after the relationship (eg. submitter)
+type Edge struct {
+	Name       string
+	Entity     internal.Entity // Related entity
+	LocalKey   string          // Field of the entity, matching ForeignKey of related entities
+	ForeignKey string
+	List       bool // Whether the entity has a list of related entities, rather than one
+}
+
+// Edges of all registered entities, built once
+var edges = sync.OnceValue(buildEdges)
+
+// Edges - Edges of every entity by entity name, see buildEdges
+func Edges() map[string][]Edge {
+	return edges()
+}
+
+// Edge of an entity by its name
+func findEdge(entity internal.Entity, name string) (Edge, bool) {
+	for _, e := range Edges()[entity.EntityName()] {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Edge{}, false
+}
+
+/*
+*		Edges of every entity, from the relationships of entities. Each relationship to a single entity by its primary key
+*		(eg. submitter of a ticket) is also an edge back from the related entity to a list of entities (eg. tickets of
+*		a submitter), unless the related entity already has the same relationship
+*
+*	    @return (map[string][]Edge): Edges by entity name
+ */
+func buildEdges() map[string][]Edge {
+	edges := map[string][]Edge{}
+	names := map[string]map[string]bool{}
+	add := func(entity string, e Edge) {
+		if names[entity] == nil {
+			names[entity] = map[string]bool{}
+		}
+		names[entity][e.Name] = true
+		edges[entity] = append(edges[entity], e)
+	}
+	for _, entity := range models.Registry.All() {
+		for _, relation := range entity.Relations() {
+			related, ok := models.Registry.Get(relation.Entity)
+			if !ok {
+				continue
+			}
+			localType := entity.FieldType(relation.LocalKey)
+			add(entity.EntityName(), Edge{
+				Name:       strings.TrimSuffix(relation.Field, "_"+relation.Display), // eg. organization_name is organization
+				Entity:     related,
+				LocalKey:   relation.LocalKey,
+				ForeignKey: relation.ForeignKey,
+				List:       (localType != nil && localType.Kind() == reflect.Slice) || relation.ForeignKey != related.PrimaryKey(),
+			})
+		}
+	}
+	for _, entity := range models.Registry.All() {
+		for _, e := range append([]Edge{}, edges[entity.EntityName()]...) {
+			if e.List {
+				continue
+			}
+			reverse := Edge{Entity: entity, LocalKey: e.ForeignKey, ForeignKey: e.LocalKey, List: true}
+			duplicate := false
+			for _, existing := range edges[e.Entity.EntityName()] {
+				if existing.Entity.EntityName() == entity.EntityName() && existing.LocalKey == reverse.LocalKey && existing.ForeignKey == reverse.ForeignKey {
+					duplicate = true
+				}
+			}
+			if duplicate {
+				continue
+			}
+			reverse.Name = entity.EntityName() + "s" // eg. tickets of an organization
+			if names[e.Entity.EntityName()][reverse.Name] {
+				reverse.Name = e.Name + "_" + reverse.Name // eg. assignee_tickets of a user
+			}
+			add(e.Entity.EntityName(), reverse)
+		}
+	}
+	return edges
+}
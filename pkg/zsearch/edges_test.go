@@ -0,0 +1,33 @@
+package zsearch
+
+import (
+	"ZendeskChallenge/models/tickets"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEdges(t *testing.T) {
+	t.Run("test relationships are edges in both directions, named after the relationship", func(t *testing.T) {
+		edges := Edges()
+		names := func(entity string) []string {
+			var all []string
+			for _, e := range edges[entity] {
+				all = append(all, e.Name)
+			}
+			return all
+		}
+		assert.Equal(t, []string{"organization", "tickets", "groups", "assignee_tickets", "comments", "ratings", "requester_ratings"}, names("user"))
+		assert.Equal(t, []string{"users", "tickets"}, names("organization"))
+		assert.Equal(t, []string{"organization", "submitter", "assignee", "comments", "ratings"}, names("ticket"))
+		assert.Equal(t, []string{"agents", "ratings"}, names("group"))
+	})
+	t.Run("test edge by name", func(t *testing.T) {
+		e, ok := findEdge(tickets.Model, "submitter")
+		assert.True(t, ok)
+		assert.Equal(t, "user", e.Entity.EntityName())
+		assert.Equal(t, "submitter_id", e.LocalKey)
+		assert.False(t, e.List)
+		_, ok = findEdge(tickets.Model, "watchers")
+		assert.False(t, ok)
+	})
+}
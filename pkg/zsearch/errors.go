@@ -0,0 +1,75 @@
+// Package zsearch -
+//
+// This file is meant for errors of loading and searching data, which are told apart by errors.Is against the errors
+// below (eg. ErrUnknownField), or errors.As for their details (eg. the field of a SearchError)
+package zsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownEntity - Entity is not registered, or not loaded in the dataset
+	ErrUnknownEntity = errors.New("unknown entity")
+	// ErrUnknownField - Field searched for is not a searchable field of the entity
+	ErrUnknownField = errors.New("unknown field")
+	// ErrInvalidValue - Value searched for is not of the type of the field (eg. a word for an int field)
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrInvalidQuery - Query of the query language is invalid, see ParseError
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrLoad - Data file could not be read or parsed, see LoadError
+	ErrLoad = errors.New("failed to load data")
+	// ErrNotFound - There is no entity with the key
+	ErrNotFound = errors.New("not found")
+)
+
+// ValueError - Value searched for is not what the field expects, matching ErrInvalidValue
+type ValueError struct {
+	Name     string // Field searched for
+	Expected string // What the value should be, eg. int type of
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("Please specify %v --value associated with --name of %v\n", e.Expected, e.Name)
+}
+
+func (e *ValueError) Is(target error) bool {
+	return target == ErrInvalidValue
+}
+
+// SearchError - Invalid search of a field of an entity, wrapping the cause (eg. ErrUnknownField or a ValueError).
+// Suggestions are the closest searchable fields to an unknown field
+type SearchError struct {
+	Entity      string
+	Field       string
+	Err         error
+	Suggestions []string
+}
+
+func (e *SearchError) Error() string {
+	return fmt.Sprintf("invalid search of %v: %v", e.Entity+"s", strings.TrimSpace(e.Err.Error()))
+}
+
+func (e *SearchError) Unwrap() error {
+	return e.Err
+}
+
+// LoadError - Data file which could not be read or parsed, matching ErrLoad as well as the cause (eg. fs.ErrNotExist)
+type LoadError struct {
+	File string
+	Err  error
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("error occurred during parsing %v: %v", e.File, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+func (e *LoadError) Is(target error) bool {
+	return target == ErrLoad
+}
@@ -0,0 +1,188 @@
+// Package zsearch -
+//
+// This file is meant for fuzzy matching of string fields by edit distance, for searches which tolerate misspellings
+// (eg. "Fransisca" for Francisca Rasmussen), and for suggesting the closest values or field names when a search
+// finds nothing
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Similarity (from 0 to 1) of a value to a field for it to match a fuzzy search, or be suggested instead of the value
+const (
+	fuzzyMatchSimilarity = 0.7
+	suggestionSimilarity = 0.6
+)
+
+// similar - String field, or list of strings, with a value similar to the searched value
+type similar struct {
+	field string
+	value string
+}
+
+func (s similar) match(fields map[string]any) bool {
+	switch value := fields[s.field].(type) {
+	case string:
+		return fuzzyMatch(value, s.value)
+	case []any:
+		for _, item := range value {
+			if text, ok := item.(string); ok && fuzzyMatch(text, s.value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+*	Whether text matches a searched value fuzzily: case-insensitively, containing the value, or similar to it as a
+*	whole or in any of its runs of as many words as the value (eg. "rasmusen" matches "Francisca Rasmussen")
+ */
+func fuzzyMatch(text, value string) bool {
+	return similarity(text, value) >= fuzzyMatchSimilarity
+}
+
+/*
+*	Similarity of text to a value, from 0 (nothing in common) to 1 (text contains the value, ignoring case). Text is
+*	compared to the value as a whole, and by each run of as many words as the value, taking the closest of them
+ */
+func similarity(text, value string) float64 {
+	text, value = strings.ToLower(text), strings.ToLower(value)
+	if strings.Contains(text, value) {
+		return 1
+	}
+	best := ratio(text, value)
+	words, count := strings.Fields(text), len(strings.Fields(value))
+	for i := 0; count > 0 && i+count <= len(words); i++ {
+		best = max(best, ratio(strings.Join(words[i:i+count], " "), value))
+	}
+	return best
+}
+
+// Similarity of two strings by their edit distance, relative to the length of the longer of them
+func ratio(a, b string) float64 {
+	length := max(len([]rune(a)), len([]rune(b)))
+	if length == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(a, b))/float64(length)
+}
+
+/*
+*	Edit distance between two strings: the number of runes inserted, deleted or substituted, or adjacent runes swapped
+*	(eg. "nmae" for name), to turn one into the other
+ */
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	beforePrevious := make([]int, len(target)+1)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				current[j] = min(current[j], beforePrevious[j-2]+1)
+			}
+		}
+		beforePrevious, previous, current = previous, current, beforePrevious
+	}
+	return previous[len(target)]
+}
+
+/*
+*		Closest candidates to a value, most similar first, leaving out candidates which are not similar enough to be
+*		what was meant
+*
+*	    @return ([]string): Up to limit distinct candidates
+ */
+func Suggest(value string, candidates []string, limit int) []string {
+	type scored struct {
+		candidate string
+		score     float64
+	}
+	var suggestions []scored
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if seen[candidate] || candidate == value {
+			continue
+		}
+		seen[candidate] = true
+		if score := similarity(candidate, value); score >= suggestionSimilarity {
+			suggestions = append(suggestions, scored{candidate, score})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].score != suggestions[j].score {
+			return suggestions[i].score > suggestions[j].score
+		}
+		return suggestions[i].candidate < suggestions[j].candidate
+	})
+	var closest []string
+	for _, suggestion := range suggestions[:min(limit, len(suggestions))] {
+		closest = append(closest, suggestion.candidate)
+	}
+	return closest
+}
+
+/*
+*		Closest values of a string field of entities of a type to a value, for suggesting when a search of the value
+*		finds nothing. Values of lists of strings (eg. tags) are suggested by each of their items
+*
+*	    @return ([]string): Up to limit values, most similar first
+ */
+func (d *Dataset) SuggestValues(name, field, value string, limit int) []string {
+	entities := d.tables[name]
+	if entities == nil {
+		return nil
+	}
+	var candidates []string
+	for _, fields := range entities.fields {
+		switch fieldValue := fields[field].(type) {
+		case string:
+			candidates = append(candidates, fieldValue)
+		case []any:
+			for _, item := range fieldValue {
+				if text, ok := item.(string); ok {
+					candidates = append(candidates, text)
+				}
+			}
+		}
+	}
+	return Suggest(value, candidates, limit)
+}
+
+/*
+*		Closest searchable fields of entities of a type to a field name, including the friendly names of their custom
+*		fields, for suggesting when a field is unknown
+*
+*	    @return ([]string): Up to limit field names, most similar first
+ */
+func (d *Dataset) SuggestFields(name, field string, limit int) []string {
+	entity, ok := models.Registry.Get(name)
+	if !ok {
+		return nil
+	}
+	return d.suggestFields(entity, field, limit)
+}
+
+// Closest searchable fields of an entity to a field name, see SuggestFields
+func (d *Dataset) suggestFields(entity internal.Entity, field string, limit int) []string {
+	candidates := slices.Clone(entity.SearchableFields())
+	for _, definition := range d.definitions.ForEntity(entity.EntityName()) {
+		candidates = append(candidates, definition.Name)
+	}
+	return Suggest(field, candidates, limit)
+}
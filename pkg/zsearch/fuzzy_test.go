@@ -0,0 +1,98 @@
+package zsearch
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Test fuzzy matching of values, and suggestions of values and fields closest to ones which found nothing
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{a: "", b: "", distance: 0},
+		{a: "name", b: "", distance: 4},
+		{a: "name", b: "name", distance: 0},
+		{a: "nmae", b: "name", distance: 1},
+		{a: "Melisa", b: "Melissa", distance: 1},
+		{a: "kitten", b: "sitting", distance: 3},
+		{a: "東京", b: "京都", distance: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" to "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.distance, editDistance(tt.a, tt.b))
+			assert.Equal(t, tt.distance, editDistance(tt.b, tt.a))
+		})
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		text, value string
+		matched     bool
+	}{
+		{text: "Francisca Rasmussen", value: "francisca rasmussen", matched: true},
+		{text: "Francisca Rasmussen", value: "Fransisca Rasmusen", matched: true},
+		{text: "Francisca Rasmussen", value: "rasmusen", matched: true},
+		{text: "Francisca Rasmussen", value: "cisca", matched: true},
+		{text: "Francisca Rasmussen", value: "Moran Daniels", matched: false},
+		{text: "Ohio", value: "Iowa", matched: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.matched, fuzzyMatch(tt.text, tt.value))
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"Ohio", "Iowa", "Oregon", "Ohio", "Ohio River"}
+	t.Run("test closest candidates, most similar first", func(t *testing.T) {
+		assert.Equal(t, []string{"Ohio", "Ohio River"}, Suggest("Ohoi", candidates, 3))
+		assert.Equal(t, []string{"Oregon"}, Suggest("oregan", candidates, 3))
+	})
+	t.Run("test no candidates close enough", func(t *testing.T) {
+		assert.Nil(t, Suggest("Massachusetts", candidates, 3))
+	})
+	t.Run("test limit of candidates", func(t *testing.T) {
+		assert.Equal(t, []string{"Ohio"}, Suggest("Ohoi", candidates, 1))
+	})
+}
+
+func TestDataset_Suggest(t *testing.T) {
+	dataset := loadTestDataset(t)
+
+	t.Run("test values of a string field", func(t *testing.T) {
+		assert.Equal(t, []string{"Melissa Bishop"}, dataset.SuggestValues("user", "name", "Melisa Bishop", 3))
+	})
+	t.Run("test values of a list of strings", func(t *testing.T) {
+		assert.Equal(t, []string{"Ohio"}, dataset.SuggestValues("ticket", "tags", "Ohoi", 3))
+	})
+	t.Run("test fields, including friendly names of custom fields", func(t *testing.T) {
+		assert.Equal(t, []string{"email"}, dataset.SuggestFields("user", "emial", 1))
+		assert.Equal(t, []string{"product"}, dataset.SuggestFields("ticket", "prodcut", 1))
+	})
+	t.Run("test unknown entity", func(t *testing.T) {
+		assert.Nil(t, dataset.SuggestValues("widget", "name", "x", 3))
+		assert.Nil(t, dataset.SuggestFields("widget", "name", 3))
+	})
+}
+
+func TestSearch_Like(t *testing.T) {
+	dataset := loadTestDataset(t)
+
+	t.Run("test fuzzy query of a misspelled value", func(t *testing.T) {
+		users, err := Search(context.Background(), dataset, NewQuery(Users).Like("name", "moran danials"))
+		assert.Nil(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, 22, users[0].Id)
+	})
+	t.Run("test fuzzy query of a field which is not a string is exact", func(t *testing.T) {
+		users, err := Search(context.Background(), dataset, NewQuery(Users).Like("_id", "2"))
+		assert.Nil(t, err)
+		assert.Len(t, users, 0)
+	})
+}
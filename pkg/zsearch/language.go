@@ -0,0 +1,461 @@
+// Package zsearch -
+//
+// This file is meant for evaluating parsed queries of the query language (see `parser.go`) against a dataset. Each
+// comparison is compiled into conditions of the search engine where it can be (eg. status = open, created_at < 30d ago,
+// alias is null), and matched against the fields of entities otherwise (eg. name ~ "ras", _id > 10). Entities are
+// then combined by their primary keys, for and, or and not
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keySet - Primary keys of entities matching an expression
+type keySet map[string]bool
+
+// evaluator - Evaluates expressions of a statement against a dataset
+type evaluator struct {
+	ctx       context.Context
+	dataset   *Dataset
+	statement *Statement
+}
+
+/*
+*		Find entities matching a parsed query, sorted and limited as the query says. Only the entities left once they
+*		are limited are copied and have their related entities added
+*
+*	    @return ([]interface{}, error): Matching entities, and a ParseError pointing at any unknown field or invalid value
+ */
+func (d *Dataset) Query(ctx context.Context, statement *Statement) ([]interface{}, error) {
+	e := &evaluator{ctx: ctx, dataset: d, statement: statement}
+	for _, order := range statement.OrderBy {
+		if _, ok := statement.Entity.Mappings()[order.Field]; !ok {
+			return nil, e.wrapAt(order.Column, ErrUnknownField, "unknown field '%v' to order %v by", order.Field, statement.Entity.EntityName()+"s")
+		}
+	}
+	var keys keySet
+	if statement.Where != nil {
+		var err error
+		keys, err = e.evaluate(statement.Entity, statement.Where)
+		if err != nil {
+			return nil, err
+		}
+	}
+	entities := d.tables[statement.Entity.EntityName()]
+	if entities == nil {
+		return nil, fmt.Errorf("%w %v", ErrUnknownEntity, statement.Entity.EntityName())
+	}
+	var rows []int
+	for row, record := range entities.records {
+		if keys == nil || keys[primaryKey(record, statement.Entity)] {
+			rows = append(rows, row)
+		}
+	}
+	sortRows(rows, entities.records, statement.Entity, statement.OrderBy)
+	if statement.Limit > 0 && len(rows) > statement.Limit {
+		rows = rows[:statement.Limit]
+	}
+	return d.enrich(ctx, statement.Entity, rows)
+}
+
+// Error at a column of the query
+func (e *evaluator) errorAt(column int, format string, args ...any) error {
+	return e.wrapAt(column, nil, format, args...)
+}
+
+// Error at a column of the query, caused by an error other than its syntax (eg. ErrUnknownField)
+func (e *evaluator) wrapAt(column int, cause error, format string, args ...any) error {
+	return &ParseError{Query: e.statement.Query, Column: column, Message: fmt.Sprintf(format, args...), Err: cause}
+}
+
+// Keys of entities matching an expression
+func (e *evaluator) evaluate(entity internal.Entity, expression Expression) (keySet, error) {
+	if err := e.ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch node := expression.(type) {
+	case *Binary:
+		left, err := e.evaluate(entity, node.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.evaluate(entity, node.Right)
+		if err != nil {
+			return nil, err
+		}
+		keys := keySet{}
+		for key := range left {
+			if node.Operator == "or" || right[key] {
+				keys[key] = true
+			}
+		}
+		if node.Operator == "or" {
+			for key := range right {
+				keys[key] = true
+			}
+		}
+		return keys, nil
+	case *Not:
+		matching, err := e.evaluate(entity, node.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return e.except(entity, matching), nil
+	case *Comparison:
+		return e.compare(entity, node, node.Field)
+	default:
+		return nil, fmt.Errorf("unsupported expression %v", expression)
+	}
+}
+
+/*
+*		Keys of entities matching a comparison of a field. A field of related entities (eg. organization.name) matches
+*		entities related to any entity matching the comparison of that field
+ */
+func (e *evaluator) compare(entity internal.Entity, comparison *Comparison, field string) (keySet, error) {
+	if e.isField(entity, field) {
+		return e.compareField(entity, comparison, field)
+	}
+	name, rest, found := strings.Cut(field, ".")
+	edge, ok := findEdge(entity, name)
+	if !found || !ok {
+		return nil, e.wrapAt(comparison.Column, ErrUnknownField, "unknown field '%v' of %v", field, entity.EntityName()+"s")
+	}
+	related, err := e.compare(edge.Entity, comparison, rest)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys := keySet{}
+	for _, record := range e.dataset.All(edge.Entity.EntityName()) {
+		if related[primaryKey(record, edge.Entity)] {
+			for _, key := range indexKeys(fieldOf(record, edge.Entity, edge.ForeignKey)) {
+				foreignKeys[key] = true
+			}
+		}
+	}
+	keys := keySet{}
+	for _, record := range e.dataset.All(entity.EntityName()) {
+		for _, key := range indexKeys(fieldOf(record, entity, edge.LocalKey)) {
+			if foreignKeys[key] {
+				keys[primaryKey(record, entity)] = true
+			}
+		}
+	}
+	return keys, nil
+}
+
+// Whether a field is a searchable field of an entity, or one of its custom fields
+func (e *evaluator) isField(entity internal.Entity, field string) bool {
+	for _, searchable := range entity.SearchableFields() {
+		if searchable == field {
+			return true
+		}
+	}
+	_, ok := findCustomField(field, entity, e.dataset.definitions)
+	return ok
+}
+
+// Keys of entities matching a comparison of a field of their own
+func (e *evaluator) compareField(entity internal.Entity, comparison *Comparison, field string) (keySet, error) {
+	fieldType := entity.FieldType(field)
+	isTimestamp := fieldType == reflect.TypeOf(internal.Timestamp{})
+	if comparison.Operator == "=" || comparison.Operator == "!=" || comparison.Operator == "in" {
+		for _, value := range comparison.Values {
+			if err := validateValue(fieldType, value); err != nil {
+				return nil, e.wrapAt(comparison.Column, ErrInvalidValue, "invalid value of field '%v': %v", comparison.Field, err)
+			}
+		}
+	}
+	switch comparison.Operator {
+	case "=":
+		return e.find(entity, comparison, Condition{Name: field, Value: comparison.Values[0]})
+	case "!=":
+		matching, err := e.find(entity, comparison, Condition{Name: field, Value: comparison.Values[0]})
+		if err != nil {
+			return nil, err
+		}
+		return e.except(entity, matching), nil
+	case "in":
+		conditions := make([]Condition, len(comparison.Values))
+		for i, value := range comparison.Values {
+			conditions[i] = Condition{Name: field, Value: value}
+		}
+		return e.find(entity, comparison, conditions...)
+	case "is":
+		return e.find(entity, comparison, Condition{Name: field, Predicate: comparison.Predicate})
+	case "<", "<=", ">", ">=":
+		if isTimestamp {
+			return e.find(entity, comparison, Condition{Name: field, Value: comparison.Operator + comparison.Values[0]})
+		}
+		return e.match(entity, comparison, field, func(value any) (bool, error) {
+			return orderedMatch(value, comparison.Operator, comparison.Values[0])
+		})
+	case "~", "!~":
+		return e.match(entity, comparison, field, func(value any) (bool, error) {
+			return containsValue(value, comparison.Values[0]) == (comparison.Operator == "~"), nil
+		})
+	default:
+		return nil, e.errorAt(comparison.Column, "unsupported operator '%v'", comparison.Operator)
+	}
+}
+
+// Check a value can be searched for in a field of a type, which is a number or a boolean (or a list of numbers)
+func validateValue(fieldType reflect.Type, value string) error {
+	if fieldType == nil {
+		return nil
+	}
+	if fieldType.Kind() == reflect.Slice {
+		fieldType = fieldType.Elem()
+	}
+	switch fieldType.Kind() {
+	case reflect.Int:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("expected a number, found %q", value)
+		}
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("expected true or false, found %q", value)
+		}
+	}
+	return nil
+}
+
+/*
+*		Keys of entities matching any of the conditions, as searched for by the search engine, in one scan of the
+*		entities (eg. status in (open, pending) is matched as status = open or status = pending)
+ */
+func (e *evaluator) find(entity internal.Entity, comparison *Comparison, conditions ...Condition) (keySet, error) {
+	start := time.Now()
+	entities := e.dataset.tables[entity.EntityName()]
+	if entities == nil {
+		return nil, fmt.Errorf("%w %v", ErrUnknownEntity, entity.EntityName())
+	}
+	matchers := make([]recordMatcher, len(conditions))
+	descriptions := make([]string, len(conditions))
+	estimated := 0
+	for i, condition := range conditions {
+		condition.Location = e.statement.Location
+		m, err := compileCondition(condition, entity, e.dataset.definitions)
+		if err != nil {
+			return nil, e.invalid(comparison, e.dataset.conditionError(entity, condition, err))
+		}
+		matchers[i], descriptions[i] = m, describeCondition(condition)
+		estimated += estimateMatches(condition, entity, entities.records, e.dataset.definitions)
+	}
+	rows, err := entities.filter(entities.rows(), anyMatcher(matchers...))
+	if err != nil {
+		return nil, e.invalid(comparison, &SearchError{Entity: entity.EntityName(), Field: comparison.Field, Err: err})
+	}
+	keys := keySet{}
+	for _, row := range rows {
+		keys[primaryKey(entities.records[row], entity)] = true
+	}
+	trace := traceFrom(e.ctx)
+	trace.Observe(PhaseMatch, time.Since(start))
+	trace.record(Step{
+		Entity:    entity.EntityName(),
+		Condition: strings.Join(descriptions, " or "),
+		Strategy:  strategyOf(conditions[0], entity, e.dataset.definitions),
+		Searched:  len(entities.records),
+		Estimated: min(estimated, len(entities.records)),
+		Actual:    len(keys),
+		Duration:  time.Since(start),
+	})
+	return keys, nil
+}
+
+// Error of a comparison which is an invalid condition of the search engine
+func (e *evaluator) invalid(comparison *Comparison, err *SearchError) error {
+	return e.wrapAt(comparison.Column, err, "invalid condition %v: %v", comparison, strings.TrimSpace(strings.ReplaceAll(err.Error(), "--value", "value")))
+}
+
+// Keys of entities whose value of a field matches
+func (e *evaluator) match(entity internal.Entity, comparison *Comparison, field string, matches func(value any) (bool, error)) (keySet, error) {
+	start := time.Now()
+	all := e.dataset.All(entity.EntityName())
+	keys := keySet{}
+	for _, record := range all {
+		value := e.valueOf(record, entity, field)
+		if value == nil {
+			continue
+		}
+		matched, err := matches(value)
+		if err != nil {
+			return nil, e.wrapAt(comparison.Column, ErrInvalidValue, "invalid condition %v: %v", comparison, err)
+		}
+		if matched {
+			keys[primaryKey(record, entity)] = true
+		}
+	}
+	trace := traceFrom(e.ctx)
+	trace.Observe(PhaseMatch, time.Since(start))
+	trace.record(Step{
+		Entity:    entity.EntityName(),
+		Condition: fmt.Sprint(comparison),
+		Strategy:  fmt.Sprintf("scan of entities (%v)", comparison.Operator),
+		Searched:  len(all),
+		Estimated: int(math.Ceil(float64(len(all)) * defaultSelectivity)),
+		Actual:    len(keys),
+		Duration:  time.Since(start),
+	})
+	return keys, nil
+}
+
+// Keys of all entities of a type, except the matching ones
+func (e *evaluator) except(entity internal.Entity, matching keySet) keySet {
+	keys := keySet{}
+	for _, record := range e.dataset.All(entity.EntityName()) {
+		if key := primaryKey(record, entity); !matching[key] {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// Value of a field of an entity, or of its custom field. Nil if the entity does not have the field
+func (e *evaluator) valueOf(record any, entity internal.Entity, field string) any {
+	if definition, ok := findCustomField(field, entity, e.dataset.definitions); ok {
+		store, ok := record.(internal.CustomFieldStore)
+		if !ok {
+			return nil
+		}
+		return store.FetchCustomFields()[definition.Key]
+	}
+	return fieldOf(record, entity, field)
+}
+
+// Value of a mapped field of an entity, nil if the field is not mapped
+func fieldOf(record any, entity internal.Entity, field string) any {
+	value := reflect.ValueOf(record).FieldByName(entity.Mappings()[field])
+	if !value.IsValid() {
+		return nil
+	}
+	return value.Interface()
+}
+
+// Primary key of an entity, as a string
+func primaryKey(record any, entity internal.Entity) string {
+	return fmt.Sprint(fieldOf(record, entity, entity.PrimaryKey()))
+}
+
+// Keys of a value matching related entities. A list (eg. agent_ids of a group) matches on each of its values
+func indexKeys(value any) []string {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return []string{fmt.Sprint(value)}
+	}
+	var keys []string
+	for i := 0; i < v.Len(); i++ {
+		keys = append(keys, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return keys
+}
+
+// Whether a value contains text, case-insensitively. A list contains text if any of its values does
+func containsValue(value any, text string) bool {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if containsValue(v.Index(i).Interface(), text) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(text))
+}
+
+/*
+*		Compare a value of a field to a value of a query. Numbers are compared as numbers, and the value of the query
+*		must be one, and everything else is compared as text
+ */
+func orderedMatch(value any, operator, text string) (bool, error) {
+	var compared int
+	switch v := value.(type) {
+	case int, float64:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return false, fmt.Errorf("%q is not a number", text)
+		}
+		compared = compareNumbers(reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0))).Float(), number)
+	case string:
+		compared = strings.Compare(v, text)
+	default:
+		return false, fmt.Errorf("operator '%v' is not supported for values like %v", operator, value)
+	}
+	switch operator {
+	case "<":
+		return compared < 0, nil
+	case "<=":
+		return compared <= 0, nil
+	case ">":
+		return compared > 0, nil
+	default:
+		return compared >= 0, nil
+	}
+}
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Sort rows of entities by fields in order, keeping their order in the data where they are equal
+func sortRows(rows []int, records []any, entity internal.Entity, orderBy []Order) {
+	if len(orderBy) == 0 {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, order := range orderBy {
+			compared := compareFields(fieldOf(records[rows[i]], entity, order.Field), fieldOf(records[rows[j]], entity, order.Field))
+			if compared == 0 {
+				continue
+			}
+			if order.Descending {
+				return compared > 0
+			}
+			return compared < 0
+		}
+		return false
+	})
+}
+
+// Compare values of a field of two entities. Zero timestamps come before all others
+func compareFields(a, b any) int {
+	switch x := a.(type) {
+	case int:
+		return compareNumbers(float64(x), float64(b.(int)))
+	case string:
+		return strings.Compare(x, b.(string))
+	case bool:
+		return compareNumbers(float64(boolInt(x)), float64(boolInt(b.(bool))))
+	case internal.Timestamp:
+		return x.Compare(b.(internal.Timestamp).Time)
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package zsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Test evaluation of queries of the query language against the test data
+
+func TestDataset_Query(t *testing.T) {
+	dataset := loadTestDataset(t)
+	tests := []struct {
+		query string
+		ids   []string
+	}{
+		{query: "organization", ids: []string{"919191919", "121", "102", "107", "114"}},
+		{query: "user where role = end-user and organization_id = 114", ids: []string{"707070707", "70"}},
+		{query: "user where role = admin or role = agent", ids: []string{"74", "43"}},
+		{query: "user where role in (admin, agent)", ids: []string{"74", "43"}},
+		{query: "user where role not in (admin, agent)", ids: []string{"707070707", "70", "22"}},
+		{query: "user where role != end-user", ids: []string{"74", "43"}},
+		{query: "user where not (role = end-user or alias is null)", ids: []string{"43"}},
+		{query: "user where alias is empty", ids: []string{"43"}},
+		{query: `user where name ~ "valentine"`, ids: []string{"707070707", "70"}},
+		{query: `user where name !~ "a"`, ids: []string{}},
+		{query: "user where _id > 70 order by _id", ids: []string{"74", "707070707"}},
+		{query: "user where _id <= 70 order by _id desc", ids: []string{"70", "43", "22"}},
+		{query: `ticket where created_at < "2016-05-01"`, ids: []string{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"}},
+		{query: "ticket where product = chat", ids: []string{"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"}},
+		{query: "ticket where status in (open, pending) and priority = high and organization.name ~ \"geek\"", ids: []string{"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"}},
+		{query: "ticket where submitter.role = end-user order by created_at desc", ids: []string{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"}},
+		{query: "organization where tickets.status = closed", ids: []string{"102"}},
+		{query: "user where organization.tickets.status = solved", ids: []string{"22"}},
+		{query: "organization order by name, _id desc limit 3", ids: []string{"102", "919191919", "121"}},
+	}
+	for _, tt := range tests {
+		t.Run("test query "+tt.query, func(t *testing.T) {
+			statement, err := ParseQuery(tt.query)
+			assert.Nil(t, err)
+			results, err := dataset.Query(context.Background(), statement)
+			assert.Nil(t, err)
+			ids := []string{}
+			for _, result := range results {
+				ids = append(ids, primaryKey(result, statement.Entity))
+			}
+			assert.Equal(t, tt.ids, ids)
+		})
+	}
+	t.Run("test results have their related entities", func(t *testing.T) {
+		statement, _ := ParseQuery("user where _id = 22")
+		results, err := dataset.Query(context.Background(), statement)
+		assert.Nil(t, err)
+		assert.Equal(t, "Terrasys", results[0].(User).OrganizationName)
+	})
+}
+
+func TestDataset_Query_Errors(t *testing.T) {
+	dataset := loadTestDataset(t)
+	tests := []struct {
+		query   string
+		column  int
+		message string
+	}{
+		{query: "ticket where statis = open", column: 14, message: "unknown field 'statis' of tickets"},
+		{query: "ticket where organization.nam = x", column: 14, message: "unknown field 'nam' of organizations"},
+		{query: "user where _id = abc", column: 12, message: "invalid value of field '_id': expected a number"},
+		{query: "user where active in (true, maybe)", column: 12, message: "expected true or false"},
+		{query: "user where _id > abc", column: 12, message: `"abc" is not a number`},
+		{query: "user where created_at < someday", column: 12, message: "invalid condition"},
+		{query: "ticket order by due", column: 17, message: "unknown field 'due' to order tickets by"},
+	}
+	for _, tt := range tests {
+		t.Run("test query "+tt.query, func(t *testing.T) {
+			statement, err := ParseQuery(tt.query)
+			assert.Nil(t, err)
+			_, err = dataset.Query(context.Background(), statement)
+			var parseError *ParseError
+			assert.True(t, errors.As(err, &parseError), fmt.Sprint(err))
+			assert.Equal(t, tt.column, parseError.Column)
+			assert.Contains(t, parseError.Message, tt.message)
+		})
+	}
+	t.Run("test query stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		statement, _ := ParseQuery("user where role = admin")
+		_, err := dataset.Query(ctx, statement)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
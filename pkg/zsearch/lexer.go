@@ -0,0 +1,171 @@
+// Package zsearch -
+//
+// This file is meant for splitting queries of the query language (see `parser.go`) into tokens: words (entity and
+// field names, keywords and unquoted values), quoted strings, operators and punctuation
+package zsearch
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind - Kind of a token of a query
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+// Operators comparing a field to a value, longest first so that they are matched before their prefixes
+var operators = []string{"!=", "!~", "<=", ">=", "=", "~", "<", ">"}
+
+// token - Token of a query, along with the column (from 1) it starts at
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+// Description of a token for parse errors, eg. 'where' or end of query
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return fmt.Sprintf("'%v'", t.text)
+	}
+}
+
+// is - Whether the token is a keyword (eg. where, and), which are matched case-insensitively
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// ParseError - Error in a query, pointing at the column (from 1) it was found at. Matches ErrInvalidQuery, as well as
+// the cause of errors which are not in the syntax of the query (eg. ErrUnknownField)
+type ParseError struct {
+	Query   string
+	Column  int
+	Message string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v at column %v", e.Message, e.Column)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParseError) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
+
+// Pointer - The query, with a caret under the column of the error on the next line
+func (e *ParseError) Pointer() string {
+	return e.Query + "\n" + strings.Repeat(" ", max(e.Column-1, 0)) + "^"
+}
+
+/*
+*		Split a query into tokens. Words run until whitespace, an operator or punctuation, so that unquoted values can
+*		be dates or emails (eg. 2016-04-15, a@b.com). Strings are quoted with double or single quotes, and may escape
+*		quotes and backslashes with a backslash
+*
+*	    @return ([]token, error): Tokens, ending with tokenEOF, and a ParseError if a string is not terminated
+ */
+func tokenize(query string) ([]token, error) {
+	runes := []rune(query)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", column: column})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", column: column})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", column: column})
+			i++
+		case r == '"' || r == '\'':
+			text, end, ok := scanString(runes, i)
+			if !ok {
+				return nil, &ParseError{Query: query, Column: column, Message: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, column: column})
+			i = end
+		default:
+			if operator := matchOperator(runes[i:]); operator != "" {
+				tokens = append(tokens, token{kind: tokenOperator, text: operator, column: column})
+				i += len(operator)
+				continue
+			}
+			if r == '!' {
+				return nil, &ParseError{Query: query, Column: column, Message: "unexpected '!', expected != or !~"}
+			}
+			start := i
+			for i < len(runes) && isWordRune(runes, i) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), column: column})
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, column: len(runes) + 1}), nil
+}
+
+// Whether the rune at a position is part of a word, rather than whitespace, punctuation or an operator
+func isWordRune(runes []rune, i int) bool {
+	r := runes[i]
+	if unicode.IsSpace(r) || strings.ContainsRune(`(),"'!`, r) {
+		return false
+	}
+	return matchOperator(runes[i:]) == ""
+}
+
+// Operator at the start of runes, or empty if there is none
+func matchOperator(runes []rune) string {
+	for _, operator := range operators {
+		if strings.HasPrefix(string(runes[:min(len(runes), len(operator))]), operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+/*
+*		Scan a quoted string starting at a position
+*
+*	    @return (string, int, bool): Unquoted text, position after the closing quote, and whether the string was closed
+ */
+func scanString(runes []rune, start int) (string, int, bool) {
+	quote := runes[start]
+	var text strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == quote || runes[i+1] == '\\') {
+				i++
+			}
+			text.WriteRune(runes[i])
+		case quote:
+			return text.String(), i + 1, true
+		default:
+			text.WriteRune(runes[i])
+		}
+	}
+	return "", len(runes), false
+}
@@ -0,0 +1,113 @@
+// Package zsearch -
+//
+// This file is meant for matching fields of entities as they are in the raw data, by conditions built from typed
+// values (eg. _id equal to 121, tags containing "Ohio"). Values are never interpolated into a query string, so that
+// any value (eg. one with quotes or brackets) is compared as it is, rather than breaking the query
+package zsearch
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// matcher - Condition on the fields of an entity, as parsed from its raw JSON
+type matcher interface {
+	match(fields map[string]any) bool
+}
+
+// equals - Field equal to a value (int64, string or bool)
+type equals struct {
+	field string
+	value any
+}
+
+func (e equals) match(fields map[string]any) bool {
+	value, found := fields[e.field]
+	return found && equalValues(value, e.value)
+}
+
+// contains - List field containing a value (int64 or string)
+type contains struct {
+	field string
+	value any
+}
+
+func (c contains) match(fields map[string]any) bool {
+	values, _ := fields[c.field].([]any)
+	for _, value := range values {
+		if equalValues(value, c.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// presence - Field matching a predicate on whether it is missing, null or empty, see `predicate.go`
+type presence struct {
+	field     string
+	predicate string
+}
+
+func (p presence) match(fields map[string]any) bool {
+	value, found := fields[p.field]
+	return matchPredicate(p.predicate, value, found)
+}
+
+/*
+*	Whether a JSON value is equal to a searched value. Numbers are compared by value, as JSON integers may be parsed
+*	as either int64 or float64
+ */
+func equalValues(value, searched any) bool {
+	switch s := searched.(type) {
+	case int64:
+		switch v := value.(type) {
+		case int64:
+			return v == s
+		case float64:
+			return v == float64(s)
+		}
+		return false
+	default:
+		return value == searched
+	}
+}
+
+/*
+*	Build the matcher of a search for a value of a field, by the type of the field. The value is parsed to the type of
+*	the field first, so that a value of the wrong type is reported rather than never matching
+*
+*	    @return (matcher, error): Matcher of the search, and an error if the value is not of the type of the field
+ */
+func newMatcher(fieldType reflect.Type, value, name string) (matcher, error) {
+	if fieldType == nil {
+		return nil, errors.New("invalid data type not supported")
+	}
+	switch fieldType.Kind() {
+	case reflect.Int:
+		parsedInt, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, &ValueError{Name: name, Expected: "int type of"}
+		}
+		return equals{field: name, value: parsedInt}, nil
+	case reflect.String:
+		return equals{field: name, value: value}, nil
+	case reflect.Bool:
+		parsedBool, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, &ValueError{Name: name, Expected: "bool type of"}
+		}
+		return equals{field: name, value: parsedBool}, nil
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.Int {
+			parsedInt, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, &ValueError{Name: name, Expected: "int type of"}
+			}
+			return contains{field: name, value: parsedInt}, nil
+		}
+		return contains{field: name, value: value}, nil
+	default:
+		return nil, errors.New("invalid data type not supported")
+	}
+}
@@ -0,0 +1,85 @@
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/users"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"unicode/utf8"
+)
+
+// Fuzz searching by values of any content, which are matched as they are rather than being parsed as part of a query
+
+func FuzzFind_String(f *testing.F) {
+	for _, seed := range []string{"", "Francisca Rasmussen", `"`, `'`, `\`, `")]`, `' in @.tags)]`, "$..[?(@._id==1)]", "a\nb", "日本語"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		if !utf8.ValidString(value) {
+			t.Skip("JSON strings are valid UTF-8")
+		}
+		records := []map[string]any{
+			{"_id": 1, "name": value, "tags": []string{"Ohio", value}},
+			{"_id": 2, "name": value + "!", "tags": []string{"!" + value}},
+		}
+		dataset := newUserDataset(t, records)
+
+		for _, name := range []string{"name", "tags"} {
+			matches, err := dataset.Find(context.Background(), users.Model.Name, Condition{Name: name, Value: value})
+			if !assert.Nil(t, err, "searching %v for %q", name, value) {
+				return
+			}
+			if assert.Len(t, matches, 1, "searching %v for %q", name, value) {
+				assert.Equal(t, 1, matches[0].(User).Id)
+			}
+		}
+	})
+}
+
+func FuzzFind_Int(f *testing.F) {
+	f.Add(int64(121), "121")
+	f.Add(int64(-1), "-1")
+	f.Add(int64(0), "0 || true")
+	f.Add(int64(5), "5)]")
+	f.Fuzz(func(t *testing.T, id int64, value string) {
+		other := id ^ 1 // Another _id, so that a search matching every record is found out
+		dataset := newUserDataset(t, []map[string]any{{"_id": id}, {"_id": other}})
+
+		matches, err := dataset.Find(context.Background(), users.Model.Name, Condition{Name: "_id", Value: fmt.Sprint(id)})
+		if assert.Nil(t, err) && assert.Len(t, matches, 1, "searching _id %v for its own value", id) {
+			assert.Equal(t, int(id), matches[0].(User).Id)
+		}
+
+		matches, err = dataset.Find(context.Background(), users.Model.Name, Condition{Name: "_id", Value: value})
+		if err != nil {
+			var valueError *ValueError
+			assert.True(t, errors.Is(err, ErrInvalidValue), "searching _id for %q", value)
+			if assert.True(t, errors.As(err, &valueError)) {
+				assert.Equal(t, "_id", valueError.Name)
+			}
+			return
+		}
+		parsed, parseErr := strconv.ParseInt(value, 10, 64)
+		if parseErr != nil || (parsed != id && parsed != other) {
+			assert.Empty(t, matches, "searching _id for %q matches no record", value)
+			return
+		}
+		if assert.Len(t, matches, 1, "searching _id for %q matches the record of that _id", value) {
+			assert.Equal(t, int(parsed), matches[0].(User).Id)
+		}
+	})
+}
+
+// Dataset of users, loaded from records as if they were read from a data file
+func newUserDataset(t *testing.T, records []map[string]any) *Dataset {
+	raw, err := json.Marshal(records)
+	assert.Nil(t, err)
+	data, err := users.Model.Load(raw)
+	assert.Nil(t, err)
+	return newTestDataset(t, map[string]internal.DataProcessor{users.Model.Name: data}, nil)
+}
@@ -0,0 +1,367 @@
+// Package zsearch -
+//
+// This file is meant for parsing queries of the query language, which searches entities of a type by any number of
+// conditions, eg.
+//
+//	ticket where status in (open, pending) and priority = high and organization.name ~ "Multron"
+//	    order by created_at desc limit 20
+//
+// The grammar of queries, where keywords are matched case-insensitively:
+//
+//	query      = entity [ "where" expression ] [ "order" "by" order { "," order } ] [ "limit" number ]
+//	expression = term { "or" term }
+//	term       = factor { "and" factor }
+//	factor     = "not" factor | "(" expression ")" | condition
+//	condition  = field operator value
+//	           | field [ "not" ] "in" "(" value { "," value } ")"
+//	           | field "is" [ "not" ] ( "missing" | "null" | "empty" )
+//	order      = field [ "asc" | "desc" ]
+//	operator   = "=" | "!=" | "~" | "!~" | "<" | "<=" | ">" | ">="
+//
+// Fields of related entities are prefixed by the name of the relationship (eg. organization.name, submitter.role),
+// see Edges. Values are words, or strings quoted with double or single quotes (eg. "30d ago")
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keywords of the query language, which are not field names
+var keywords = map[string]bool{
+	"where": true, "and": true, "or": true, "not": true, "in": true, "is": true,
+	"order": true, "by": true, "asc": true, "desc": true, "limit": true,
+}
+
+// Statement - Parsed query, searching entities of a type
+type Statement struct {
+	Query    string
+	Entity   internal.Entity
+	Where    Expression // Conditions entities must match, or nil for all entities
+	OrderBy  []Order
+	Limit    int            // Maximum number of results, or all of them if it is 0
+	Location *time.Location // Timezone dates are searched in, set by callers (eg. --tz of the query command)
+}
+
+// Order - Field results are sorted by, in ascending order unless Descending
+type Order struct {
+	Field      string
+	Descending bool
+	Column     int
+}
+
+// Expression - Node of the conditions of a query: Binary, Not or Comparison
+type Expression interface {
+	String() string
+}
+
+// Binary - Both (and) or either (or) of two expressions
+type Binary struct {
+	Operator    string // and, or
+	Left, Right Expression
+}
+
+func (b *Binary) String() string {
+	return fmt.Sprintf("(%v %v %v)", b.Left, b.Operator, b.Right)
+}
+
+// Not - Negation of an expression
+type Not struct {
+	Expression Expression
+}
+
+func (n *Not) String() string {
+	return fmt.Sprintf("not %v", n.Expression)
+}
+
+// Comparison - Field compared to one or more values by an operator, or matched by a predicate (eg. is null)
+type Comparison struct {
+	Field     string
+	Operator  string // One of operators, or in, or is
+	Values    []string
+	Predicate string // One of Predicates, when Operator is is
+	Column    int
+}
+
+func (c *Comparison) String() string {
+	switch c.Operator {
+	case "in":
+		return fmt.Sprintf("%v in (%v)", c.Field, strings.Join(c.Values, ", "))
+	case "is":
+		return fmt.Sprintf("%v is %v", c.Field, c.Predicate)
+	default:
+		return fmt.Sprintf("%v %v %q", c.Field, c.Operator, c.Values[0])
+	}
+}
+
+// parser - Recursive descent parser of the tokens of a query
+type parser struct {
+	query    string
+	tokens   []token
+	position int
+}
+
+/*
+*		Parse a query of the query language
+*
+*	    @return (*Statement, error): Parsed query, and a ParseError pointing at the column of any error in it
+ */
+func ParseQuery(query string) (*Statement, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{query: query, tokens: tokens}
+	return p.parseStatement()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.kind != tokenEOF {
+		p.position++
+	}
+	return t
+}
+
+// Error at a token
+func (p *parser) errorAt(t token, format string, args ...any) error {
+	return &ParseError{Query: p.query, Column: t.column, Message: fmt.Sprintf(format, args...)}
+}
+
+// Consume a keyword, or fail if the next token is anything else
+func (p *parser) expectKeyword(keyword string) error {
+	if t := p.next(); !t.is(keyword) {
+		return p.errorAt(t, "expected '%v', found %v", keyword, t)
+	}
+	return nil
+}
+
+// Consume a token of a kind, or fail if the next token is of any other kind
+func (p *parser) expect(kind tokenKind, description string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.errorAt(t, "expected %v, found %v", description, t)
+	}
+	return t, nil
+}
+
+func (p *parser) parseStatement() (*Statement, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return nil, p.errorAt(t, "expected an entity (%v), found %v", strings.Join(entityNames(), ", "), t)
+	}
+	entity, ok := models.Registry.Get(strings.ToLower(t.text))
+	if !ok {
+		entity, ok = models.Registry.Get(strings.TrimSuffix(strings.ToLower(t.text), "s")) // eg. tickets
+	}
+	if !ok {
+		return nil, p.errorAt(t, "unknown entity '%v', expected one of %v", t.text, strings.Join(entityNames(), ", "))
+	}
+	statement := &Statement{Query: p.query, Entity: entity}
+	if p.peek().is("where") {
+		p.next()
+		where, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		statement.Where = where
+	}
+	if p.peek().is("order") {
+		p.next()
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		for {
+			field, err := p.parseField()
+			if err != nil {
+				return nil, err
+			}
+			order := Order{Field: field.text, Column: field.column}
+			if p.peek().is("asc") || p.peek().is("desc") {
+				order.Descending = p.next().is("desc")
+			}
+			statement.OrderBy = append(statement.OrderBy, order)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.peek().is("limit") {
+		p.next()
+		t := p.next()
+		limit, err := strconv.Atoi(t.text)
+		if t.kind != tokenWord || err != nil || limit < 0 {
+			return nil, p.errorAt(t, "expected a number for limit, found %v", t)
+		}
+		statement.Limit = limit
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		if statement.Where == nil && statement.OrderBy == nil {
+			return nil, p.errorAt(t, "expected 'where', 'order by', 'limit' or end of query, found %v", t)
+		}
+		return nil, p.errorAt(t, "unexpected %v", t)
+	}
+	return statement, nil
+}
+
+// expression = term { "or" term }
+func (p *parser) parseExpression() (Expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("or") {
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Operator: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// term = factor { "and" factor }
+func (p *parser) parseTerm() (Expression, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("and") {
+		p.next()
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Operator: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// factor = "not" factor | "(" expression ")" | condition
+func (p *parser) parseFactor() (Expression, error) {
+	if p.peek().is("not") {
+		p.next()
+		expression, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expression: expression}, nil
+	}
+	if p.peek().kind == tokenLeftParen {
+		p.next()
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, "')'"); err != nil {
+			return nil, err
+		}
+		return expression, nil
+	}
+	return p.parseCondition()
+}
+
+// condition = field operator value | field [ "not" ] "in" "(" value { "," value } ")" | field "is" [ "not" ] predicate
+func (p *parser) parseCondition() (Expression, error) {
+	field, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+	comparison := &Comparison{Field: field.text, Column: field.column}
+	t := p.next()
+	switch {
+	case t.kind == tokenOperator:
+		comparison.Operator = t.text
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		comparison.Values = []string{value}
+		return comparison, nil
+	case t.is("in"), t.is("not") && p.peek().is("in"):
+		if t.is("not") {
+			p.next()
+		}
+		comparison.Operator = "in"
+		if _, err := p.expect(tokenLeftParen, "'(' before values"); err != nil {
+			return nil, err
+		}
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			comparison.Values = append(comparison.Values, value)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokenRightParen, "',' or ')' after values"); err != nil {
+			return nil, err
+		}
+		if t.is("not") {
+			return &Not{Expression: comparison}, nil
+		}
+		return comparison, nil
+	case t.is("is"):
+		comparison.Operator = "is"
+		negated := p.peek().is("not")
+		if negated {
+			p.next()
+		}
+		predicate := p.next()
+		switch strings.ToLower(predicate.text) {
+		case PredicateMissing, PredicateNull, PredicateEmpty:
+			if predicate.kind != tokenWord {
+				return nil, p.errorAt(predicate, "expected missing, null or empty, found %v", predicate)
+			}
+			comparison.Predicate = strings.ToLower(predicate.text)
+		default:
+			return nil, p.errorAt(predicate, "expected missing, null or empty, found %v", predicate)
+		}
+		if negated {
+			comparison.Predicate = "not-" + comparison.Predicate
+		}
+		return comparison, nil
+	default:
+		return nil, p.errorAt(t, "expected an operator (%v), 'in' or 'is' after field '%v', found %v", strings.Join(operators, " "), field.text, t)
+	}
+}
+
+// Field name, which is a word other than a keyword
+func (p *parser) parseField() (token, error) {
+	t := p.next()
+	if t.kind != tokenWord || keywords[strings.ToLower(t.text)] {
+		return t, p.errorAt(t, "expected a field, found %v", t)
+	}
+	return t, nil
+}
+
+// Value, which is a word or a quoted string
+func (p *parser) parseValue() (string, error) {
+	t := p.next()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return "", p.errorAt(t, "expected a value, found %v", t)
+	}
+	return t.text, nil
+}
+
+// Names of all registered entities
+func entityNames() []string {
+	var names []string
+	for _, entity := range models.Registry.All() {
+		names = append(names, entity.EntityName())
+	}
+	return names
+}
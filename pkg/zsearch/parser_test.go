@@ -0,0 +1,105 @@
+package zsearch
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Test tokenizing and parsing of queries of the query language, and errors pointing at the column they were found at
+
+func TestTokenize(t *testing.T) {
+	t.Run("test words, strings, operators and punctuation", func(t *testing.T) {
+		tokens, err := tokenize(`ticket where a.b!="x \"y\"" and c in (1,'2') or d>=2016-04-15`)
+		assert.Nil(t, err)
+		var texts []string
+		for _, token := range tokens {
+			texts = append(texts, token.text)
+		}
+		assert.Equal(t, []string{"ticket", "where", "a.b", "!=", `x "y"`, "and", "c", "in", "(", "1", ",", "2", ")", "or", "d", ">=", "2016-04-15", ""}, texts)
+		assert.Equal(t, 17, tokens[3].column)
+		assert.Equal(t, tokenEOF, tokens[len(tokens)-1].kind)
+	})
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		entity  string
+		where   string
+		orderBy []Order
+		limit   int
+	}{
+		{query: "user", entity: "user"},
+		{query: "Tickets LIMIT 5", entity: "ticket", limit: 5},
+		{
+			query:   `ticket where status in (open, pending) and priority = high and organization.name ~ "Multron" order by created_at desc limit 20`,
+			entity:  "ticket",
+			where:   `((status in (open, pending) and priority = "high") and organization.name ~ "Multron")`,
+			orderBy: []Order{{Field: "created_at", Descending: true, Column: 103}},
+			limit:   20,
+		},
+		{
+			query:  "user where role = admin or not (alias is not null and _id not in (1, 2))",
+			entity: "user",
+			where:  `(role = "admin" or not (alias is not-null and not _id in (1, 2)))`,
+		},
+		{
+			query:   "organization order by name, _id asc",
+			entity:  "organization",
+			orderBy: []Order{{Field: "name", Column: 23}, {Field: "_id", Column: 29}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("test query "+tt.query, func(t *testing.T) {
+			statement, err := ParseQuery(tt.query)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.entity, statement.Entity.EntityName())
+			if tt.where == "" {
+				assert.Nil(t, statement.Where)
+			} else {
+				assert.Equal(t, tt.where, statement.Where.String())
+			}
+			assert.Equal(t, tt.orderBy, statement.OrderBy)
+			assert.Equal(t, tt.limit, statement.Limit)
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query   string
+		column  int
+		message string
+	}{
+		{query: "", column: 1, message: "expected an entity"},
+		{query: "widget where a = b", column: 1, message: "unknown entity 'widget'"},
+		{query: "ticket status = open", column: 8, message: "expected 'where', 'order by', 'limit' or end of query, found 'status'"},
+		{query: "ticket where status open", column: 21, message: "expected an operator"},
+		{query: "ticket where status in (open pending)", column: 30, message: "expected ',' or ')' after values, found 'pending'"},
+		{query: "ticket where status = ", column: 23, message: "expected a value, found end of query"},
+		{query: "ticket where (status = open", column: 28, message: "expected ')'"},
+		{query: `ticket where subject = "A Problem`, column: 24, message: "unterminated string"},
+		{query: "ticket where alias is nothing", column: 23, message: "expected missing, null or empty"},
+		{query: "ticket order created_at", column: 14, message: "expected 'by'"},
+		{query: "ticket limit ten", column: 14, message: "expected a number for limit"},
+		{query: "ticket where and = 1", column: 14, message: "expected a field, found 'and'"},
+		{query: "ticket where a ! b", column: 16, message: "unexpected '!'"},
+	}
+	for _, tt := range tests {
+		t.Run("test query "+tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			var parseError *ParseError
+			assert.True(t, errors.As(err, &parseError))
+			assert.Equal(t, tt.column, parseError.Column)
+			assert.Contains(t, parseError.Message, tt.message)
+		})
+	}
+	t.Run("test error points at its column", func(t *testing.T) {
+		_, err := ParseQuery("ticket where status in (open pending)")
+		var parseError *ParseError
+		assert.True(t, errors.As(err, &parseError))
+		assert.Equal(t, "ticket where status in (open pending)\n                             ^", parseError.Pointer())
+		assert.Equal(t, "expected ',' or ')' after values, found 'pending' at column 30", parseError.Error())
+	})
+}
@@ -0,0 +1,62 @@
+// Package zsearch -
+//
+// This file is meant for searching entities by whether a field is missing, null or empty (or not), which works for
+// fields of any type, unlike searching for a value
+package zsearch
+
+import (
+	"strings"
+)
+
+// Predicates on presence of a field, searched for instead of a value. Each predicate has a negation prefixed by 'not-'
+const (
+	PredicateMissing    = "missing"
+	PredicateNull       = "null"
+	PredicateEmpty      = "empty"
+	PredicateNotMissing = "not-missing"
+	PredicateNotNull    = "not-null"
+	PredicateNotEmpty   = "not-empty"
+)
+
+// Predicates - All predicates, in order of their flags
+var Predicates = []string{PredicateMissing, PredicateNull, PredicateEmpty, PredicateNotMissing, PredicateNotNull, PredicateNotEmpty}
+
+/*
+*	Match a predicate against a value of a field, and whether the field was found at all.
+*	Only strings, lists and objects can be empty, a missing or null field is neither empty nor not empty
+ */
+func matchPredicate(predicate string, value any, found bool) bool {
+	negated := strings.HasPrefix(predicate, "not-")
+	var matched bool
+	switch strings.TrimPrefix(predicate, "not-") {
+	case PredicateMissing:
+		matched = !found
+	case PredicateNull:
+		if !found {
+			return false
+		}
+		matched = value == nil
+	case PredicateEmpty:
+		if !found || value == nil {
+			return false
+		}
+		matched = isEmptyValue(value)
+	default:
+		return false
+	}
+	return matched != negated
+}
+
+// isEmptyValue - Whether a JSON value is an empty string, list or object
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
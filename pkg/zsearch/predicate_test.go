@@ -0,0 +1,106 @@
+package zsearch
+
+import (
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+	"context"
+)
+
+// Test searching for missing, null and empty fields
+
+func (suite *TestSuite) TestFind_Predicates() {
+	testsSuccess := []struct {
+		title     string
+		entity    string
+		condition Condition
+		ids       []any
+	}{
+		{
+			title:     "string field which is null",
+			entity:    users.Model.Name,
+			condition: Condition{Name: "alias", Predicate: PredicateNull},
+			ids:       []any{74},
+		},
+		{
+			title:     "string field which is empty",
+			entity:    users.Model.Name,
+			condition: Condition{Name: "alias", Predicate: PredicateEmpty},
+			ids:       []any{43},
+		},
+		{
+			title:     "string field which is not empty, leaving out null fields",
+			entity:    users.Model.Name,
+			condition: Condition{Name: "alias", Predicate: PredicateNotEmpty},
+			ids:       []any{707070707, 70, 22},
+		},
+		{
+			title:     "string field which is missing",
+			entity:    users.Model.Name,
+			condition: Condition{Name: "email", Predicate: PredicateMissing},
+			ids:       []any{22},
+		},
+		{
+			title:     "int field which is missing",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "assignee_id", Predicate: PredicateMissing},
+			ids:       []any{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "int field which is not missing",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "assignee_id", Predicate: PredicateNotMissing},
+			ids:       []any{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", "7c67b6ed-6776-4065-bd4a-f2d9d12c33b7"},
+		},
+		{
+			title:     "int field is never empty",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "assignee_id", Predicate: PredicateEmpty},
+			ids:       nil,
+		},
+		{
+			title:     "list field which is not null",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "tags", Predicate: PredicateNotNull},
+			ids:       []any{"test_id", "20615fe1-765b-4ff5-b4f6-ea42dcc8cac3", "3ff0599a-fe0f-4f8f-ac31-e2636843bcea", "7c67b6ed-6776-4065-bd4a-f2d9d12c33b7"},
+		},
+		{
+			title:     "custom field which is null",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "custom_field.360009012", Predicate: PredicateNull},
+			ids:       []any{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "custom field which is missing",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "custom_field.360009012", Predicate: PredicateMissing},
+			ids:       []any{"test_id", "7c67b6ed-6776-4065-bd4a-f2d9d12c33b7"},
+		},
+	}
+	for _, tt := range testsSuccess {
+		suite.Run(tt.title, func() {
+			results, err := suite.dataset.Find(context.Background(), tt.entity, tt.condition)
+			suite.Nil(err)
+			suite.Equal(tt.ids, idsOf(results))
+		})
+	}
+	suite.Run("predicate on invalid field", func() {
+		results, err := suite.dataset.Find(context.Background(), users.Model.Name, Condition{Name: "nickname", Predicate: PredicateMissing})
+		suite.Nil(results)
+		suite.NotNil(err)
+	})
+}
+
+func (suite *TestSuite) TestMatchPredicate() {
+	suite.Run("predicates and their negations", func() {
+		suite.True(matchPredicate(PredicateMissing, nil, false))
+		suite.False(matchPredicate(PredicateNotMissing, nil, false))
+		suite.False(matchPredicate(PredicateNull, nil, false), "missing field is not null")
+		suite.False(matchPredicate(PredicateNotNull, nil, false), "missing field is not 'not null' either")
+		suite.True(matchPredicate(PredicateNull, nil, true))
+		suite.True(matchPredicate(PredicateEmpty, []any{}, true))
+		suite.True(matchPredicate(PredicateEmpty, map[string]any{}, true))
+		suite.False(matchPredicate(PredicateEmpty, false, true))
+		suite.True(matchPredicate(PredicateNotEmpty, int64(0), true))
+		suite.False(matchPredicate("unknown", nil, true))
+	})
+}
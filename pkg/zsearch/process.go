@@ -0,0 +1,209 @@
+// Package zsearch -
+//
+// This file is meant for processing of all search queries, and is where most of the heavy-lifting of storage
+// and data processing is done
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Results are enriched by a pool of workers once there are more than this many, each enriching this many at a time
+const enrichmentChunk = 512
+
+/*
+*	Add related entities to each entity in the results, as declared by the relationships of the entity (eg.
+*	organization name and submitted tickets of users, submitter and assignee names of tickets)
+*
+*	Related entities are indexed once by the field they are matched on, rather than searched through for every result.
+*	Large results are enriched in chunks by a pool of workers bounded by the number of CPUs, each setting fields of its
+*	own entities only
+*
+*	    @return (error): Error of the context, if it is done before all results are enriched
+ */
+func addRelatedEntities(ctx context.Context, records reflect.Value, entity internal.Entity, related map[string]internal.DataProcessor) error {
+	if records.Kind() != reflect.Slice {
+		return nil
+	}
+	for _, relation := range entity.Relations() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		relatedEntity, ok := models.Registry.Get(relation.Entity)
+		relatedData := related[relation.Entity]
+		if !ok || relatedData == nil {
+			log.Debugf("No %v data to add to %v results", relation.Entity, entity.EntityName())
+			continue
+		}
+		index := indexRelatedEntities(relatedData.FetchProcessed(), relatedEntity.Mappings(), relation)
+		if records.Len() <= enrichmentChunk {
+			addRelatedEntity(records, 0, records.Len(), entity, relation, index)
+			continue
+		}
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(runtime.GOMAXPROCS(0))
+		for from := 0; from < records.Len(); from += enrichmentChunk {
+			from, to := from, min(from+enrichmentChunk, records.Len())
+			group.Go(func() error {
+				if err := groupCtx.Err(); err != nil {
+					return err
+				}
+				addRelatedEntity(records, from, to, entity, relation, index)
+				return nil
+			})
+		}
+		if err := group.Wait(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add entities of a relationship to the results from one index up to another, from the index of related entities
+func addRelatedEntity(records reflect.Value, from, to int, entity internal.Entity, relation internal.Relationship, index map[any][]reflect.Value) {
+	for i := from; i < to; i++ {
+		record := records.Index(i)
+		key := record.FieldByName(entity.Mappings()[relation.LocalKey])
+		field := record.FieldByName(entity.Mappings()[relation.Field])
+		if !key.IsValid() || !field.CanSet() {
+			continue
+		}
+		var matches []reflect.Value
+		for _, k := range fieldValues(key) {
+			matches = append(matches, index[k]...)
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		if field.Kind() == reflect.Slice {
+			values := reflect.MakeSlice(field.Type(), 0, len(matches))
+			for _, match := range matches {
+				values = reflect.Append(values, match)
+			}
+			field.Set(values)
+		} else {
+			field.Set(matches[0])
+		}
+	}
+}
+
+/*
+*	Index the displayed field of related entities by the value(s) of their foreign key
+ */
+func indexRelatedEntities(entities []interface{}, mappings map[string]string, relation internal.Relationship) map[any][]reflect.Value {
+	index := map[any][]reflect.Value{}
+	for _, entity := range entities {
+		value := reflect.ValueOf(entity)
+		key := value.FieldByName(mappings[relation.ForeignKey])
+		display := value.FieldByName(mappings[relation.Display])
+		if !key.IsValid() || !display.IsValid() {
+			continue
+		}
+		for _, k := range fieldValues(key) {
+			index[k] = append(index[k], display)
+		}
+	}
+	return index
+}
+
+/*
+*	Values of a field used to match related entities. A list field (eg. agent_ids of a group) matches on each of its
+*	values
+ */
+func fieldValues(field reflect.Value) []any {
+	if field.Kind() != reflect.Slice {
+		return []any{field.Interface()}
+	}
+	var values []any
+	for i := 0; i < field.Len(); i++ {
+		values = append(values, field.Index(i).Interface())
+	}
+	return values
+}
+
+/*
+*	Find the custom field being searched for, by its definition or else by the custom field prefix of the entity.
+*	Fields without definitions have no type, which is then inferred from their values
+ */
+func findCustomField(name string, entity internal.Entity, definitions internal.FieldDefinitions) (internal.FieldDefinition, bool) {
+	if definition, ok := definitions.Find(entity.EntityName(), name); ok {
+		return definition, true
+	}
+	prefix := entity.CustomFieldPrefix()
+	if prefix != "" && strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+		return internal.FieldDefinition{Entity: entity.EntityName(), Key: name}, true
+	}
+	return internal.FieldDefinition{}, false
+}
+
+/*
+*	Compare the searched value to the value of a custom field, depending on its defined (or else inferred) type
+ */
+func matchCustomFieldValue(definition internal.FieldDefinition, fieldValue any, value string) (bool, error) {
+	fieldType := definition.Type
+	if fieldType == "" {
+		fieldType = inferFieldType(fieldValue)
+	}
+	switch fieldType {
+	case internal.FieldTypeInt:
+		parsedInt, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, &ValueError{Name: definition.Key, Expected: "int type of"}
+		}
+		number, ok := fieldValue.(float64)
+		return ok && number == float64(parsedInt), nil
+	case internal.FieldTypeBool:
+		parsedBool, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, &ValueError{Name: definition.Key, Expected: "bool type of"}
+		}
+		boolean, ok := fieldValue.(bool)
+		return ok && boolean == parsedBool, nil
+	case internal.FieldTypeList:
+		values, _ := fieldValue.([]any)
+		for _, item := range values {
+			if fmt.Sprint(item) == value {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		if fieldValue == nil {
+			return value == "", nil
+		}
+		return fmt.Sprint(fieldValue) == value, nil
+	}
+}
+
+/*
+*	Infer type of a custom field which has no definition, from its JSON value
+ */
+func inferFieldType(fieldValue any) string {
+	switch fieldValue.(type) {
+	case float64:
+		return internal.FieldTypeInt
+	case bool:
+		return internal.FieldTypeBool
+	case []any:
+		return internal.FieldTypeList
+	default:
+		return internal.FieldTypeString
+	}
+}
+
+// isStringField - Whether a field is a string, or a list of strings
+func isStringField(fieldType reflect.Type) bool {
+	if fieldType != nil && fieldType.Kind() == reflect.Slice {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType != nil && fieldType.Kind() == reflect.String
+}
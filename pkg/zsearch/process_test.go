@@ -0,0 +1,568 @@
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/comments"
+	"ZendeskChallenge/models/groups"
+	"ZendeskChallenge/models/organizations"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	_ "github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// Test processing of user invoked search queries, and evaluating the output
+
+type TestSuite struct {
+	suite.Suite
+	orgData    organizations.OrgData
+	userData   users.UserData
+	ticketData tickets.TicketData
+	dataset    *Dataset // Dataset of the data above, searched by tests of searching
+}
+
+func TestProcessSuite(t *testing.T) {
+	suite.Run(t, new(TestSuite))
+}
+
+func GetSampleOrgData() organizations.OrgData {
+	data, _ := os.ReadFile(filepath.Join(testDataDir, "organizations.json"))
+	var allOrgs organizations.Organization
+	// Fill the instance from the JSON file content
+	_ = json.Unmarshal(data, &allOrgs)
+
+	return organizations.OrgData{
+		Raw:       data,
+		Processed: allOrgs,
+	}
+}
+
+func GetSampleTicketData() tickets.TicketData {
+	data, _ := os.ReadFile(filepath.Join(testDataDir, "tickets.json"))
+	var allTickets tickets.Ticket
+	// Fill the instance from the JSON file content
+	_ = json.Unmarshal(data, &allTickets)
+
+	return tickets.TicketData{
+		Raw:       data,
+		Processed: allTickets,
+	}
+}
+
+func GetSampleUserData() users.UserData {
+	data, _ := os.ReadFile(filepath.Join(testDataDir, "users.json"))
+	var allUsers users.User
+	// Fill the instance from the JSON file content
+	_ = json.Unmarshal(data, &allUsers)
+
+	return users.UserData{
+		Raw:       data,
+		Processed: allUsers,
+	}
+}
+
+func (suite *TestSuite) SetupSuite() {
+	suite.orgData = GetSampleOrgData()
+	suite.ticketData = GetSampleTicketData()
+	suite.userData = GetSampleUserData()
+	suite.dataset = newTestDataset(suite.T(), map[string]internal.DataProcessor{
+		organizations.Model.Name: &suite.orgData,
+		users.Model.Name:         &suite.userData,
+		tickets.Model.Name:       &suite.ticketData,
+	}, nil)
+}
+
+// this function executes after all tests executed
+func (suite *TestSuite) TearDownSuite() {
+	fmt.Println(">>> From TearDownSuite")
+}
+
+func (suite *TestSuite) TestAddRelatedUserEntities() {
+	testsSuccess := []struct {
+		title    string
+		users    users.User
+		related  map[string]internal.DataProcessor
+		expected map[int]map[string]interface{}
+	}{
+		{
+			title: "Add related ticket entities - entities non-existent",
+			// !! IMPORTANT this test case needs to run before to avoid using the newly set values from next test case
+			users:   suite.userData.Processed,
+			related: nil,
+			expected: map[int]map[string]interface{}{
+				707070707: {
+					"OrganizationName": "",
+					"Tickets":          []string(nil),
+				},
+				70: {
+					"OrganizationName": "",
+					"Tickets":          []string(nil),
+				},
+				22: {
+					"OrganizationName": "",
+					"Tickets":          []string(nil),
+				},
+				74: {
+					"OrganizationName": "",
+					"Tickets":          []string(nil),
+				},
+				43: {
+					"OrganizationName": "",
+					"Tickets":          []string(nil),
+				},
+			},
+		},
+		{
+			title:   "Add related user entities - success",
+			users:   suite.userData.Processed,
+			related: map[string]internal.DataProcessor{"ticket": &suite.ticketData, "organization": &suite.orgData},
+			expected: map[int]map[string]interface{}{
+				707070707: {
+					"OrganizationName": "Isotronic",
+					"Tickets":          []string(nil),
+				},
+				70: {
+					"OrganizationName": "Isotronic",
+					"Tickets":          []string{"test description 2"},
+				},
+				22: {
+					"OrganizationName": "Terrasys",
+					"Tickets":          []string{"test description 1"},
+				},
+				74: {
+					"OrganizationName": "Hotcâkes",
+					"Tickets":          []string(nil),
+				},
+				43: {
+					"OrganizationName": "",
+					"Tickets":          []string(nil),
+				},
+			},
+		},
+	}
+
+	for _, tt := range testsSuccess {
+		suite.Run(tt.title, func() {
+			for i := range tt.users { // Related entities all null before search
+				suite.Empty(tt.users[i].Tickets)
+				suite.Empty(tt.users[i].OrganizationName)
+			}
+			suite.Nil(addRelatedEntities(context.Background(), reflect.ValueOf(tt.users), users.Model, tt.related)) // Add entities to all users
+			for _, user := range tt.users {
+				value, _ := tt.expected[user.Id]["Tickets"]
+				suite.Equal(user.Tickets, value)
+				value, _ = tt.expected[user.Id]["OrganizationName"]
+				suite.Equal(user.OrganizationName, value)
+			}
+		})
+	}
+}
+
+func (suite *TestSuite) TestAddRelatedTicketEntities() {
+	testsSuccess := []struct {
+		title    string
+		tickets  tickets.Ticket
+		related  map[string]internal.DataProcessor
+		expected map[string]map[string]string
+	}{
+		{
+			title: "Add related ticket entities - nothing to change",
+			// !! IMPORTANT this test case needs to run before to avoid using the newly set values from next test case
+			tickets: suite.ticketData.Processed,
+			related: nil,
+			expected: map[string]map[string]string{
+				"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3": {
+					"SubmitterName":    "",
+					"AssigneeName":     "",
+					"OrganizationName": "",
+				},
+				"3ff0599a-fe0f-4f8f-ac31-e2636843bcea": {
+					"SubmitterName":    "",
+					"AssigneeName":     "",
+					"OrganizationName": "",
+				},
+				"7c67b6ed-6776-4065-bd4a-f2d9d12c33b7": {
+					"AssigneeName":     "",
+					"OrganizationName": "",
+					"SubmitterName":    "",
+				},
+			},
+		},
+		{
+			title:   "Add related ticket entities - success",
+			tickets: suite.ticketData.Processed,
+			related: map[string]internal.DataProcessor{"user": &suite.userData, "organization": &suite.orgData},
+			expected: map[string]map[string]string{
+				"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3": {
+					"SubmitterName":    "Moran Daniels",
+					"AssigneeName":     "Catalina Simpson",
+					"OrganizationName": "Geekfarm",
+				},
+				"3ff0599a-fe0f-4f8f-ac31-e2636843bcea": {
+					"SubmitterName":    "Valentine Ashley",
+					"AssigneeName":     "",
+					"OrganizationName": "Geekfarm",
+				},
+				"7c67b6ed-6776-4065-bd4a-f2d9d12c33b7": {
+					"AssigneeName":     "Melissa Bishop",
+					"OrganizationName": "Terrasys",
+					"SubmitterName":    "",
+				},
+			},
+		},
+	}
+
+	for _, tt := range testsSuccess {
+		suite.Run(tt.title, func() {
+			//suite.Nil(tt.data.FetchFiltered()) // No filtered results prior to search
+			for i := range tt.tickets { // Related entities all null before search
+				suite.Empty(tt.tickets[i].SubmitterName)
+				suite.Empty(tt.tickets[i].AssigneeName)
+				suite.Empty(tt.tickets[i].OrganizationName)
+			}
+			suite.Nil(addRelatedEntities(context.Background(), reflect.ValueOf(tt.tickets), tickets.Model, tt.related)) // Add entities to all tickets
+			for _, ticket := range tt.tickets {
+				value, _ := tt.expected[ticket.Id]["SubmitterName"]
+				suite.Equal(ticket.SubmitterName, value)
+				value, _ = tt.expected[ticket.Id]["AssigneeName"]
+				suite.Equal(ticket.AssigneeName, value)
+				value, _ = tt.expected[ticket.Id]["OrganizationName"]
+				suite.Equal(ticket.OrganizationName, value)
+			}
+		})
+	}
+}
+
+func (suite *TestSuite) TestAddRelatedEntities_ListFields() {
+	suite.Run("Add related entities matched on list fields - agents of groups, and groups of users", func() {
+		raw, _ := os.ReadFile(filepath.Join(testDataDir, "groups.json"))
+		groupData, err := groups.Model.Load(raw)
+		suite.Nil(err)
+		allGroups := groupData.(*groups.GroupData).Processed
+		suite.Nil(addRelatedEntities(context.Background(), reflect.ValueOf(allGroups), groups.Model, map[string]internal.DataProcessor{"user": &suite.userData}))
+		suite.Equal([]string{"Catalina Simpson", "Melissa Bishop"}, allGroups[0].Agents)
+		suite.Equal([]string{"Melissa Bishop"}, allGroups[1].Agents)
+		suite.Nil(allGroups[2].Agents)
+
+		allUsers := append(users.User{}, suite.userData.Processed...)
+		suite.Nil(addRelatedEntities(context.Background(), reflect.ValueOf(allUsers), users.Model, map[string]internal.DataProcessor{"group": groupData}))
+		for _, user := range allUsers {
+			switch user.Id {
+			case 43:
+				suite.Equal([]string{"Support"}, user.Groups)
+			case 74:
+				suite.Equal([]string{"Support", "Billing"}, user.Groups)
+			default:
+				suite.Nil(user.Groups)
+			}
+		}
+	})
+	suite.Run("Add related entities of ticket comments", func() {
+		raw, _ := os.ReadFile(filepath.Join(testDataDir, "ticket_comments.json"))
+		commentData, err := comments.Model.Load(raw)
+		suite.Nil(err)
+		allComments := commentData.(*comments.CommentData).Processed
+		suite.Nil(addRelatedEntities(context.Background(), reflect.ValueOf(allComments), comments.Model, map[string]internal.DataProcessor{"user": &suite.userData, "ticket": &suite.ticketData}))
+		suite.Equal("Moran Daniels", allComments[0].AuthorName)
+		suite.Equal("A Problem in Gambia", allComments[0].TicketSubject)
+		suite.Equal("Catalina Simpson", allComments[1].AuthorName)
+		suite.Equal("Melissa Bishop", allComments[2].AuthorName)
+	})
+}
+
+func (suite *TestSuite) TestAddRelatedEntities_Parallel() {
+	related := map[string]internal.DataProcessor{"organization": &suite.orgData, "ticket": &suite.ticketData}
+	expected := append(users.User{}, suite.userData.Processed...)
+	suite.Nil(addRelatedEntities(context.Background(), reflect.ValueOf(expected), users.Model, related)) // Enriched one at a time, as there are few of them
+	allUsers := make(users.User, 3*enrichmentChunk+1)
+	for i := range allUsers {
+		allUsers[i] = suite.userData.Processed[i%len(suite.userData.Processed)]
+	}
+
+	suite.Run("Add related entities to large results by a pool of workers", func() {
+		results := append(users.User{}, allUsers...)
+		suite.Nil(addRelatedEntities(context.Background(), reflect.ValueOf(results), users.Model, related))
+		for i, user := range results {
+			suite.Equal(expected[i%len(expected)].OrganizationName, user.OrganizationName)
+			suite.Equal(expected[i%len(expected)].Tickets, user.Tickets)
+		}
+		suite.Equal("Isotronic", results[len(results)-1].OrganizationName)
+	})
+	suite.Run("Stop adding related entities once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := append(users.User{}, allUsers...)
+		suite.True(errors.Is(addRelatedEntities(ctx, reflect.ValueOf(results), users.Model, related), context.Canceled))
+	})
+}
+
+func (suite *TestSuite) TestFind_Error() {
+	testsError := []struct {
+		title     string
+		entity    string
+		condition Condition
+	}{
+		{
+			title:     "find orgs - pass invalid field to search for",
+			entity:    organizations.Model.Name,
+			condition: Condition{Name: "invalid_field", Value: "121"},
+		},
+		{
+			title:     "find users - pass invalid field to search for",
+			entity:    users.Model.Name,
+			condition: Condition{Name: "random field", Value: "74"},
+		},
+		{
+			title:     "find tickets - pass invalid field to search for",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "useless field", Value: "7c67b6ed-6776-4065-bd4a-f2d9d12c33b7"},
+		},
+	}
+
+	for _, tt := range testsError {
+		suite.Run(tt.title, func() {
+			results, err := suite.dataset.Find(context.Background(), tt.entity, tt.condition)
+			suite.NotNil(err)
+			suite.Nil(results)
+			suite.True(errors.Is(err, ErrUnknownField)) // Assert correct error type thrown
+		})
+	}
+}
+
+func (suite *TestSuite) TestFind_Success() {
+	testsSuccess := []struct {
+		title     string
+		entity    internal.Entity
+		count     int       // number of results returned from search query
+		condition Condition // user input flags
+		dataType  any       // type of the entities returned
+	}{
+		{
+			title:     "find orgs",
+			entity:    organizations.Model,
+			count:     1,
+			dataType:  organizations.OrganizationEntity{},
+			condition: Condition{Name: "_id", Value: "121"},
+		},
+		{
+			title:     "find users",
+			entity:    users.Model,
+			count:     1,
+			dataType:  users.UserEntity{},
+			condition: Condition{Name: "_id", Value: "74"},
+		},
+		{
+			title:     "find tickets",
+			entity:    tickets.Model,
+			count:     1,
+			dataType:  tickets.TicketEntity{},
+			condition: Condition{Name: "_id", Value: "7c67b6ed-6776-4065-bd4a-f2d9d12c33b7"},
+		},
+	}
+
+	for _, tt := range testsSuccess {
+		suite.Run(tt.title, func() {
+			results, err := suite.dataset.Find(context.Background(), tt.entity.EntityName(), tt.condition)
+			suite.Implements((*Flags)(nil), tt.condition) // Conditions implement correct interface
+			suite.Nil(err)
+			suite.Equal(tt.count, len(results)) // Number of results as expected
+			for _, result := range results {    // Ensure value returned is value specified
+				suite.IsType(tt.dataType, result)
+				field := reflect.ValueOf(result).FieldByName(tt.entity.Mappings()[tt.condition.FetchName()])
+				switch field.Kind() {
+				case reflect.Int:
+					value, _ := strconv.ParseInt(tt.condition.FetchValue(), 10, 64)
+					suite.Equal(value, field.Int())
+				default:
+					suite.Equal(tt.condition.FetchValue(), field.String())
+				}
+			}
+		})
+	}
+}
+
+func (suite *TestSuite) TestFind_ByDataType_Error() {
+	testsError := []struct {
+		title        string
+		entity       string
+		name         string
+		value        string
+		errorMessage string
+	}{
+		{
+			title:        "Search by integer field but specifying invalid integer",
+			entity:       organizations.Model.Name,
+			value:        "invalid integer",
+			name:         "_id",
+			errorMessage: "Please specify int type of --value associated with --name of _id\n",
+		},
+		{
+			title:        "Search by bool field but specifying invalid bool",
+			entity:       users.Model.Name,
+			value:        "invalid bool",
+			name:         "suspended",
+			errorMessage: "Please specify bool type of --value associated with --name of suspended\n",
+		},
+	}
+
+	for _, tt := range testsError {
+		suite.Run(tt.title, func() {
+			results, err := suite.dataset.Find(context.Background(), tt.entity, Condition{Name: tt.name, Value: tt.value})
+			var valueError *ValueError
+			suite.True(errors.As(err, &valueError)) // Error has occurred
+			suite.Equal(tt.errorMessage, valueError.Error())
+			suite.Nil(results) // Error causes nil value to be returned
+		})
+	}
+	suite.Run("Invalid data type not supported by CLI", func() {
+		m, err := newMatcher(reflect.TypeOf(&struct{}{}), "invalid bool", "suspended")
+		suite.Nil(m)
+		suite.Equal("invalid data type not supported", err.Error())
+	})
+}
+
+func (suite *TestSuite) TestFind_ByDataType_Success() {
+	testsSuccess := []struct {
+		title  string
+		entity string
+		name   string
+		value  string
+		count  int
+	}{
+		{
+			title:  "Search by integer field",
+			entity: organizations.Model.Name,
+			value:  strconv.Itoa(121),
+			name:   "_id",
+			count:  1,
+		},
+		{
+			title:  "Search by string field",
+			entity: users.Model.Name,
+			value:  "rosannasimpson@flotonic.com",
+			name:   "email",
+			count:  1,
+		},
+		{
+			title:  "Search by array-type field",
+			entity: tickets.Model.Name,
+			value:  "Massachusetts",
+			name:   "tags",
+			count:  1,
+		},
+		{
+			title:  "Search by bool field",
+			entity: users.Model.Name,
+			value:  strconv.FormatBool(true),
+			name:   "verified",
+			count:  1,
+		},
+		{
+			title:  "Multiple result count - when searching for users who are suspended",
+			entity: users.Model.Name,
+			value:  strconv.FormatBool(true),
+			name:   "suspended",
+			count:  3,
+		},
+	}
+
+	for _, tt := range testsSuccess {
+		suite.Run(tt.title, func() {
+			results, err := suite.dataset.Find(context.Background(), tt.entity, Condition{Name: tt.name, Value: tt.value})
+			suite.Nil(err)
+			suite.Equal(tt.count, len(results)) // Number of results as expected
+		})
+	}
+}
+
+func (suite *TestSuite) TestFind_ByDataType_IntList() {
+	raw, _ := os.ReadFile(filepath.Join(testDataDir, "groups.json"))
+	groupData, _ := groups.Model.Load(raw)
+	dataset := newTestDataset(suite.T(), map[string]internal.DataProcessor{groups.Model.Name: groupData}, nil)
+	suite.Run("Search by array-type field of integers", func() {
+		results, err := dataset.Find(context.Background(), groups.Model.Name, Condition{Name: "agent_ids", Value: "74"})
+		suite.Nil(err)
+		suite.Equal(2, len(results))
+	})
+	suite.Run("Search by array-type field of integers but specifying invalid integer", func() {
+		results, err := dataset.Find(context.Background(), groups.Model.Name, Condition{Name: "agent_ids", Value: "Melissa"})
+		suite.Nil(results)
+		suite.Equal("invalid search of groups: Please specify int type of --value associated with --name of agent_ids", err.Error())
+	})
+}
+
+func (suite *TestSuite) TestFind_CustomFields() {
+	definitions := internal.FieldDefinitions{
+		{Entity: "ticket", Key: "custom_field.360001234", Name: "product", Type: internal.FieldTypeString},
+		{Entity: "ticket", Key: "custom_field.360009012", Name: "affected_users", Type: internal.FieldTypeInt},
+		{Entity: "user", Key: "user_field.vip", Name: "vip", Type: internal.FieldTypeBool},
+	}
+	dataset := newTestDataset(suite.T(), map[string]internal.DataProcessor{
+		users.Model.Name:   &suite.userData,
+		tickets.Model.Name: &suite.ticketData,
+	}, definitions)
+	testsSuccess := []struct {
+		title     string
+		entity    string
+		condition Condition
+		ids       []any
+	}{
+		{
+			title:     "search custom field by friendly name",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "product", Value: "guide"},
+			ids:       []any{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "search custom field by searchable name, with a defined int type",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "custom_field.360009012", Value: "5"},
+			ids:       []any{"20615fe1-765b-4ff5-b4f6-ea42dcc8cac3"},
+		},
+		{
+			title:     "search custom field without definition, with type inferred as bool",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "custom_field.360005678", Value: "f"},
+			ids:       []any{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "search custom field without definition, with type inferred as list",
+			entity:    tickets.Model.Name,
+			condition: Condition{Name: "custom_field.360007777", Value: "b"},
+			ids:       []any{"3ff0599a-fe0f-4f8f-ac31-e2636843bcea"},
+		},
+		{
+			title:     "search user field of users",
+			entity:    users.Model.Name,
+			condition: Condition{Name: "vip", Value: "true"},
+			ids:       []any{22},
+		},
+		{
+			title:     "search unknown custom field",
+			entity:    users.Model.Name,
+			condition: Condition{Name: "user_field.unknown", Value: ""},
+			ids:       nil,
+		},
+	}
+	for _, tt := range testsSuccess {
+		suite.Run(tt.title, func() {
+			results, err := dataset.Find(context.Background(), tt.entity, tt.condition)
+			suite.Nil(err)
+			suite.Equal(tt.ids, idsOf(results))
+		})
+	}
+	suite.Run("search custom field with value of invalid type", func() {
+		results, err := dataset.Find(context.Background(), tickets.Model.Name, Condition{Name: "affected_users", Value: "many"})
+		suite.Nil(results)
+		suite.Equal("invalid search of tickets: Please specify int type of --value associated with --name of custom_field.360009012", err.Error())
+	})
+}
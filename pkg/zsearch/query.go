@@ -0,0 +1,193 @@
+// Package zsearch -
+//
+// This file is meant for building queries of entities, and searching a dataset for them with results of the type of
+// the entity (eg. []zsearch.User), rather than of any type
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models/comments"
+	"ZendeskChallenge/models/groups"
+	"ZendeskChallenge/models/organizations"
+	"ZendeskChallenge/models/ratings"
+	"ZendeskChallenge/models/tickets"
+	"ZendeskChallenge/models/users"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Entities searched for, as declared by their models
+type (
+	User         = users.UserEntity
+	Organization = organizations.OrganizationEntity
+	Ticket       = tickets.TicketEntity
+	Group        = groups.GroupEntity
+	Comment      = comments.CommentEntity
+	Rating       = ratings.RatingEntity
+	Timestamp    = internal.Timestamp
+)
+
+// EntityType - Type of entities searched for by a query, and of its results
+type EntityType[T any] struct {
+	model internal.Model[T]
+}
+
+// Types of all searchable entities
+var (
+	Users         = EntityType[User]{users.Model}
+	Organizations = EntityType[Organization]{organizations.Model}
+	Tickets       = EntityType[Ticket]{tickets.Model}
+	Groups        = EntityType[Group]{groups.Model}
+	Comments      = EntityType[Comment]{comments.Model}
+	Ratings       = EntityType[Rating]{ratings.Model}
+)
+
+// Name - Name of the entity (eg. user), as used by Dataset methods and by CLI sub-commands
+func (e EntityType[T]) Name() string {
+	return e.model.Name
+}
+
+// Flags - Field name and value (or predicate) to search for, implemented by Condition
+type Flags interface {
+	FetchName() string
+	FetchValue() string
+	FetchPredicate() string
+	FetchLocation() *time.Location
+	FetchFuzzy() bool
+}
+
+// Condition - Field name and value to search for, the same as --name and --value of the search command. Predicate is
+// set instead of the value, when searching for missing, null or empty fields. Location is the timezone dates are
+// searched in, instead of the timezone each timestamp is stored in. Fuzzy matches string fields with values similar
+// to the value, rather than equal to it, see `fuzzy.go`
+type Condition struct {
+	Value     string
+	Name      string
+	Predicate string
+	Location  *time.Location
+	Fuzzy     bool
+}
+
+func (c Condition) FetchName() string {
+	return c.Name
+}
+
+func (c Condition) FetchValue() string {
+	return c.Value
+}
+
+func (c Condition) FetchPredicate() string {
+	return c.Predicate
+}
+
+func (c Condition) FetchLocation() *time.Location {
+	return c.Location
+}
+
+func (c Condition) FetchFuzzy() bool {
+	return c.Fuzzy
+}
+
+// Query - Conditions which entities of a type must all match, built by chaining its methods, eg.
+//
+//	zsearch.NewQuery(zsearch.Tickets).Where("status", "open").Is("assignee_id", zsearch.PredicateMissing).Limit(10)
+type Query[T any] struct {
+	entity     EntityType[T]
+	conditions []Condition
+	location   *time.Location
+	limit      int
+}
+
+// NewQuery - Query of all entities of a type, until conditions are added to it
+func NewQuery[T any](entity EntityType[T]) *Query[T] {
+	return &Query[T]{entity: entity}
+}
+
+// Where - Match entities whose field has a value. Values are parsed by the type of the field, see compileCondition
+func (q *Query[T]) Where(name, value string) *Query[T] {
+	q.conditions = append(q.conditions, Condition{Name: name, Value: value})
+	return q
+}
+
+// Like - Match entities whose string field has a value similar to a value, tolerating misspellings
+func (q *Query[T]) Like(name, value string) *Query[T] {
+	q.conditions = append(q.conditions, Condition{Name: name, Value: value, Fuzzy: true})
+	return q
+}
+
+// Is - Match entities by whether their field is missing, null or empty (or not), one of Predicates
+func (q *Query[T]) Is(name, predicate string) *Query[T] {
+	q.conditions = append(q.conditions, Condition{Name: name, Predicate: predicate})
+	return q
+}
+
+// In - Search dates, and return timestamps of results, in a timezone
+func (q *Query[T]) In(location *time.Location) *Query[T] {
+	q.location = location
+	return q
+}
+
+// Limit - Return up to a number of results, or all of them if it is 0
+func (q *Query[T]) Limit(limit int) *Query[T] {
+	q.limit = limit
+	return q
+}
+
+// Conditions - All conditions of the query, in the order they were added
+func (q *Query[T]) Conditions() []Condition {
+	conditions := make([]Condition, len(q.conditions))
+	for i, condition := range q.conditions {
+		condition.Location = q.location
+		conditions[i] = condition
+	}
+	return conditions
+}
+
+/*
+*		Search a dataset for entities matching all conditions of a query, along with their related entities
+*
+*	    @return ([]T, error): Matching entities, and error if any condition is invalid or the context is done
+ */
+func Search[T any](ctx context.Context, dataset *Dataset, query *Query[T]) ([]T, error) {
+	results, err := dataset.Find(ctx, query.entity.Name(), query.Conditions()...)
+	if err != nil {
+		return nil, err
+	}
+	if query.limit > 0 && len(results) > query.limit {
+		results = results[:query.limit]
+	}
+	return typed[T](results, query.location)
+}
+
+/*
+*		Get an entity of a type from a dataset by its primary key, along with its related entities
+*
+*	    @return (T, error): Entity, and error wrapping ErrNotFound if there is no entity with the key
+ */
+func Get[T any](ctx context.Context, dataset *Dataset, entity EntityType[T], id string) (T, error) {
+	var zero T
+	result, err := dataset.Get(ctx, entity.Name(), id)
+	if err != nil {
+		return zero, err
+	}
+	all, err := typed[T]([]any{result}, nil)
+	if err != nil {
+		return zero, err
+	}
+	return all[0], nil
+}
+
+// Results as entities of their type, with timestamps in a timezone (if any)
+func typed[T any](results []any, location *time.Location) ([]T, error) {
+	all := make([]T, 0, len(results))
+	for _, result := range results {
+		entity, ok := result.(T)
+		if !ok {
+			return nil, fmt.Errorf("unexpected result of type %T", result)
+		}
+		all = append(all, entity)
+	}
+	internal.InTimezone(internal.Records[T](all), location)
+	return all, nil
+}
@@ -0,0 +1,207 @@
+// Package zsearch -
+//
+// This file is meant for matching conditions against entities of a dataset, parsed once when the dataset is loaded.
+// Searches filter rows of the entities, rather than setting filtered entities of their data, so that a dataset is
+// searched by any number of searches at once
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/ohler55/ojg/oj"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// table - Entities of a type, as unmarshalled into their model and as their fields are in the raw data, by row
+type table struct {
+	records []any
+	fields  []map[string]any
+}
+
+// recordMatcher - Condition on an entity, matched against the entity or its fields as they are in the raw data
+type recordMatcher func(record any, fields map[string]any) (bool, error)
+
+/*
+*		Parse entities of data into a table, along with the fields of each entity as they are in the raw data
+*
+*	    @return (*table, error): Table of the entities, and error if the raw data is not a list of entities
+ */
+func newTable(data internal.DataProcessor) (*table, error) {
+	obj, err := oj.Parse(data.FetchRaw())
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := obj.([]any)
+	records := data.FetchProcessed()
+	if len(raw) != len(records) {
+		return nil, fmt.Errorf("parsed %v entities from raw data of %v entities", len(raw), len(records))
+	}
+	fields := make([]map[string]any, len(raw))
+	for i, record := range raw {
+		fields[i], _ = record.(map[string]any)
+	}
+	return &table{records: records, fields: fields}, nil
+}
+
+// All rows of a table
+func (t *table) rows() []int {
+	rows := make([]int, len(t.records))
+	for i := range rows {
+		rows[i] = i
+	}
+	return rows
+}
+
+/*
+*		Rows of a table matching a condition, out of the given rows
+*
+*	    @return ([]int, error): Matching rows, and error of the condition on any of them (eg. a value of the wrong type)
+ */
+func (t *table) filter(rows []int, m recordMatcher) ([]int, error) {
+	var matching []int
+	for _, row := range rows {
+		matched, err := m(t.records[row], t.fields[row])
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matching = append(matching, row)
+		}
+	}
+	return matching, nil
+}
+
+/*
+*		Copies of the entities of rows, as a slice of the type of the entities, so that related entities are added to
+*		them without changing the entities of the table
+*
+*	    @return (reflect.Value): Slice of the entities, nil if there are no rows
+ */
+func (t *table) copyRows(rows []int) reflect.Value {
+	if len(rows) == 0 {
+		return reflect.Value{}
+	}
+	copied := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(t.records[rows[0]])), len(rows), len(rows))
+	for i, row := range rows {
+		copied.Index(i).Set(reflect.ValueOf(t.records[row]))
+	}
+	return copied
+}
+
+// Entities of a slice, as returned by searches
+func entitiesOf(records reflect.Value) []interface{} {
+	all := []interface{}{}
+	if !records.IsValid() {
+		return all
+	}
+	for i := 0; i < records.Len(); i++ {
+		all = append(all, records.Index(i).Interface())
+	}
+	return all
+}
+
+/*
+*		Compile a condition into a matcher of entities, by the kind of field it is searched on:
+*
+*		- custom fields by their searchable name (eg. custom_field.360001234) or by the friendly name given to them in
+*		  the field definitions, compared by their definition
+*		- missing, null and empty fields (of any type) by a predicate instead of a value, see `predicate.go`
+*		- date/time fields by date, timestamp or relative time, see `dates.go`
+*		- string fields (and lists of strings) by similar values when searching fuzzily, see `fuzzy.go`
+*		- any other field by its exact value, parsed by the type of the field, see `match.go`
+*
+*	    @return (recordMatcher, error): Matcher, and error if the field is unknown (validator.ValidationErrors) or the
+*		value is not of the type of the field
+ */
+func compileCondition(flags Flags, entity internal.Entity, definitions internal.FieldDefinitions) (recordMatcher, error) {
+	if definition, ok := findCustomField(flags.FetchName(), entity, definitions); ok {
+		predicate, value := flags.FetchPredicate(), flags.FetchValue()
+		return func(record any, _ map[string]any) (bool, error) {
+			store, ok := record.(internal.CustomFieldStore)
+			if !ok {
+				return false, nil
+			}
+			fieldValue, found := store.FetchCustomFields()[definition.Key]
+			if predicate != "" {
+				return matchPredicate(predicate, fieldValue, found), nil
+			}
+			if !found {
+				return false, nil
+			}
+			return matchCustomFieldValue(definition, fieldValue, value)
+		}, nil
+	}
+	validate := validator.New()
+	err := validate.Var(flags.FetchName(), "required,oneof="+strings.Join(entity.SearchableFields(), " "))
+	if err != nil {
+		return nil, err
+	}
+	if flags.FetchPredicate() != "" {
+		return rawMatcher(presence{field: flags.FetchName(), predicate: flags.FetchPredicate()}), nil
+	}
+	fieldType := entity.FieldType(flags.FetchName()) // Field type is declared by the entity struct
+	if fieldType == reflect.TypeOf(internal.Timestamp{}) {
+		return timestampMatcher(flags.FetchValue(), flags.FetchName(), entity.Mappings()[flags.FetchName()], flags.FetchLocation())
+	}
+	if flags.FetchFuzzy() && isStringField(fieldType) {
+		return rawMatcher(similar{field: flags.FetchName(), value: flags.FetchValue()}), nil
+	}
+	m, err := newMatcher(fieldType, flags.FetchValue(), flags.FetchName())
+	if err != nil {
+		return nil, err
+	}
+	return rawMatcher(m), nil
+}
+
+// Matcher of entities by their fields as they are in the raw data
+func rawMatcher(m matcher) recordMatcher {
+	return func(_ any, fields map[string]any) (bool, error) {
+		return m.match(fields), nil
+	}
+}
+
+// Matcher of entities matching any of the matchers, eg. for the values of a comparison with 'in (a, b)'
+func anyMatcher(matchers ...recordMatcher) recordMatcher {
+	return func(record any, fields map[string]any) (bool, error) {
+		for _, m := range matchers {
+			matched, err := m(record, fields)
+			if matched || err != nil {
+				return matched, err
+			}
+		}
+		return false, nil
+	}
+}
+
+/*
+*		Matcher of entities by a timestamp, see `dates.go`. An empty value matches entities without the timestamp
+*
+*	    @return (recordMatcher, error): Matcher, and error if the value is not a date, timestamp or relative time
+ */
+func timestampMatcher(value, name, field string, location *time.Location) (recordMatcher, error) {
+	var query timeQuery
+	if value != "" {
+		var err error
+		query, err = parseTimeQuery(value, name, location)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return func(record any, _ map[string]any) (bool, error) {
+		timestamp, ok := reflect.ValueOf(record).FieldByName(field).Interface().(internal.Timestamp)
+		if !ok {
+			return false, nil
+		}
+		return (value == "" && timestamp.IsZero()) || (value != "" && query.match(timestamp, location)), nil
+	}, nil
+}
+
+// Whether an error of a condition is of an unknown field, rather than of its value
+func isUnknownField(err error) bool {
+	var invalidField validator.ValidationErrors
+	return errors.As(err, &invalidField)
+}
@@ -0,0 +1,264 @@
+// Package zsearch -
+//
+// This file is meant for explaining and timing searches: the plan of each search (the order conditions are evaluated
+// in, how each is evaluated, and estimated vs actual counts of entities), and the time spent in each phase of them.
+// Searches record them in a Trace set on their context, eg.
+//
+//	trace := zsearch.NewTrace()
+//	results, err := dataset.Find(zsearch.WithTrace(ctx, trace), "user", conditions...)
+//	trace.WritePlan(os.Stdout)
+//	trace.WriteTiming(os.Stdout)
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phases of searches, in the order they happen in. Results are displayed by callers of searches, see Observe
+const (
+	PhaseLoad    = "load data"
+	PhaseMatch   = "match conditions"
+	PhaseEnrich  = "add related entities"
+	PhaseDisplay = "display results"
+)
+
+// Fraction of entities estimated to match a condition which cannot be estimated from the values of its field (eg. a
+// predicate, or a fuzzy or date search)
+const defaultSelectivity = 0.1
+
+// Step - Evaluation of a condition of a search, in the order conditions were evaluated in
+type Step struct {
+	Entity    string
+	Condition string // eg. name = "Francisca Rasmussen", alias is null
+	Strategy  string // How the condition is evaluated, eg. scan of raw data (string equality)
+	Searched  int    // Entities the condition was evaluated on, matching all conditions before it
+	Estimated int    // Entities estimated to match the condition, when planning the order of conditions
+	Actual    int    // Entities which matched the condition, and all conditions before it
+	Duration  time.Duration
+}
+
+// Phase - Total time spent in a phase of searches
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Trace - Plan and timing of searches with a context it is set on, see WithTrace. Safe for concurrent use
+type Trace struct {
+	mutex  sync.Mutex
+	steps  []Step
+	phases []Phase
+}
+
+type traceKey struct{}
+
+// NewTrace - Empty trace, to be set on the context of searches
+func NewTrace() *Trace {
+	return &Trace{}
+}
+
+// WithTrace - Context of searches which record their plan and timing in a trace
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+/*
+*		Context of searches, traced if their plan (explain) or timing (timing) is to be written, eg. by --explain and
+*		--timing of commands
+*
+*	    @return (context.Context, *Trace): Context of the searches, and their trace, nil if they are not traced
+ */
+func Traced(ctx context.Context, explain, timing bool) (context.Context, *Trace) {
+	if !explain && !timing {
+		return ctx, nil
+	}
+	trace := NewTrace()
+	return WithTrace(ctx, trace), trace
+}
+
+// Trace set on a context, or nil if searches of the context are not traced
+func traceFrom(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// Steps - Steps of all traced searches, in the order they were evaluated in
+func (t *Trace) Steps() []Step {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]Step{}, t.steps...)
+}
+
+// Phases - Total time spent in each phase of all traced searches, in the order phases first happened in
+func (t *Trace) Phases() []Phase {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]Phase{}, t.phases...)
+}
+
+// Observe - Add time spent in a phase (eg. display results, by callers of searches). Does nothing on a nil trace
+func (t *Trace) Observe(phase string, duration time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for i := range t.phases {
+		if t.phases[i].Name == phase {
+			t.phases[i].Duration += duration
+			return
+		}
+	}
+	t.phases = append(t.phases, Phase{Name: phase, Duration: duration})
+}
+
+// Record a step of a search. Does nothing on a nil trace
+func (t *Trace) record(step Step) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.steps = append(t.steps, step)
+}
+
+/*
+*		Write the plan of all traced searches: each step in the order it was evaluated in, how it was evaluated, and
+*		estimated vs actual counts of entities
+ */
+func (t *Trace) WritePlan(w io.Writer) {
+	_, _ = fmt.Fprint(w, "======== Query plan ========\n")
+	for i, step := range t.Steps() {
+		_, _ = fmt.Fprintf(w, "%v. %v where %v\n", i+1, step.Entity, step.Condition)
+		_, _ = fmt.Fprintf(w, "   %v: searched %v, estimated %v, actual %v (%v)\n", step.Strategy, step.Searched, step.Estimated, step.Actual, step.Duration.Round(time.Microsecond))
+	}
+}
+
+// WriteTiming - Write the time spent in each phase of all traced searches, and in total
+func (t *Trace) WriteTiming(w io.Writer) {
+	_, _ = fmt.Fprint(w, "======== Timing ========\n")
+	var total time.Duration
+	for _, phase := range t.Phases() {
+		_, _ = fmt.Fprintf(w, "%-28v %v\n", phase.Name+":", phase.Duration.Round(time.Microsecond))
+		total += phase.Duration
+	}
+	_, _ = fmt.Fprintf(w, "%-28v %v\n", "total:", total.Round(time.Microsecond))
+}
+
+// Write - Write the plan (explain) and timing (timing) of all traced searches. Does nothing on a nil trace
+func (t *Trace) Write(w io.Writer, explain, timing bool) {
+	if t == nil {
+		return
+	}
+	if explain {
+		t.WritePlan(w)
+	}
+	if timing {
+		t.WriteTiming(w)
+	}
+}
+
+// Describe a condition for the plan of a search, eg. name = "Francisca Rasmussen"
+func describeCondition(condition Condition) string {
+	switch {
+	case condition.Predicate != "":
+		return fmt.Sprintf("%v is %v", condition.Name, condition.Predicate)
+	case condition.Fuzzy:
+		return fmt.Sprintf("%v ~ %q (fuzzy)", condition.Name, condition.Value)
+	default:
+		return fmt.Sprintf("%v = %q", condition.Name, condition.Value)
+	}
+}
+
+/*
+*		How a condition is evaluated, for the plan of a search. Every condition is evaluated by a scan of the entities
+*		matching the conditions before it, by the type of its field
+ */
+func strategyOf(condition Condition, entity internal.Entity, definitions internal.FieldDefinitions) string {
+	kind := valueType(condition, entity, definitions)
+	fieldType := entity.FieldType(condition.Name)
+	switch {
+	case condition.Predicate != "":
+		return "scan of raw data (predicate)"
+	case kind == "timestamp":
+		return "scan of entities (dates)"
+	case strings.HasPrefix(kind, "custom"):
+		return fmt.Sprintf("scan of custom fields (%v)", kind)
+	case condition.Fuzzy && isStringField(fieldType):
+		return fmt.Sprintf("scan of raw data (fuzzy %v)", kind)
+	case fieldType != nil && fieldType.Kind() == reflect.Slice:
+		return fmt.Sprintf("scan of raw data (%v membership)", kind)
+	default:
+		return fmt.Sprintf("scan of raw data (%v equality)", kind)
+	}
+}
+
+/*
+*		Estimate the number of entities matching a condition. Equality of the primary key matches one entity, equality
+*		of any other field matches as many entities as there are of each of its values on average, and anything else
+*		matches defaultSelectivity of entities
+ */
+func estimateMatches(condition Condition, entity internal.Entity, entities []any, definitions internal.FieldDefinitions) int {
+	if len(entities) == 0 {
+		return 0
+	}
+	if _, custom := findCustomField(condition.Name, entity, definitions); custom || condition.Predicate != "" || condition.Fuzzy ||
+		entity.FieldType(condition.Name) == reflect.TypeOf(internal.Timestamp{}) {
+		return int(math.Ceil(float64(len(entities)) * defaultSelectivity))
+	}
+	if condition.Name == entity.PrimaryKey() {
+		return 1
+	}
+	distinct := map[any]bool{}
+	values := 0
+	for _, record := range entities {
+		field := reflect.ValueOf(record).FieldByName(entity.Mappings()[condition.Name])
+		if !field.IsValid() {
+			continue
+		}
+		for _, value := range fieldValues(field) {
+			distinct[fmt.Sprint(value)] = true
+			values++
+		}
+	}
+	if len(distinct) == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(values) / float64(len(distinct))))
+}
+
+/*
+*		Plan the order conditions of a search are evaluated in: the conditions estimated to match the fewest entities
+*		first, so that later conditions are evaluated on as few entities as possible. Conditions estimated to match as
+*		many entities are evaluated in the order they were given in
+*
+*	    @return ([]Condition, []int): Conditions in order, and the number of entities each is estimated to match
+ */
+func planConditions(conditions []Condition, entity internal.Entity, entities []any, definitions internal.FieldDefinitions) ([]Condition, []int) {
+	type planned struct {
+		condition Condition
+		estimate  int
+	}
+	plan := make([]planned, len(conditions))
+	for i, condition := range conditions {
+		plan[i] = planned{condition: condition, estimate: estimateMatches(condition, entity, entities, definitions)}
+	}
+	sort.SliceStable(plan, func(i, j int) bool {
+		return plan[i].estimate < plan[j].estimate
+	})
+	ordered := make([]Condition, len(plan))
+	estimates := make([]int, len(plan))
+	for i, p := range plan {
+		ordered[i], estimates[i] = p.condition, p.estimate
+	}
+	return ordered, estimates
+}
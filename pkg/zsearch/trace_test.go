@@ -0,0 +1,147 @@
+package zsearch
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// Test plans and timing of searches recorded in a trace, and the order conditions are planned in
+
+func TestFind_Trace(t *testing.T) {
+	dataset := loadTestDataset(t)
+
+	t.Run("test conditions estimated to match the fewest entities are evaluated first", func(t *testing.T) {
+		trace := NewTrace()
+		results, err := dataset.Find(WithTrace(context.Background(), trace), "user",
+			Condition{Name: "role", Value: "end-user"}, Condition{Name: "_id", Value: "22"})
+		assert.Nil(t, err)
+		assert.Len(t, results, 1)
+		steps := trace.Steps()
+		assert.Len(t, steps, 2)
+		assert.Equal(t, Step{Entity: "user", Condition: `_id = "22"`, Strategy: "scan of raw data (int equality)", Searched: 5, Estimated: 1, Actual: 1}, withoutDuration(steps[0]))
+		assert.Equal(t, Step{Entity: "user", Condition: `role = "end-user"`, Strategy: "scan of raw data (string equality)", Searched: 1, Estimated: 2, Actual: 1}, withoutDuration(steps[1]))
+	})
+	t.Run("test strategies of predicates, fuzzy and list searches", func(t *testing.T) {
+		trace := NewTrace()
+		ctx := WithTrace(context.Background(), trace)
+		_, err := dataset.Find(ctx, "user", Condition{Name: "alias", Predicate: PredicateNull})
+		assert.Nil(t, err)
+		_, err = dataset.Find(ctx, "user", Condition{Name: "name", Value: "moran danials", Fuzzy: true})
+		assert.Nil(t, err)
+		_, err = dataset.Find(ctx, "ticket", Condition{Name: "tags", Value: "Ohio"})
+		assert.Nil(t, err)
+		var strategies []string
+		for _, step := range trace.Steps() {
+			strategies = append(strategies, step.Strategy)
+		}
+		assert.Equal(t, []string{"scan of raw data (predicate)", "scan of raw data (fuzzy string)", "scan of raw data ([]string membership)"}, strategies)
+	})
+	t.Run("test time spent in each phase", func(t *testing.T) {
+		trace := NewTrace()
+		_, err := dataset.Find(WithTrace(context.Background(), trace), "user",
+			Condition{Name: "role", Value: "end-user"}, Condition{Name: "active", Value: "false"})
+		assert.Nil(t, err)
+		var phases []string
+		for _, phase := range trace.Phases() {
+			phases = append(phases, phase.Name)
+		}
+		assert.Equal(t, []string{PhaseMatch, PhaseEnrich}, phases)
+	})
+	t.Run("test searches without a trace are not traced", func(t *testing.T) {
+		results, err := dataset.Find(context.Background(), "user", Condition{Name: "_id", Value: "22"})
+		assert.Nil(t, err)
+		assert.Len(t, results, 1)
+	})
+}
+
+func TestQuery_Trace(t *testing.T) {
+	dataset := loadTestDataset(t)
+
+	t.Run("test comparisons matched against fields of entities", func(t *testing.T) {
+		statement, err := ParseQuery(`user where name ~ "moran" and role = end-user`)
+		assert.Nil(t, err)
+		trace := NewTrace()
+		results, err := dataset.Query(WithTrace(context.Background(), trace), statement)
+		assert.Nil(t, err)
+		assert.Len(t, results, 1)
+		steps := trace.Steps()
+		assert.Len(t, steps, 2)
+		assert.Equal(t, Step{Entity: "user", Condition: `name ~ "moran"`, Strategy: "scan of entities (~)", Searched: 5, Estimated: 1, Actual: 1}, withoutDuration(steps[0]))
+		assert.Equal(t, `role = "end-user"`, steps[1].Condition)
+	})
+	t.Run("test values of a comparison with in are matched in one scan", func(t *testing.T) {
+		statement, err := ParseQuery("user where role in (admin, agent) limit 1")
+		assert.Nil(t, err)
+		trace := NewTrace()
+		results, err := dataset.Query(WithTrace(context.Background(), trace), statement)
+		assert.Nil(t, err)
+		assert.Len(t, results, 1)
+		steps := trace.Steps()
+		assert.Len(t, steps, 1)
+		assert.Equal(t, `role = "admin" or role = "agent"`, steps[0].Condition)
+		assert.Equal(t, 2, steps[0].Actual)
+	})
+}
+
+func TestTrace_Write(t *testing.T) {
+	trace := NewTrace()
+	trace.record(Step{Entity: "user", Condition: `_id = "22"`, Strategy: "scan of raw data (int equality)", Searched: 5, Estimated: 1, Actual: 1})
+	trace.Observe(PhaseLoad, 2000)
+	trace.Observe(PhaseLoad, 1000)
+	trace.Observe(PhaseMatch, 1000)
+
+	t.Run("test plan", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		trace.WritePlan(buffer)
+		assert.Equal(t, "======== Query plan ========\n"+
+			"1. user where _id = \"22\"\n"+
+			"   scan of raw data (int equality): searched 5, estimated 1, actual 1 (0s)\n", buffer.String())
+	})
+	t.Run("test timing, with phases added up", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		trace.WriteTiming(buffer)
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		assert.Equal(t, []string{
+			"======== Timing ========",
+			"load data:                   3µs",
+			"match conditions:            1µs",
+			"total:                       4µs",
+		}, lines)
+	})
+	t.Run("test plan and timing, as asked for", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		trace.Write(buffer, false, true)
+		assert.True(t, strings.HasPrefix(buffer.String(), "======== Timing ========\n"))
+		assert.False(t, strings.Contains(buffer.String(), "Query plan"))
+	})
+	t.Run("test nil trace", func(t *testing.T) {
+		var trace *Trace
+		buffer := new(bytes.Buffer)
+		assert.NotPanics(t, func() {
+			trace.Observe(PhaseMatch, 1000)
+			trace.record(Step{})
+			trace.Write(buffer, true, true)
+		})
+		assert.Empty(t, buffer.String())
+	})
+}
+
+func TestTraced(t *testing.T) {
+	t.Run("test searches traced only if their plan or timing is asked for", func(t *testing.T) {
+		ctx, trace := Traced(context.Background(), false, false)
+		assert.Nil(t, trace)
+		assert.Nil(t, traceFrom(ctx))
+		ctx, trace = Traced(context.Background(), true, false)
+		assert.NotNil(t, trace)
+		assert.Equal(t, trace, traceFrom(ctx))
+	})
+}
+
+// Step without its duration, which differs on every run
+func withoutDuration(step Step) Step {
+	step.Duration = 0
+	return step
+}
@@ -0,0 +1,95 @@
+// Package zsearch -
+//
+// This file is meant for listing searchable fields of entities and distinct values of their fields in the data, eg.
+// for completion of searches in a shell
+package zsearch
+
+import (
+	"ZendeskChallenge/internal"
+	"ZendeskChallenge/models"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// Fields - Searchable fields of entities of a type, including the friendly names of their custom fields, sorted
+func (d *Dataset) Fields(name string) []string {
+	entity, ok := models.Registry.Get(name)
+	if !ok {
+		return nil
+	}
+	fields := slices.Clone(entity.SearchableFields())
+	if entity.CustomFieldPrefix() != "" {
+		for _, definition := range d.definitions.ForEntity(name) {
+			fields = append(fields, definition.Name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+/*
+*		Distinct values of a field of entities of a type, as they are in the data (eg. statuses of tickets, or names
+*		of organizations). Values of lists (eg. tags) are listed by each of their items, and custom fields by their
+*		friendly name or key
+*
+*	    @return ([]string): Values, sorted. None if the entities or the field are unknown
+ */
+func (d *Dataset) Values(name, field string) []string {
+	entity, ok := models.Registry.Get(name)
+	if !ok {
+		return nil
+	}
+	entities := d.tables[name]
+	if entities == nil {
+		return nil
+	}
+	var values []any
+	if definition, ok := findCustomField(field, entity, d.definitions); ok {
+		for _, record := range entities.records {
+			if store, ok := record.(internal.CustomFieldStore); ok {
+				values = append(values, store.FetchCustomFields()[definition.Key])
+			}
+		}
+	} else {
+		for _, fields := range entities.fields {
+			values = append(values, fields[field])
+		}
+	}
+	seen := map[string]bool{}
+	var distinct []string
+	for _, value := range values {
+		for _, text := range valueTexts(value) {
+			if !seen[text] {
+				seen[text] = true
+				distinct = append(distinct, text)
+			}
+		}
+	}
+	sort.Strings(distinct)
+	return distinct
+}
+
+// Texts of a value as it would be searched for: a list by each of its items, and nothing for null or objects
+func valueTexts(value any) []string {
+	switch value := value.(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case int64:
+		return []string{strconv.FormatInt(value, 10)}
+	case float64:
+		return []string{strconv.FormatFloat(value, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(value)}
+	case []any:
+		var texts []string
+		for _, item := range value {
+			texts = append(texts, valueTexts(item)...)
+		}
+		return texts
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package zsearch
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDataset_Values(t *testing.T) {
+	dataset := loadTestDataset(t)
+
+	t.Run("test fields, including friendly names of custom fields", func(t *testing.T) {
+		fields := dataset.Fields("user")
+		assert.Contains(t, fields, "role")
+		assert.Contains(t, fields, "plan")
+		assert.NotContains(t, fields, "organization_name", "Fields filled in from related entities are not searchable")
+		assert.Contains(t, dataset.Fields("ticket"), "product")
+	})
+	t.Run("test distinct values of a field", func(t *testing.T) {
+		assert.Equal(t, []string{"admin", "agent", "end-user"}, dataset.Values("user", "role"))
+		assert.Equal(t, []string{"closed", "pending", "solved"}, dataset.Values("ticket", "status"))
+	})
+	t.Run("test values of lists, numbers and booleans", func(t *testing.T) {
+		assert.Contains(t, dataset.Values("ticket", "tags"), "Ohio")
+		assert.Contains(t, dataset.Values("user", "organization_id"), "119")
+		assert.Equal(t, []string{"false"}, dataset.Values("user", "active"))
+		assert.Equal(t, []string{"25", "5"}, dataset.Values("user", "seats"))
+	})
+	t.Run("test values of custom fields, by friendly name or key", func(t *testing.T) {
+		assert.Equal(t, []string{"enterprise", "team"}, dataset.Values("user", "plan"))
+		assert.Equal(t, []string{"enterprise", "team"}, dataset.Values("user", "user_field.plan"))
+	})
+	t.Run("test unknown entity or field", func(t *testing.T) {
+		assert.Nil(t, dataset.Values("widget", "name"))
+		assert.Nil(t, dataset.Values("user", "nmae"))
+		assert.Nil(t, dataset.Fields("widget"))
+	})
+}
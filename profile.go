@@ -0,0 +1,64 @@
+// Package main -
+//
+// This file is meant for profiling commands by the global profiling flags (--cpuprofile and --memprofile), writing
+// profiles to be read with `go tool pprof`, eg. go tool pprof cpu.prof
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// addProfilingFlags - Add the global profiling flags to the root command, inherited by all sub-commands
+func addProfilingFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("cpuprofile", "", "File the CPU profile of the command is written to")
+	cmd.PersistentFlags().String("memprofile", "", "File the heap profile is written to, once the command is done")
+}
+
+/*
+*		Start profiling a command by its profiling flags. The CPU profile is written as the command runs, and the heap
+*		profile once it is done, so that it shows memory still in use by loaded data
+*
+*	    @return (func() error, error): Function stopping profiling and writing the heap profile once the command is
+*		done, and a UsageError if any profile file could not be created
+ */
+func startProfiling(cmd *cobra.Command) (func() error, error) {
+	cpuPath, _ := cmd.Flags().GetString("cpuprofile")
+	memPath, _ := cmd.Flags().GetString("memprofile")
+	var cpuFile *os.File
+	if cpuPath != "" {
+		file, err := os.Create(cpuPath)
+		if err != nil {
+			return nil, &UsageError{Err: fmt.Errorf("invalid --cpuprofile: %w", err)}
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("could not start CPU profile: %w", err)
+		}
+		cpuFile = file
+	}
+	return func() error {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				return fmt.Errorf("could not write CPU profile: %w", err)
+			}
+		}
+		if memPath == "" {
+			return nil
+		}
+		file, err := os.Create(memPath)
+		if err != nil {
+			return fmt.Errorf("invalid --memprofile: %w", err)
+		}
+		defer file.Close()
+		runtime.GC() // Heap profile of memory in use, rather than of garbage not collected yet
+		if err := pprof.WriteHeapProfile(file); err != nil {
+			return fmt.Errorf("could not write heap profile: %w", err)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test CPU and heap profiles written by the global profiling flags
+
+func TestStartProfiling(t *testing.T) {
+	t.Setenv("DATA_DIR", "testdata")
+
+	t.Run("test profiles of a search", func(t *testing.T) {
+		dir := t.TempDir()
+		cpuProfile, memProfile := filepath.Join(dir, "cpu.prof"), filepath.Join(dir, "mem.prof")
+		root := NewRootCmd()
+		root.SetOut(new(bytes.Buffer))
+		args := []string{"search", "user", "--name", "_id", "--value", "22", "--cpuprofile", cpuProfile, "--memprofile", memProfile}
+		assert.Equal(t, ExitOK, execute(root, args, new(bytes.Buffer)))
+		for _, path := range []string{cpuProfile, memProfile} {
+			info, err := os.Stat(path)
+			assert.Nil(t, err)
+			assert.NotZero(t, info.Size(), "profile is written to %v", path)
+		}
+	})
+	t.Run("test profile in a directory which does not exist", func(t *testing.T) {
+		stderr := new(bytes.Buffer)
+		args := []string{"list", "--cpuprofile", filepath.Join(t.TempDir(), "missing", "cpu.prof")}
+		assert.Equal(t, ExitUsage, execute(NewRootCmd(), args, stderr))
+		assert.Contains(t, stderr.String(), "Error: invalid --cpuprofile: ")
+	})
+}